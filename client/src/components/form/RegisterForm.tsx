@@ -31,7 +31,8 @@ const RegisterForm = () => {
   const onSubmit = async (data: z.infer<typeof registerFormSchema>) => {
     await register(data);
     form.reset();
-    navigate("/dashboard");
+    // The account has no role yet; sign-in works once an administrator assigns one
+    navigate("/login");
   };
 
   return (
@@ -139,14 +139,20 @@ const SummaryEvaluateFrom = ({
       let mergedResult = { ...initialResultAddData };
 
       if (formData.result && formData.result.applicants && formData.result.applicants.length > 0) {
+        const taken = new Set<number>();
         mergedResult = {
           ...initialResultAddData,
           debtDetail: formData.result.debtDetail,
           dti: formData.result.dti,
           dscr: formData.result.dscr,
-          applicants: initialResultAddData.applicants.map((calc, i) => {
-            const stored = formData.result.applicants[i];
-            if (!stored) return calc;
+          // Stored expenses follow the applicant's ID card, not their position in the list
+          applicants: initialResultAddData.applicants.map((calc) => {
+            const storedIndex = formData.result.applicants.findIndex(
+              (candidate, j) => !taken.has(j) && candidate.idCard === calc.idCard,
+            );
+            if (storedIndex < 0) return calc;
+            taken.add(storedIndex);
+            const stored = formData.result.applicants[storedIndex];
             const livingExpenses = Number(stored.livingExpenses ?? 0);
             const otherExpenses = Number(stored.otherExpenses ?? 0);
             const totalExpenses = calc.resultCustomerExpenses + livingExpenses + otherExpenses;
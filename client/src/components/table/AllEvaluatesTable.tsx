@@ -176,7 +176,7 @@ const AllEvaluatesTable = ({ evaluates }: AllEvaluatesTableProps) => {
                   </span>
                 ) : (
                   <span className="px-3 py-1 bg-yellow-100 text-yellow-700 font-medium rounded-md">
-                    รอการอนุมัติ
+                    {evaluate.status || "รอการอนุมัติ"}
                   </span>
                 )}
               </TableCell>
@@ -279,7 +279,7 @@ const EvaluatesTable = ({
                   </span>
                 ) : (
                   <span className="px-3 py-1 bg-yellow-100 text-yellow-700 font-medium rounded-md">
-                    รอการอนุมัติ
+                    {evaluate.status || "รอการอนุมัติ"}
                   </span>
                 )}
               </TableCell>
@@ -361,9 +361,13 @@ const EvaluatesTable = ({
                   <SelectValue />
                 </SelectTrigger>
                 <SelectContent>
+                  <SelectItem value="ฉบับร่าง">ฉบับร่าง</SelectItem>
                   <SelectItem value="รอการอนุมัติ">รอการอนุมัติ</SelectItem>
+                  <SelectItem value="อยู่ระหว่างพิจารณา">อยู่ระหว่างพิจารณา</SelectItem>
                   <SelectItem value="อนุมัติ">อนุมัติ</SelectItem>
                   <SelectItem value="ไม่อนุมัติ">ไม่อนุมัติ</SelectItem>
+                  <SelectItem value="เบิกจ่ายแล้ว">เบิกจ่ายแล้ว</SelectItem>
+                  <SelectItem value="ยกเลิก">ยกเลิก</SelectItem>
                 </SelectContent>
               </Select>
             </div>
@@ -4,8 +4,9 @@ import { Link } from "react-router-dom";
 import { Button } from "@/components/ui/button";
 import { Card, CardContent } from "@/components/ui/card";
 import { CountUp } from "@/components/CountUp";
-import { getPublicKPI } from "@/services/publicService";
+import { getDashboardSummary, getPublicKPI } from "@/services/publicService";
 import {
+  Building2,
   Users,
   FileCheck,
   TrendingUp,
@@ -55,13 +56,25 @@ const variantStyles: Record<
 export default function HomePage() {
   const { authUser, isLoading: authLoading } = useAuthContext();
 
-  const { data: kpi, isLoading: kpiLoading } = useQuery({
+  const { data: publicKpi, isLoading: publicKpiLoading } = useQuery({
     queryKey: ["public-kpi"],
     queryFn: getPublicKPI,
+    enabled: !authUser,
     staleTime: 1000 * 60 * 5,
     refetchOnWindowFocus: false,
   });
 
+  // Member, share and evaluation figures belong to a cooperative, so they only show once signed in
+  const { data: summary, isLoading: summaryLoading } = useQuery({
+    queryKey: ["dashboard-summary"],
+    queryFn: getDashboardSummary,
+    enabled: !!authUser,
+    staleTime: 1000 * 60 * 5,
+    refetchOnWindowFocus: false,
+  });
+
+  const kpiLoading = authUser ? summaryLoading : publicKpiLoading;
+
   const kpiCards: {
     variant: Variant;
     icon: React.ElementType;
@@ -69,32 +82,43 @@ export default function HomePage() {
     value: number;
     suffix: string;
     subtitle: string;
-  }[] = [
-    {
-      variant: "blue",
-      icon: Users,
-      title: "สมาชิกทั้งหมด",
-      value: kpi?.totalMembers ?? 0,
-      suffix: " คน",
-      subtitle: "จำนวนสมาชิกสหกรณ์ในระบบ",
-    },
-    {
-      variant: "green",
-      icon: TrendingUp,
-      title: "จำนวนหุ้นรวม",
-      value: kpi?.totalShares ?? 0,
-      suffix: " หุ้น",
-      subtitle: "มูลค่าหุ้นทั้งหมดของสมาชิก",
-    },
-    {
-      variant: "purple",
-      icon: FileCheck,
-      title: "จำนวนการประเมิน",
-      value: kpi?.totalEvaluations ?? 0,
-      suffix: " รายการ",
-      subtitle: "ใบประเมินสินเชื่อทั้งหมด",
-    },
-  ];
+  }[] = authUser
+    ? [
+        {
+          variant: "blue",
+          icon: Users,
+          title: "สมาชิกทั้งหมด",
+          value: summary?.totalMembers ?? 0,
+          suffix: " คน",
+          subtitle: "จำนวนสมาชิกสหกรณ์ในระบบ",
+        },
+        {
+          variant: "green",
+          icon: TrendingUp,
+          title: "จำนวนหุ้นรวม",
+          value: summary?.totalShares ?? 0,
+          suffix: " หุ้น",
+          subtitle: "มูลค่าหุ้นทั้งหมดของสมาชิก",
+        },
+        {
+          variant: "purple",
+          icon: FileCheck,
+          title: "จำนวนการประเมิน",
+          value: summary?.totalEvaluations ?? 0,
+          suffix: " รายการ",
+          subtitle: "ใบประเมินสินเชื่อทั้งหมด",
+        },
+      ]
+    : [
+        {
+          variant: "blue",
+          icon: Building2,
+          title: "สหกรณ์ในระบบ",
+          value: publicKpi?.totalCooperatives ?? 0,
+          suffix: " แห่ง",
+          subtitle: "จำนวนสหกรณ์ที่ใช้งานระบบ",
+        },
+      ];
 
   const steps = [
     {
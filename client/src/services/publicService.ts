@@ -1,6 +1,10 @@
 import { axiosInstance } from "@/utils/axios";
 
 export interface PublicKPIData {
+  totalCooperatives: number;
+}
+
+export interface DashboardSummaryData {
   totalMembers: number;
   totalEvaluations: number;
   totalShares: number;
@@ -10,3 +14,9 @@ export const getPublicKPI = async (): Promise<PublicKPIData> => {
   const response = await axiosInstance.get("/public/kpi");
   return response.data;
 };
+
+// Figures of the signed-in admin's cooperative, never served without a session
+export const getDashboardSummary = async (): Promise<DashboardSummaryData> => {
+  const response = await axiosInstance.get("/protected/dashboard/summary");
+  return response.data;
+};
@@ -134,7 +134,14 @@ export interface ICreateEvaluate {
   result: ResultEvaluate
 }
 
-export type EvaluateStatus = "รอการอนุมัติ" | "อนุมัติ" | "ไม่อนุมัติ";
+export type EvaluateStatus =
+  | "ฉบับร่าง"
+  | "รอการอนุมัติ"
+  | "อยู่ระหว่างพิจารณา"
+  | "อนุมัติ"
+  | "ไม่อนุมัติ"
+  | "เบิกจ่ายแล้ว"
+  | "ยกเลิก";
 
 export interface Evaluate {
   id: string;
@@ -1,4 +1,4 @@
-import axios from "axios";
+import axios, { type AxiosError, type InternalAxiosRequestConfig } from "axios";
 
 export const axiosInstance = axios.create({
   baseURL: import.meta.env.VITE_BASE_URL,
@@ -29,3 +29,44 @@ axiosInstance.interceptors.request.use(
     return Promise.reject(error);
   },
 );
+
+// A single refresh is shared by every request that fails while it is in flight, since the
+// refresh token is rotated on each call and replaying the old one revokes the session
+let refreshing: Promise<void> | null = null;
+
+const refreshSession = () => {
+  if (!refreshing) {
+    refreshing = axiosInstance
+      .post("/auth/refresh")
+      .then((response) => {
+        const accessToken = response.data?.data?.accessToken;
+        if (accessToken && localStorage.getItem("token")) {
+          localStorage.setItem("token", accessToken);
+        }
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
+axiosInstance.interceptors.response.use(
+  (response) => response,
+  async (error: AxiosError) => {
+    const config = error.config as (InternalAxiosRequestConfig & { _retry?: boolean }) | undefined;
+
+    // Auth endpoints answer 401 for bad credentials or an expired refresh token; retry only once
+    if (error.response?.status !== 401 || !config || config._retry || config.url?.startsWith("/auth/")) {
+      return Promise.reject(error);
+    }
+
+    config._retry = true;
+    try {
+      await refreshSession();
+    } catch {
+      return Promise.reject(error);
+    }
+    return axiosInstance(config);
+  },
+);
@@ -0,0 +1,194 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+// MarginLookup returns the bank net-profit margin (%) for a career category and
+// sub-career, or 0 when the career is unknown.
+type MarginLookup func(careerCategory string, career string) float64
+
+// Consumption expense rates (ค่าใช้จ่ายในการอุปโภคบริโภค) by monthly net income
+const (
+	lowIncomeThreshold    = 15000
+	highIncomeThreshold   = 100000
+	lowIncomeExpenseRate  = 0.3
+	midIncomeExpenseRate  = 0.25
+	highIncomeExpenseRate = 0.2
+)
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// CalculateBusiness recomputes the business income, expense, P&L and
+// shareholding figures of an applicant from the raw business inputs.
+func CalculateBusiness(a *models.ApplicantRequest, careerMargin float64) {
+	totalIncome := a.BusinessActivity.Salary + a.BusinessActivity.OtherSalary
+	a.BusinessActivity.TotalIncome = totalIncome
+
+	costAndService := totalIncome * (a.ExpenseItem.CostPercentage / 100)
+	a.ExpenseItem.CostAndService = costAndService
+	a.ExpenseItem.TotalExpense = costAndService +
+		a.ExpenseItem.EmpSalary +
+		a.ExpenseItem.RentExpenses +
+		a.ExpenseItem.UtilityExpenses +
+		a.ExpenseItem.OtherExpenses
+
+	a.ProfileLost.GrossProfit = totalIncome - a.ExpenseItem.TotalExpense
+	a.ProfileLost.ProfitBeforeTax = a.ProfileLost.GrossProfit - a.ProfileLost.InterestExpense
+	a.ProfileLost.NetProfit = a.ProfileLost.ProfitBeforeTax - a.ProfileLost.TaxExpense
+
+	sharePercentage := a.ShareHolder.ShareOfNetProfit / 100
+	shareValue := a.ProfileLost.NetProfit * sharePercentage
+	a.ShareHolder.BankNetProfit = round2(shareValue)
+
+	// Profit above the bank's margin for this career is treated as an extra expense
+	lastProfit := totalIncome * (careerMargin / 100) * sharePercentage
+	optionalOtherExpense := shareValue - lastProfit
+	if optionalOtherExpense <= 0 {
+		optionalOtherExpense = 0
+	}
+	a.OptionalOtherExpense = round2(optionalOtherExpense)
+}
+
+// SalaryDeductions returns the payroll deductions that are not debt payments.
+func SalaryDeductions(s models.Salary) float64 {
+	return s.Tax + s.SocialSecurityFund + s.ProvidentFund + s.ShareFund + s.AssociationFund + s.OtherFund
+}
+
+// CalculateSalary recomputes the salary, regular allowance and documented
+// income totals of an applicant.
+func CalculateSalary(a *models.ApplicantRequest) {
+	a.Salary.Total = a.Salary.Base + a.Salary.FreelanceIncome - SalaryDeductions(a.Salary)
+
+	a.OtherSalary.Total = a.OtherSalary.EntertainmentSalary +
+		a.OtherSalary.LivingSalary +
+		a.OtherSalary.CertificationSalary +
+		a.OtherSalary.ProfessionalAllowance +
+		a.OtherSalary.TransportationSalary +
+		a.OtherSalary.AcademicSalary +
+		a.OtherSalary.OtherRegularSalary
+
+	a.OptionsSalary.OtherDocumentedIncome = a.OptionsSalary.Commission +
+		a.OptionsSalary.Overtime +
+		a.OptionsSalary.Bonus +
+		a.OptionsSalary.DividendsInterest +
+		a.OptionsSalary.NetSupplementaryIncome +
+		a.OptionsSalary.Other
+
+	a.OptionsSalary.Total = a.Salary.Total + a.OtherSalary.Total + a.OptionsSalary.OtherDocumentedIncome
+}
+
+// CustomerExpenseRate returns the consumption expense rate for a monthly net income.
+func CustomerExpenseRate(resultIncome float64) float64 {
+	switch {
+	case resultIncome < lowIncomeThreshold:
+		return lowIncomeExpenseRate
+	case resultIncome < highIncomeThreshold:
+		return midIncomeExpenseRate
+	default:
+		return highIncomeExpenseRate
+	}
+}
+
+// CalculateResultApplicant builds the income and expense rollup of one
+// applicant. Living and other expenses are entered by the officer on the
+// summary step and are carried over as-is.
+func CalculateResultApplicant(a models.ApplicantRequest, livingExpenses float64, otherExpenses float64) models.ResultApplicantRequest {
+	expenses := SalaryDeductions(a.Salary)
+	salary := a.Salary.Base + a.Salary.FreelanceIncome
+	resultShareValue := a.ShareHolder.BankNetProfit
+	totalSalary := salary + a.OtherSalary.Total + a.OptionsSalary.OtherDocumentedIncome + resultShareValue
+	resultIncome := totalSalary - expenses
+
+	customerExpenses := CustomerExpenseRate(resultIncome)
+	resultCustomerExpenses := math.Max(customerExpenses, resultIncome*customerExpenses)
+
+	return models.ResultApplicantRequest{
+		Name:                   a.Name,
+		IDCard:                 a.IDCard,
+		Salary:                 salary,
+		Expenses:               expenses,
+		OtherSalary:            a.OtherSalary.Total,
+		OptionsSalary:          a.OptionsSalary.OtherDocumentedIncome,
+		ResultShareValue:       resultShareValue,
+		TotalSalary:            totalSalary,
+		ResultIncome:           resultIncome,
+		CustomerExpenses:       customerExpenses,
+		ResultCustomerExpenses: resultCustomerExpenses,
+		LivingExpenses:         livingExpenses,
+		OtherExpenses:          otherExpenses,
+		TotalExpenses:          resultCustomerExpenses + livingExpenses + otherExpenses,
+	}
+}
+
+// CalculateTotalDebt returns the total monthly debt service. LastDeduction is
+// recorded for reference only and is not subtracted, matching the summary form.
+func CalculateTotalDebt(d models.DebtDetail) float64 {
+	return d.DebtAmount + d.LastDebt + d.DebtReported + d.DebtNotReported
+}
+
+// Totals sums the income and expense rollups of every applicant.
+func Totals(applicants []models.ResultApplicantRequest) (totalSalary float64, resultIncome float64, totalExpenses float64) {
+	for _, a := range applicants {
+		totalSalary += a.TotalSalary
+		resultIncome += a.ResultIncome
+		totalExpenses += a.TotalExpenses
+	}
+	return totalSalary, resultIncome, totalExpenses
+}
+
+// CalculateDti returns the debt-to-income ratio in percent.
+func CalculateDti(totalDebt float64, totalSalary float64) float64 {
+	if totalSalary <= 0 {
+		return 0
+	}
+	return round2(totalDebt / totalSalary * 100)
+}
+
+// CalculateDscr returns the debt service coverage ratio.
+func CalculateDscr(totalDebt float64, resultIncome float64, totalExpenses float64) float64 {
+	if totalDebt <= 0 {
+		return 0
+	}
+	return round2((resultIncome - totalExpenses) / totalDebt)
+}
+
+// CalculateEvaluate recomputes every derived field of the request from the raw
+// applicant inputs and debt detail, overwriting whatever totals the client sent.
+func CalculateEvaluate(request *models.EvaluateRequest, margin MarginLookup) {
+	resultApplicants := make([]models.ResultApplicantRequest, 0, len(request.Applicants))
+	taken := make([]bool, len(request.Result.Applicants))
+	for i := range request.Applicants {
+		applicant := &request.Applicants[i]
+
+		careerMargin := 0.0
+		if margin != nil {
+			careerMargin = margin(applicant.CareerCategory, applicant.Career)
+		}
+		CalculateBusiness(applicant, careerMargin)
+		CalculateSalary(applicant)
+
+		// Officer-entered expenses are matched to applicants by ID card, so reordering or removing
+		// an applicant does not hand their expenses to someone else. Each row is used once.
+		livingExpenses, otherExpenses := 0.0, 0.0
+		for j, entered := range request.Result.Applicants {
+			if !taken[j] && entered.IDCard == applicant.IDCard {
+				livingExpenses, otherExpenses, taken[j] = entered.LivingExpenses, entered.OtherExpenses, true
+				break
+			}
+		}
+		resultApplicants = append(resultApplicants, CalculateResultApplicant(*applicant, livingExpenses, otherExpenses))
+	}
+
+	request.Result.EvaluateType = request.EvaluateType
+	request.Result.Applicants = resultApplicants
+	request.Result.DebtDetail.TotalDebt = CalculateTotalDebt(request.Result.DebtDetail)
+
+	totalSalary, resultIncome, totalExpenses := Totals(resultApplicants)
+	request.Result.Dti = CalculateDti(request.Result.DebtDetail.TotalDebt, totalSalary)
+	request.Result.Dscr = CalculateDscr(request.Result.DebtDetail.TotalDebt, resultIncome, totalExpenses)
+}
@@ -0,0 +1,249 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+// Expected values follow the formulas in client/src/components/form/SummaryEvaluateFrom.tsx
+
+func TestCustomerExpenseRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		resultIncome float64
+		want         float64
+	}{
+		{"negative income", -500, 0.3},
+		{"zero income", 0, 0.3},
+		{"just below 15000", 14999.99, 0.3},
+		{"exactly 15000", 15000, 0.25},
+		{"between tiers", 50000, 0.25},
+		{"just below 100000", 99999.99, 0.25},
+		{"exactly 100000", 100000, 0.2},
+		{"above 100000", 250000, 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CustomerExpenseRate(tt.resultIncome); got != tt.want {
+				t.Errorf("CustomerExpenseRate(%v) = %v, want %v", tt.resultIncome, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateResultApplicant(t *testing.T) {
+	tests := []struct {
+		name      string
+		applicant models.ApplicantRequest
+		living    float64
+		other     float64
+		want      models.ResultApplicantRequest
+	}{
+		{
+			name: "low income tier",
+			applicant: models.ApplicantRequest{
+				Salary: models.Salary{Base: 14000, Tax: 200},
+			},
+			want: models.ResultApplicantRequest{
+				Salary: 14000, Expenses: 200, TotalSalary: 14000, ResultIncome: 13800,
+				CustomerExpenses: 0.3, ResultCustomerExpenses: 4140, TotalExpenses: 4140,
+			},
+		},
+		{
+			name: "mid tier at the 15000 boundary",
+			applicant: models.ApplicantRequest{
+				Salary: models.Salary{Base: 15750, SocialSecurityFund: 750},
+			},
+			living: 3000,
+			other:  1000,
+			want: models.ResultApplicantRequest{
+				Salary: 15750, Expenses: 750, TotalSalary: 15750, ResultIncome: 15000,
+				CustomerExpenses: 0.25, ResultCustomerExpenses: 3750,
+				LivingExpenses: 3000, OtherExpenses: 1000, TotalExpenses: 7750,
+			},
+		},
+		{
+			name: "high tier at the 100000 boundary with every income source",
+			applicant: models.ApplicantRequest{
+				Salary:        models.Salary{Base: 80000, FreelanceIncome: 5000, Tax: 3000, ProvidentFund: 2000},
+				OtherSalary:   models.OtherSalary{Total: 10000},
+				OptionsSalary: models.OptionsSalary{OtherDocumentedIncome: 6000},
+				ShareHolder:   models.ShareHolder{BankNetProfit: 4000},
+			},
+			want: models.ResultApplicantRequest{
+				Salary: 85000, Expenses: 5000, OtherSalary: 10000, OptionsSalary: 6000,
+				ResultShareValue: 4000, TotalSalary: 105000, ResultIncome: 100000,
+				CustomerExpenses: 0.2, ResultCustomerExpenses: 20000, TotalExpenses: 20000,
+			},
+		},
+		{
+			name: "no income keeps the rate as the minimum expense",
+			want: models.ResultApplicantRequest{
+				CustomerExpenses: 0.3, ResultCustomerExpenses: 0.3, TotalExpenses: 0.3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateResultApplicant(tt.applicant, tt.living, tt.other)
+			if got != tt.want {
+				t.Errorf("CalculateResultApplicant() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateTotalDebt(t *testing.T) {
+	tests := []struct {
+		name string
+		debt models.DebtDetail
+		want float64
+	}{
+		{"no debt", models.DebtDetail{}, 0},
+		{"new loan only", models.DebtDetail{DebtAmount: 3500}, 3500},
+		{
+			"last deduction is not added",
+			models.DebtDetail{DebtAmount: 3000, LastDebt: 1500, DebtReported: 800, DebtNotReported: 200, LastDeduction: 999},
+			5500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateTotalDebt(tt.debt); got != tt.want {
+				t.Errorf("CalculateTotalDebt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateDti(t *testing.T) {
+	tests := []struct {
+		name        string
+		totalDebt   float64
+		totalSalary float64
+		want        float64
+	}{
+		{"quarter of salary", 5000, 20000, 25},
+		{"rounded to two decimals", 1234.567, 10000, 12.35},
+		{"no debt", 0, 20000, 0},
+		{"no salary", 5000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateDti(tt.totalDebt, tt.totalSalary); !almostEqual(got, tt.want) {
+				t.Errorf("CalculateDti(%v, %v) = %v, want %v", tt.totalDebt, tt.totalSalary, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateDscr(t *testing.T) {
+	tests := []struct {
+		name          string
+		totalDebt     float64
+		resultIncome  float64
+		totalExpenses float64
+		want          float64
+	}{
+		{"covers twice", 5000, 18000, 8000, 2},
+		{"rounded to two decimals", 3000, 15000, 7750, 2.42},
+		{"expenses above income", 2000, 10000, 12000, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateDscr(tt.totalDebt, tt.resultIncome, tt.totalExpenses); !almostEqual(got, tt.want) {
+				t.Errorf("CalculateDscr(%v, %v, %v) = %v, want %v", tt.totalDebt, tt.resultIncome, tt.totalExpenses, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateEvaluate(t *testing.T) {
+	request := models.EvaluateRequest{
+		EvaluateType: "loan",
+		Applicants: []models.ApplicantRequest{
+			{Name: "ผู้กู้", Salary: models.Salary{Base: 15750, SocialSecurityFund: 750}},
+			{Name: "ผู้กู้ร่วม", Salary: models.Salary{Base: 14000, Tax: 200}},
+		},
+		Result: models.EvaluateResultRequest{
+			// Client totals are overwritten; officer-entered expenses are kept
+			Applicants: []models.ResultApplicantRequest{
+				{LivingExpenses: 3000, OtherExpenses: 1000, TotalSalary: 999999},
+			},
+			DebtDetail: models.DebtDetail{DebtAmount: 3000, LastDebt: 1000, TotalDebt: 1},
+			Dti:        99,
+			Dscr:       99,
+		},
+	}
+
+	CalculateEvaluate(&request, nil)
+
+	result := request.Result
+	if len(result.Applicants) != 2 {
+		t.Fatalf("got %d result applicants, want 2", len(result.Applicants))
+	}
+	if result.Applicants[0].TotalExpenses != 7750 || result.Applicants[1].TotalExpenses != 4140 {
+		t.Errorf("total expenses = %v, %v, want 7750, 4140", result.Applicants[0].TotalExpenses, result.Applicants[1].TotalExpenses)
+	}
+	if result.DebtDetail.TotalDebt != 4000 {
+		t.Errorf("total debt = %v, want 4000", result.DebtDetail.TotalDebt)
+	}
+	// 4000 / 29750 * 100
+	if !almostEqual(result.Dti, 13.45) {
+		t.Errorf("dti = %v, want 13.45", result.Dti)
+	}
+	// (28800 - 11890) / 4000
+	if !almostEqual(result.Dscr, 4.23) {
+		t.Errorf("dscr = %v, want 4.23", result.Dscr)
+	}
+}
+
+func TestCalculateEvaluateMatchesExpensesByIDCard(t *testing.T) {
+	// The rows the officer entered expenses on, before the applicants changed
+	borrower := models.ResultApplicantRequest{IDCard: "1101700203450", LivingExpenses: 3000, OtherExpenses: 1000}
+	coBorrower := models.ResultApplicantRequest{IDCard: "3100600123450", LivingExpenses: 500, OtherExpenses: 500}
+	removed := models.ResultApplicantRequest{IDCard: "1100400567890", LivingExpenses: 9000, OtherExpenses: 9000}
+
+	tests := []struct {
+		name    string
+		entered []models.ResultApplicantRequest
+		// total expenses of the borrower (3750 customer expenses) and the co-borrower (4140)
+		want []float64
+	}{
+		{"same order", []models.ResultApplicantRequest{borrower, coBorrower}, []float64{7750, 5140}},
+		{"reordered", []models.ResultApplicantRequest{coBorrower, borrower}, []float64{7750, 5140}},
+		{"applicant removed", []models.ResultApplicantRequest{removed, coBorrower}, []float64{3750, 5140}},
+		{"applicant added", []models.ResultApplicantRequest{borrower}, []float64{7750, 4140}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.EvaluateRequest{
+				Applicants: []models.ApplicantRequest{
+					{Name: "ผู้กู้", IDCard: borrower.IDCard, Salary: models.Salary{Base: 15750, SocialSecurityFund: 750}},
+					{Name: "ผู้กู้ร่วม", IDCard: coBorrower.IDCard, Salary: models.Salary{Base: 14000, Tax: 200}},
+				},
+				Result: models.EvaluateResultRequest{Applicants: tt.entered},
+			}
+
+			CalculateEvaluate(&request, nil)
+
+			for i, want := range tt.want {
+				if got := request.Result.Applicants[i].TotalExpenses; got != want {
+					t.Errorf("applicant %d total expenses = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+// Installment returns the first monthly payment of the loan, which is the payment for every
+// period except under equal principal where it is the largest one
+func Installment(terms models.LoanTerms) float64 {
+	if terms.Principal <= 0 || terms.TermMonths <= 0 {
+		return 0
+	}
+
+	n := float64(terms.TermMonths)
+	monthlyRate := terms.AnnualRate / 100 / 12
+
+	switch terms.Method {
+	case models.LoanMethodFlat:
+		return round2((terms.Principal + terms.Principal*monthlyRate*n) / n)
+	case models.LoanMethodEqualPrincipal:
+		return round2(terms.Principal/n + terms.Principal*monthlyRate)
+	default: // models.LoanMethodReducing
+		if monthlyRate == 0 {
+			return round2(terms.Principal / n)
+		}
+		return round2(terms.Principal * monthlyRate / (1 - math.Pow(1+monthlyRate, -n)))
+	}
+}
+
+// AmortizationSchedule lists every period of the loan. Payments are rounded to satang and
+// the last period absorbs the rounding so the balance ends at exactly zero.
+func AmortizationSchedule(terms models.LoanTerms) models.LoanSchedule {
+	schedule := models.LoanSchedule{
+		Terms:              terms,
+		MonthlyInstallment: Installment(terms),
+		Rows:               []models.AmortizationRow{},
+	}
+	if schedule.MonthlyInstallment == 0 {
+		return schedule
+	}
+
+	n := terms.TermMonths
+	monthlyRate := terms.AnnualRate / 100 / 12
+	flatInterest := round2(terms.Principal * monthlyRate)
+	equalPrincipal := round2(terms.Principal / float64(n))
+	balance := terms.Principal
+
+	for period := int64(1); period <= n; period++ {
+		var interest, principal float64
+
+		switch terms.Method {
+		case models.LoanMethodFlat:
+			interest = flatInterest
+			principal = equalPrincipal
+		case models.LoanMethodEqualPrincipal:
+			interest = round2(balance * monthlyRate)
+			principal = equalPrincipal
+		default: // models.LoanMethodReducing
+			interest = round2(balance * monthlyRate)
+			principal = round2(schedule.MonthlyInstallment - interest)
+		}
+
+		if period == n || principal > balance {
+			principal = round2(balance)
+		}
+		balance = round2(balance - principal)
+
+		row := models.AmortizationRow{
+			Period:    period,
+			Payment:   round2(principal + interest),
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		}
+		schedule.Rows = append(schedule.Rows, row)
+		schedule.TotalPayment += row.Payment
+		schedule.TotalInterest += row.Interest
+	}
+
+	schedule.TotalPayment = round2(schedule.TotalPayment)
+	schedule.TotalInterest = round2(schedule.TotalInterest)
+	return schedule
+}
+
+// PrincipalFor is the inverse of Installment: the largest principal whose first payment does not
+// exceed the installment under the terms' rate, term and method
+func PrincipalFor(terms models.LoanTerms, installment float64) float64 {
+	if installment <= 0 || terms.TermMonths <= 0 {
+		return 0
+	}
+
+	n := float64(terms.TermMonths)
+	monthlyRate := terms.AnnualRate / 100 / 12
+
+	var principal float64
+	switch terms.Method {
+	case models.LoanMethodFlat:
+		principal = installment * n / (1 + monthlyRate*n)
+	case models.LoanMethodEqualPrincipal:
+		principal = installment / (1/n + monthlyRate)
+	default: // models.LoanMethodReducing
+		if monthlyRate == 0 {
+			principal = installment * n
+		} else {
+			principal = installment * (1 - math.Pow(1+monthlyRate, -n)) / monthlyRate
+		}
+	}
+
+	// Round down so the derived installment never rounds back above the limit
+	return math.Floor(principal*100) / 100
+}
+
+// MaxLoan solves the evaluate for the largest new monthly installment that keeps the DTI at or
+// below maxDti and the DSCR at or above minDscr (a zero cap is not applied), and the principal
+// that installment buys under terms. The request is recalculated in place with no new debt.
+func MaxLoan(request *models.EvaluateRequest, terms models.LoanTerms, maxDti float64, minDscr float64, margin MarginLookup) models.MaxLoanResult {
+	request.Result.DebtDetail.DebtAmount = 0
+	CalculateEvaluate(request, margin)
+
+	existingDebt := CalculateTotalDebt(request.Result.DebtDetail)
+	totalSalary, resultIncome, totalExpenses := Totals(request.Result.Applicants)
+
+	result := models.MaxLoanResult{
+		Terms:         terms,
+		ExistingDebt:  round2(existingDebt),
+		TotalSalary:   round2(totalSalary),
+		ResultIncome:  round2(resultIncome),
+		TotalExpenses: round2(totalExpenses),
+	}
+
+	limit := func(threshold float64, maxTotalDebt float64) *models.MaxLoanLimit {
+		installment := math.Max(0, math.Floor((maxTotalDebt-existingDebt)*100)/100)
+		return &models.MaxLoanLimit{
+			Limit:          threshold,
+			MaxInstallment: installment,
+			MaxPrincipal:   PrincipalFor(terms, installment),
+		}
+	}
+
+	// DTI = total debt / total salary * 100
+	if maxDti > 0 {
+		result.Dti = limit(maxDti, totalSalary*maxDti/100)
+	}
+
+	// DSCR = (net income - expenses) / total debt
+	if minDscr > 0 {
+		result.Dscr = limit(minDscr, (resultIncome-totalExpenses)/minDscr)
+	}
+
+	switch {
+	case result.Dti != nil && (result.Dscr == nil || result.Dti.MaxInstallment <= result.Dscr.MaxInstallment):
+		result.Binding = "dti"
+		result.MaxInstallment, result.MaxPrincipal = result.Dti.MaxInstallment, result.Dti.MaxPrincipal
+	case result.Dscr != nil:
+		result.Binding = "dscr"
+		result.MaxInstallment, result.MaxPrincipal = result.Dscr.MaxInstallment, result.Dscr.MaxPrincipal
+	}
+
+	return result
+}
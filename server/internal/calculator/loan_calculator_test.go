@@ -0,0 +1,247 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+var loanMethods = []string{models.LoanMethodFlat, models.LoanMethodReducing, models.LoanMethodEqualPrincipal}
+
+func TestInstallment(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms models.LoanTerms
+		want  float64
+	}{
+		// (120000 + 120000 * 0.005 * 12) / 12
+		{"flat", models.LoanTerms{Principal: 120000, AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodFlat}, 10600},
+		{"reducing", models.LoanTerms{Principal: 100000, AnnualRate: 12, TermMonths: 12, Method: models.LoanMethodReducing}, 8884.88},
+		{"empty method is reducing", models.LoanTerms{Principal: 100000, AnnualRate: 12, TermMonths: 12}, 8884.88},
+		// 120000 / 12 + 120000 * 0.005
+		{"equal principal", models.LoanTerms{Principal: 120000, AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodEqualPrincipal}, 10600},
+		{"flat at zero rate", models.LoanTerms{Principal: 100000, TermMonths: 3, Method: models.LoanMethodFlat}, 33333.33},
+		{"reducing at zero rate", models.LoanTerms{Principal: 100000, TermMonths: 3, Method: models.LoanMethodReducing}, 33333.33},
+		{"equal principal at zero rate", models.LoanTerms{Principal: 100000, TermMonths: 3, Method: models.LoanMethodEqualPrincipal}, 33333.33},
+		{"no principal", models.LoanTerms{AnnualRate: 6, TermMonths: 12}, 0},
+		{"no term", models.LoanTerms{Principal: 100000, AnnualRate: 6}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Installment(tt.terms); got != tt.want {
+				t.Errorf("Installment(%+v) = %v, want %v", tt.terms, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmortizationSchedule(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms models.LoanTerms
+		last  models.AmortizationRow
+	}{
+		{
+			"flat",
+			models.LoanTerms{Principal: 120000, AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodFlat},
+			models.AmortizationRow{Period: 12, Payment: 10600, Principal: 10000, Interest: 600},
+		},
+		{
+			// The last payment is short by the satang the rounded installment overpaid
+			"reducing",
+			models.LoanTerms{Principal: 100000, AnnualRate: 12, TermMonths: 12, Method: models.LoanMethodReducing},
+			models.AmortizationRow{Period: 12, Payment: 8884.85, Principal: 8796.88, Interest: 87.97},
+		},
+		{
+			"equal principal",
+			models.LoanTerms{Principal: 120000, AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodEqualPrincipal},
+			models.AmortizationRow{Period: 12, Payment: 10050, Principal: 10000, Interest: 50},
+		},
+		{
+			// 33333.33 twice, the last period takes the remaining satang
+			"flat at zero rate",
+			models.LoanTerms{Principal: 100000, TermMonths: 3, Method: models.LoanMethodFlat},
+			models.AmortizationRow{Period: 3, Payment: 33333.34, Principal: 33333.34},
+		},
+		{
+			"reducing at zero rate",
+			models.LoanTerms{Principal: 100000, TermMonths: 3, Method: models.LoanMethodReducing},
+			models.AmortizationRow{Period: 3, Payment: 33333.34, Principal: 33333.34},
+		},
+		{
+			"equal principal at zero rate",
+			models.LoanTerms{Principal: 100000, TermMonths: 3, Method: models.LoanMethodEqualPrincipal},
+			models.AmortizationRow{Period: 3, Payment: 33333.34, Principal: 33333.34},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := AmortizationSchedule(tt.terms)
+			if int64(len(schedule.Rows)) != tt.terms.TermMonths {
+				t.Fatalf("got %d rows, want %d", len(schedule.Rows), tt.terms.TermMonths)
+			}
+			if last := schedule.Rows[len(schedule.Rows)-1]; last != tt.last {
+				t.Errorf("last row = %+v, want %+v", last, tt.last)
+			}
+			if schedule.MonthlyInstallment != Installment(tt.terms) || schedule.Rows[0].Payment != schedule.MonthlyInstallment {
+				t.Errorf("first payment = %v, monthly installment = %v, want %v",
+					schedule.Rows[0].Payment, schedule.MonthlyInstallment, Installment(tt.terms))
+			}
+
+			balance, principal, payment, interest := tt.terms.Principal, 0.0, 0.0, 0.0
+			for _, row := range schedule.Rows {
+				balance = round2(balance - row.Principal)
+				if row.Balance != balance || row.Payment != round2(row.Principal+row.Interest) {
+					t.Fatalf("row %+v does not follow from balance %v", row, balance)
+				}
+				principal += row.Principal
+				payment += row.Payment
+				interest += row.Interest
+			}
+			if !almostEqual(principal, tt.terms.Principal) {
+				t.Errorf("principal repaid = %v, want %v", principal, tt.terms.Principal)
+			}
+			if !almostEqual(schedule.TotalPayment, payment) || !almostEqual(schedule.TotalInterest, interest) {
+				t.Errorf("totals = %v, %v, want %v, %v", schedule.TotalPayment, schedule.TotalInterest, payment, interest)
+			}
+		})
+	}
+}
+
+func TestAmortizationScheduleEndsAtZero(t *testing.T) {
+	for _, method := range loanMethods {
+		for _, rate := range []float64{0, 0.5, 6, 7.25, 18} {
+			for _, months := range []int64{1, 7, 36, 120} {
+				terms := models.LoanTerms{Principal: 123456.78, AnnualRate: rate, TermMonths: months, Method: method}
+				rows := AmortizationSchedule(terms).Rows
+				if last := rows[len(rows)-1]; last.Balance != 0 {
+					t.Errorf("%+v ends at balance %v", terms, last.Balance)
+				}
+				for _, row := range rows {
+					if row.Principal < 0 || row.Balance < 0 {
+						t.Errorf("%+v has a negative row %+v", terms, row)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAmortizationScheduleWithoutTerms(t *testing.T) {
+	schedule := AmortizationSchedule(models.LoanTerms{})
+	if schedule.MonthlyInstallment != 0 || schedule.Rows == nil || len(schedule.Rows) != 0 {
+		t.Errorf("AmortizationSchedule() without terms = %+v, want no rows", schedule)
+	}
+}
+
+func TestPrincipalForRoundTrip(t *testing.T) {
+	for _, method := range loanMethods {
+		for _, rate := range []float64{0, 6, 12.5} {
+			for _, months := range []int64{1, 12, 60} {
+				for _, principal := range []float64{1000, 100000, 987654.32} {
+					terms := models.LoanTerms{Principal: principal, AnnualRate: rate, TermMonths: months, Method: method}
+					installment := Installment(terms)
+
+					got := PrincipalFor(terms, installment)
+					// Installment rounds to satang, which moves the principal by at most a satang a period
+					if math.Abs(got-principal) > 0.01*float64(months) {
+						t.Errorf("PrincipalFor(%+v, %v) = %v, want about %v", terms, installment, got, principal)
+					}
+
+					terms.Principal = got
+					if derived := Installment(terms); derived > installment {
+						t.Errorf("Installment of PrincipalFor(%v) = %v, above %v (%s, %v%%, %d months)",
+							installment, derived, installment, method, rate, months)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPrincipalFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		terms       models.LoanTerms
+		installment float64
+		want        float64
+	}{
+		{"flat", models.LoanTerms{AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodFlat}, 10600, 120000},
+		{"equal principal", models.LoanTerms{AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodEqualPrincipal}, 10600, 120000},
+		{"reducing at zero rate", models.LoanTerms{TermMonths: 3, Method: models.LoanMethodReducing}, 33333.33, 99999.99},
+		{"no installment", models.LoanTerms{AnnualRate: 6, TermMonths: 12}, 0, 0},
+		{"no term", models.LoanTerms{AnnualRate: 6}, 10600, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrincipalFor(tt.terms, tt.installment); got != tt.want {
+				t.Errorf("PrincipalFor(%+v, %v) = %v, want %v", tt.terms, tt.installment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxLoan(t *testing.T) {
+	terms := models.LoanTerms{AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodFlat}
+
+	// stressRequest: total salary 59000, net income 58000, expenses 22500, 2000 of existing debt
+	noIncome := stressRequest()
+	noIncome.Applicants[0] = models.ApplicantRequest{Name: "ผู้กู้", IDCard: "1101700203450"}
+
+	tests := []struct {
+		name        string
+		request     models.EvaluateRequest
+		maxDti      float64
+		minDscr     float64
+		binding     string
+		installment float64
+		principal   float64
+	}{
+		// DTI: 59000 * 40% - 2000 = 21600, DSCR: 35500 / 1.5 - 2000 = 21666.66; 21600 * 12 / 1.06
+		{"dti binds", stressRequest(), 40, 1.5, "dti", 21600, 244528.30},
+		// DSCR: 35500 / 2 - 2000 = 15750 is below the DTI's 21600; 15750 * 12 / 1.06
+		{"dscr binds", stressRequest(), 40, 2, "dscr", 15750, 178301.88},
+		{"only dti", stressRequest(), 40, 0, "dti", 21600, 244528.30},
+		{"only dscr", stressRequest(), 0, 2, "dscr", 15750, 178301.88},
+		// Nothing to borrow against: both limits floor at zero instead of going negative
+		{"zero income", noIncome, 40, 1.5, "dti", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+			request.Result.DebtDetail.DebtAmount = 9000
+
+			got := MaxLoan(&request, terms, tt.maxDti, tt.minDscr, nil)
+			if got.Binding != tt.binding {
+				t.Errorf("binding = %q, want %q", got.Binding, tt.binding)
+			}
+			if !almostEqual(got.MaxInstallment, tt.installment) || !almostEqual(got.MaxPrincipal, tt.principal) {
+				t.Errorf("max = %v / %v, want %v / %v", got.MaxInstallment, got.MaxPrincipal, tt.installment, tt.principal)
+			}
+			if (got.Dti != nil) != (tt.maxDti > 0) || (got.Dscr != nil) != (tt.minDscr > 0) {
+				t.Errorf("limits = %+v, %+v for caps %v, %v", got.Dti, got.Dscr, tt.maxDti, tt.minDscr)
+			}
+			// The entered installment is not counted as existing debt
+			if got.ExistingDebt != 2000 || request.Result.DebtDetail.DebtAmount != 0 {
+				t.Errorf("existing debt = %v, debt amount = %v", got.ExistingDebt, request.Result.DebtDetail.DebtAmount)
+			}
+		})
+	}
+}
+
+func TestMaxLoanLimitsNeverNegative(t *testing.T) {
+	request := stressRequest()
+	// 20000 already owed is above both caps
+	request.Result.DebtDetail.LastDebt = 20000
+
+	got := MaxLoan(&request, models.LoanTerms{AnnualRate: 6, TermMonths: 12}, 20, 2, nil)
+	if got.Dti.MaxInstallment != 0 || got.Dscr.MaxInstallment != 0 || got.MaxPrincipal != 0 {
+		t.Errorf("MaxLoan() over the caps = %+v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+// Recommend checks a computed result against a policy and returns the verdict
+// with every reason it failed. A nil policy always fails.
+func Recommend(result models.EvaluateResultRequest, policy *models.EvaluatePolicy) models.Recommendation {
+	if policy == nil {
+		return models.Recommendation{
+			Passed:  false,
+			Reasons: []string{"ไม่พบเกณฑ์การอนุมัติสำหรับประเภทสินเชื่อนี้"},
+		}
+	}
+
+	reasons := []string{}
+
+	if policy.MaxDti > 0 && result.Dti > policy.MaxDti {
+		reasons = append(reasons, fmt.Sprintf("DTI %.2f%% เกินเกณฑ์สูงสุด %.2f%%", result.Dti, policy.MaxDti))
+	}
+
+	// Without debt there is nothing to cover, and CalculateDscr reports 0
+	if policy.MinDscr > 0 && result.DebtDetail.TotalDebt > 0 && result.Dscr < policy.MinDscr {
+		reasons = append(reasons, fmt.Sprintf("DSCR %.2f เท่า ต่ำกว่าเกณฑ์ขั้นต่ำ %.2f เท่า", result.Dscr, policy.MinDscr))
+	}
+
+	_, resultIncome, _ := Totals(result.Applicants)
+	if policy.MinNetIncome > 0 && resultIncome < policy.MinNetIncome {
+		reasons = append(reasons, fmt.Sprintf("รายได้สุทธิรวม %.2f บาท/เดือน ต่ำกว่าเกณฑ์ขั้นต่ำ %.2f บาท/เดือน", resultIncome, policy.MinNetIncome))
+	}
+
+	coBorrowers := int64(len(result.Applicants) - 1)
+	if policy.MaxCoBorrowers > 0 && coBorrowers > policy.MaxCoBorrowers {
+		reasons = append(reasons, fmt.Sprintf("จำนวนผู้กู้ร่วม %d คน เกินเกณฑ์สูงสุด %d คน", coBorrowers, policy.MaxCoBorrowers))
+	}
+
+	if result.ShareCredit.Exceeded {
+		reasons = append(reasons, result.ShareCredit.Warning)
+	}
+
+	policyID := policy.Id
+	return models.Recommendation{
+		Passed:   len(reasons) == 0,
+		PolicyID: &policyID,
+		Reasons:  reasons,
+	}
+}
+
+// ShareCreditLimit works out the borrower's share-backed credit limit. Without a linked member
+// or a configured multiple only the member's shares are reported; without a known principal the
+// limit is reported but not checked, since a monthly installment is not comparable to it.
+func ShareCreditLimit(member *models.Member, principal float64, policy *models.EvaluatePolicy) models.ShareCredit {
+	if member == nil {
+		return models.ShareCredit{}
+	}
+
+	memberID := member.Id
+	credit := models.ShareCredit{
+		MemberID:    &memberID,
+		SharesNum:   member.SharesNum,
+		SharesValue: member.SharesValue,
+	}
+
+	if policy == nil || policy.ShareMultiple <= 0 {
+		return credit
+	}
+
+	credit.Multiple = policy.ShareMultiple
+	credit.MaxLoan = round2(member.SharesValue * policy.ShareMultiple)
+	if principal > 0 && principal > credit.MaxLoan {
+		credit.Exceeded = true
+		credit.Warning = fmt.Sprintf("ยอดขอกู้ %.2f บาท เกินวงเงินกู้ตามหุ้น %.2f บาท (%.2f เท่าของทุนเรือนหุ้น %.2f บาท)",
+			principal, credit.MaxLoan, credit.Multiple, credit.SharesValue)
+	}
+
+	return credit
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+func TestRecommend(t *testing.T) {
+	policy := &models.EvaluatePolicy{MaxDti: 40, MinDscr: 1.5, MinNetIncome: 10000, MaxCoBorrowers: 1}
+	applicant := models.ResultApplicantRequest{ResultIncome: 20000}
+
+	tests := []struct {
+		name        string
+		result      models.EvaluateResultRequest
+		wantPassed  bool
+		wantReasons int
+	}{
+		{
+			name: "within every limit",
+			result: models.EvaluateResultRequest{
+				Applicants: []models.ResultApplicantRequest{applicant},
+				DebtDetail: models.DebtDetail{TotalDebt: 5000},
+				Dti:        25,
+				Dscr:       2,
+			},
+			wantPassed: true,
+		},
+		{
+			name: "no debt passes the DSCR check",
+			result: models.EvaluateResultRequest{
+				Applicants: []models.ResultApplicantRequest{applicant},
+				Dti:        0,
+				Dscr:       CalculateDscr(0, 20000, 5000),
+			},
+			wantPassed: true,
+		},
+		{
+			name: "debt with low DSCR fails",
+			result: models.EvaluateResultRequest{
+				Applicants: []models.ResultApplicantRequest{applicant},
+				DebtDetail: models.DebtDetail{TotalDebt: 5000},
+				Dti:        25,
+				Dscr:       1.2,
+			},
+			wantReasons: 1,
+		},
+		{
+			name: "every rule broken",
+			result: models.EvaluateResultRequest{
+				Applicants: []models.ResultApplicantRequest{{ResultIncome: 3000}, {ResultIncome: 3000}, {ResultIncome: 3000}},
+				DebtDetail: models.DebtDetail{TotalDebt: 5000},
+				Dti:        55,
+				Dscr:       0.5,
+			},
+			wantReasons: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Recommend(tt.result, policy)
+			if got.Passed != tt.wantPassed || len(got.Reasons) != tt.wantReasons {
+				t.Errorf("Recommend() passed = %v with reasons %q, want passed = %v with %d reasons",
+					got.Passed, got.Reasons, tt.wantPassed, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestRecommendWithoutPolicy(t *testing.T) {
+	if got := Recommend(models.EvaluateResultRequest{}, nil); got.Passed || len(got.Reasons) != 1 {
+		t.Errorf("Recommend() without policy = %+v, want a single failing reason", got)
+	}
+}
+
+func TestShareCreditLimit(t *testing.T) {
+	member := &models.Member{SharesNum: 100, SharesValue: 10000}
+	policy := &models.EvaluatePolicy{ShareMultiple: 5}
+
+	tests := []struct {
+		name         string
+		member       *models.Member
+		principal    float64
+		policy       *models.EvaluatePolicy
+		wantMaxLoan  float64
+		wantExceeded bool
+	}{
+		{"no linked member", nil, 80000, policy, 0, false},
+		{"no multiple configured", member, 80000, &models.EvaluatePolicy{}, 0, false},
+		{"within the limit", member, 50000, policy, 50000, false},
+		{"above the limit", member, 50000.01, policy, 50000, true},
+		{"no principal known", member, 0, policy, 50000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShareCreditLimit(tt.member, tt.principal, tt.policy)
+			if got.MaxLoan != tt.wantMaxLoan || got.Exceeded != tt.wantExceeded || (got.Warning != "") != tt.wantExceeded {
+				t.Errorf("ShareCreditLimit(%v) = %+v, want maxLoan %v, exceeded %v", tt.principal, got, tt.wantMaxLoan, tt.wantExceeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package calculator
+
+import (
+	"math"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+// DefaultStressShocks are run when the officer does not configure any scenario
+var DefaultStressShocks = []models.StressShock{
+	{Name: "ดอกเบี้ยเพิ่มขึ้น 1%", RateIncrease: 1},
+	{Name: "ดอกเบี้ยเพิ่มขึ้น 2%", RateIncrease: 2},
+	{Name: "ดอกเบี้ยเพิ่มขึ้น 3%", RateIncrease: 3},
+	{Name: "รายได้ลดลง 10%", SalaryReduction: 10, BusinessReduction: 10, OptionsReduction: 10},
+	{Name: "กำไรธุรกิจลดลง 30%", BusinessReduction: 30},
+	{Name: "รายได้ไม่ประจำลดลง 50%", OptionsReduction: 50},
+	{Name: "ค่าใช้จ่ายอุปโภคบริโภคเพิ่มขึ้น 5%", ExpenseRateIncrease: 5},
+	{Name: "รวมหลายปัจจัย", RateIncrease: 2, SalaryReduction: 10, BusinessReduction: 20, OptionsReduction: 50, ExpenseRateIncrease: 5},
+}
+
+// StressEvaluate recalculates a copy of the request under the shock and checks it against the
+// policy. Income cuts are applied to the computed buckets (salary and regular allowances, the
+// business net profit share, other documented income) so the career margin is not re-applied.
+func StressEvaluate(base models.EvaluateRequest, shock models.StressShock, margin MarginLookup, policy *models.EvaluatePolicy) models.StressScenarioResult {
+	request := base
+	request.Applicants = append([]models.ApplicantRequest(nil), base.Applicants...)
+	request.Result.Applicants = append([]models.ResultApplicantRequest(nil), base.Result.Applicants...)
+
+	scenario := models.StressScenarioResult{Shock: shock}
+
+	if shock.RateIncrease != 0 {
+		if request.LoanTerms.Principal > 0 {
+			request.LoanTerms.AnnualRate += shock.RateIncrease
+		} else {
+			scenario.Warning = "แบบประเมินนี้ไม่ได้ระบุเงื่อนไขเงินกู้ จึงไม่สามารถปรับค่างวดตามอัตราดอกเบี้ยได้"
+		}
+	}
+	if request.LoanTerms.Principal > 0 {
+		request.Result.DebtDetail.DebtAmount = Installment(request.LoanTerms)
+	}
+
+	CalculateEvaluate(&request, margin)
+
+	salaryKeep := 1 - shock.SalaryReduction/100
+	businessKeep := 1 - shock.BusinessReduction/100
+	optionsKeep := 1 - shock.OptionsReduction/100
+
+	for i := range request.Applicants {
+		applicant := request.Applicants[i]
+		applicant.Salary.Base *= salaryKeep
+		applicant.Salary.FreelanceIncome *= salaryKeep
+		applicant.OtherSalary.Total *= salaryKeep
+		applicant.ShareHolder.BankNetProfit = round2(applicant.ShareHolder.BankNetProfit * businessKeep)
+		applicant.OptionsSalary.OtherDocumentedIncome *= optionsKeep
+
+		current := request.Result.Applicants[i]
+		livingExpenses := math.Max(current.LivingExpenses, shock.LivingExpenseFloor)
+		stressed := CalculateResultApplicant(applicant, livingExpenses, current.OtherExpenses)
+
+		if shock.ExpenseRateIncrease != 0 {
+			stressed.CustomerExpenses += shock.ExpenseRateIncrease / 100
+			stressed.ResultCustomerExpenses = math.Max(stressed.CustomerExpenses, stressed.ResultIncome*stressed.CustomerExpenses)
+			stressed.TotalExpenses = stressed.ResultCustomerExpenses + stressed.LivingExpenses + stressed.OtherExpenses
+		}
+		request.Result.Applicants[i] = stressed
+	}
+
+	totalSalary, resultIncome, totalExpenses := Totals(request.Result.Applicants)
+	request.Result.Dti = CalculateDti(request.Result.DebtDetail.TotalDebt, totalSalary)
+	request.Result.Dscr = CalculateDscr(request.Result.DebtDetail.TotalDebt, resultIncome, totalExpenses)
+
+	scenario.DebtAmount = round2(request.Result.DebtDetail.DebtAmount)
+	scenario.TotalDebt = round2(request.Result.DebtDetail.TotalDebt)
+	scenario.Dti = request.Result.Dti
+	scenario.Dscr = request.Result.Dscr
+	scenario.Recommendation = Recommend(request.Result, policy)
+	scenario.Breaks = !scenario.Recommendation.Passed
+	return scenario
+}
+
+// StressTest runs the baseline and every shock over the same request
+func StressTest(request models.EvaluateRequest, shocks []models.StressShock, margin MarginLookup, policy *models.EvaluatePolicy) (models.StressScenarioResult, []models.StressScenarioResult) {
+	baseline := StressEvaluate(request, models.StressShock{Name: "กรณีปกติ"}, margin, policy)
+
+	scenarios := make([]models.StressScenarioResult, 0, len(shocks))
+	for _, shock := range shocks {
+		scenario := StressEvaluate(request, shock, margin, policy)
+		scenario.DtiChange = round2(scenario.Dti - baseline.Dti)
+		scenario.DscrChange = round2(scenario.Dscr - baseline.Dscr)
+		scenarios = append(scenarios, scenario)
+	}
+	return baseline, scenarios
+}
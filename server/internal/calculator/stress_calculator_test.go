@@ -0,0 +1,171 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+// stressRequest is one borrower with every income bucket and a flat loan of 10600 a month.
+//
+// Baseline: total salary 40000 + 5000 + 4000 + 10000 = 59000, net income 58000 after 1000 tax,
+// expenses 58000 * 0.25 + 6000 + 2000 = 22500, total debt 10600 + 2000 = 12600.
+func stressRequest() models.EvaluateRequest {
+	return models.EvaluateRequest{
+		Applicants: []models.ApplicantRequest{{
+			Name:             "ผู้กู้",
+			IDCard:           "1101700203450",
+			Salary:           models.Salary{Base: 40000, Tax: 1000},
+			OtherSalary:      models.OtherSalary{LivingSalary: 5000},
+			OptionsSalary:    models.OptionsSalary{Bonus: 4000},
+			BusinessActivity: models.BusinessActivity{Salary: 20000},
+			ExpenseItem:      models.ExpenseItem{CostPercentage: 50},
+			ShareHolder:      models.ShareHolder{ShareOfNetProfit: 100},
+		}},
+		Result: models.EvaluateResultRequest{
+			Applicants: []models.ResultApplicantRequest{{IDCard: "1101700203450", LivingExpenses: 6000, OtherExpenses: 2000}},
+			DebtDetail: models.DebtDetail{LastDebt: 2000},
+		},
+		LoanTerms: models.LoanTerms{Principal: 120000, AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodFlat},
+	}
+}
+
+var stressPolicy = &models.EvaluatePolicy{MaxDti: 23, MinDscr: 2.5}
+
+func TestStressEvaluateDefaultShocks(t *testing.T) {
+	tests := []struct {
+		name       string
+		debtAmount float64
+		dti        float64
+		dscr       float64
+		breaks     bool
+	}{
+		// 12700 / 59000, 35500 / 12700
+		{"ดอกเบี้ยเพิ่มขึ้น 1%", 10700, 21.53, 2.80, false},
+		{"ดอกเบี้ยเพิ่มขึ้น 2%", 10800, 21.69, 2.77, false},
+		{"ดอกเบี้ยเพิ่มขึ้น 3%", 10900, 21.86, 2.75, false},
+		// Salary, allowance, business share and bonus all keep 90%: 36000 + 4500 + 9000 + 3600 = 53100,
+		// the tax deduction is not cut. (52100 - 21025) / 12600
+		{"รายได้ลดลง 10%", 10600, 23.73, 2.47, true},
+		// Business share 7000 of the computed 10000: 12600 / 56000, (55000 - 21750) / 12600
+		{"กำไรธุรกิจลดลง 30%", 10600, 22.5, 2.64, false},
+		// Bonus 2000 of 4000: 12600 / 57000, (56000 - 22000) / 12600
+		{"รายได้ไม่ประจำลดลง 50%", 10600, 22.11, 2.70, false},
+		// Income unchanged, expense rate 0.30: (58000 - 25400) / 12600
+		{"ค่าใช้จ่ายอุปโภคบริโภคเพิ่มขึ้น 5%", 10600, 21.36, 2.59, false},
+		// 12800 / 50500, (49500 - 49500 * 0.3 - 8000) / 12800
+		{"รวมหลายปัจจัย", 10800, 25.35, 2.08, true},
+	}
+
+	if len(DefaultStressShocks) != len(tests) {
+		t.Fatalf("got %d default shocks, the table covers %d", len(DefaultStressShocks), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shock := DefaultStressShocks[i]
+			if shock.Name != tt.name {
+				t.Fatalf("default shock %d is %q, want %q", i, shock.Name, tt.name)
+			}
+
+			got := StressEvaluate(stressRequest(), shock, nil, stressPolicy)
+			if !almostEqual(got.DebtAmount, tt.debtAmount) || !almostEqual(got.TotalDebt, tt.debtAmount+2000) {
+				t.Errorf("debt = %v, total %v, want %v, total %v", got.DebtAmount, got.TotalDebt, tt.debtAmount, tt.debtAmount+2000)
+			}
+			if !almostEqual(got.Dti, tt.dti) || !almostEqual(got.Dscr, tt.dscr) {
+				t.Errorf("dti, dscr = %v, %v, want %v, %v", got.Dti, got.Dscr, tt.dti, tt.dscr)
+			}
+			if got.Breaks != tt.breaks {
+				t.Errorf("breaks = %v, want %v (%v)", got.Breaks, tt.breaks, got.Recommendation.Reasons)
+			}
+			if got.Warning != "" {
+				t.Errorf("unexpected warning %q", got.Warning)
+			}
+		})
+	}
+}
+
+func TestStressEvaluateBaseline(t *testing.T) {
+	got := StressEvaluate(stressRequest(), models.StressShock{Name: "กรณีปกติ"}, nil, stressPolicy)
+	// 12600 / 59000, 35500 / 12600
+	if got.DebtAmount != 10600 || !almostEqual(got.Dti, 21.36) || !almostEqual(got.Dscr, 2.82) || got.Breaks {
+		t.Errorf("baseline = %+v", got)
+	}
+}
+
+func TestStressEvaluateRateShockNeedsPrincipal(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  float64
+		debtAmount float64
+		warns      bool
+	}{
+		{"principal set", 120000, 10700, false},
+		// The entered installment is kept as it is
+		{"no principal", 0, 9000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := stressRequest()
+			request.LoanTerms.Principal = tt.principal
+			request.Result.DebtDetail.DebtAmount = 9000
+
+			got := StressEvaluate(request, models.StressShock{Name: "ดอกเบี้ย", RateIncrease: 1}, nil, stressPolicy)
+			if got.DebtAmount != tt.debtAmount {
+				t.Errorf("debt amount = %v, want %v", got.DebtAmount, tt.debtAmount)
+			}
+			if (got.Warning != "") != tt.warns {
+				t.Errorf("warning = %q, want one: %v", got.Warning, tt.warns)
+			}
+		})
+	}
+}
+
+func TestStressEvaluateWithoutPrincipalOnlyWarnsOnRateShocks(t *testing.T) {
+	request := stressRequest()
+	request.LoanTerms = models.LoanTerms{}
+	request.Result.DebtDetail.DebtAmount = 9000
+
+	got := StressEvaluate(request, models.StressShock{Name: "รายได้ลดลง", SalaryReduction: 10}, nil, stressPolicy)
+	if got.Warning != "" || got.DebtAmount != 9000 {
+		t.Errorf("income shock without principal = %+v", got)
+	}
+}
+
+func TestStressEvaluateExpenseShocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		shock models.StressShock
+		dscr  float64
+	}{
+		// (58000 - 58000 * 0.35 - 8000) / 12600
+		{"expense rate", models.StressShock{ExpenseRateIncrease: 10}, 2.36},
+		// Living expenses raised from 6000 to the floor: (58000 - 14500 - 9000 - 2000) / 12600
+		{"living expense floor", models.StressShock{LivingExpenseFloor: 9000}, 2.58},
+		// A floor below what was entered changes nothing
+		{"floor below entered", models.StressShock{LivingExpenseFloor: 3000}, 2.82},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StressEvaluate(stressRequest(), tt.shock, nil, stressPolicy)
+			if !almostEqual(got.Dscr, tt.dscr) {
+				t.Errorf("dscr = %v, want %v", got.Dscr, tt.dscr)
+			}
+			// Expenses do not move the DTI
+			if !almostEqual(got.Dti, 21.36) {
+				t.Errorf("dti = %v, want 21.36", got.Dti)
+			}
+		})
+	}
+}
+
+func TestStressEvaluateLeavesRequestUntouched(t *testing.T) {
+	request := stressRequest()
+	StressEvaluate(request, DefaultStressShocks[len(DefaultStressShocks)-1], nil, stressPolicy)
+
+	if request.LoanTerms.AnnualRate != 6 || request.Result.Applicants[0].LivingExpenses != 6000 || request.Applicants[0].Salary.Base != 40000 {
+		t.Errorf("StressEvaluate() changed the base request: %+v", request)
+	}
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"os"
 	"strconv"
 	"strings"
@@ -10,9 +11,50 @@ import (
 	"github.com/google/uuid"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/validation"
 	"github.com/gofiber/fiber/v3"
 )
 
+const refreshCookieName = "refresh_token"
+
+// sessionCookie builds an HTTP-only cookie with the production/development cross-site settings
+func sessionCookie(name string, value string, path string, expires time.Time) *fiber.Cookie {
+	// is production
+	isProd := os.Getenv("ENV") == "production"
+
+	// cookie settings
+	sameSite := fiber.CookieSameSiteNoneMode
+	secure := isProd
+
+	// development settings
+	if !isProd {
+		sameSite = fiber.CookieSameSiteLaxMode
+		secure = false
+	}
+
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Expires:  expires,
+		Path:     path,
+		Secure:   secure,
+		HTTPOnly: true,
+		SameSite: sameSite,
+	}
+}
+
+// The refresh token is only sent to the auth endpoints
+func setSessionCookies(c fiber.Ctx, tokens *services.SessionTokens) {
+	c.Cookie(sessionCookie("jwt", tokens.AccessToken, "/", tokens.AccessExpiresAt))
+	c.Cookie(sessionCookie(refreshCookieName, tokens.RefreshToken, "/api/v1/auth", tokens.RefreshExpiresAt))
+}
+
+func clearSessionCookies(c fiber.Ctx) {
+	expired := time.Now().Add(-1 * time.Hour)
+	c.Cookie(sessionCookie("jwt", "", "/", expired))
+	c.Cookie(sessionCookie(refreshCookieName, "", "/api/v1/auth", expired))
+}
+
 // Register Admin
 func RegisterAdmin(c fiber.Ctx) error {
 	var request models.AdminRegister
@@ -30,11 +72,13 @@ func RegisterAdmin(c fiber.Ctx) error {
 		})
 	}
 
-	if len(request.Username) < 13 {
+	username, err := validation.ValidateThaiID(request.Username)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "กรุณากรอกเลขบัตรประชาชนให้ถูกต้อง",
+			"message": err.Error(),
 		})
 	}
+	request.Username = username
 
 	if len(request.Password) < 5 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -42,164 +86,187 @@ func RegisterAdmin(c fiber.Ctx) error {
 		})
 	}
 
-	// Exists username
-	var existingUser models.Admin
-	isUsernameExists := database.DB.Where("username = ?", request.Username).First(&existingUser).Error == nil
-	if isUsernameExists {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "เลขบัตรประชาชนนี้ถูกใช้แล้ว",
+	// A taken ID card or name gets the same answer, after the same work, as a new one
+	if err := services.RegisterAdmin(request.Username, request.Password, request.FullName); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "เกิดข้อผิดพลาดในการสร้างผู้ใช้",
 		})
 	}
 
-	// Make clean input
-	cleanFullname := strings.ReplaceAll(request.FullName, " ", "")
+	// No session is opened: the account can do nothing until it has a role and a cooperative
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "ส่งคำขอลงทะเบียนแล้ว กรุณารอผู้ดูแลระบบกำหนดสิทธิ์ก่อนเข้าสู่ระบบ",
+	})
+}
 
-	var count int64
-	database.DB.Model(&models.Admin{}).
-		Where("REPLACE(full_name, ' ', '') = ?", cleanFullname).
-		Count(&count)
+func LoginAdmin(c fiber.Ctx) error {
+	var request models.AdminLogin
 
-	if count > 0 {
+	// Validate data
+	if err := c.Bind().Body(&request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "ชื่อ-นามสกุลถูกใช้แล้ว",
+			"message": "กรุณากรอกข้อมูลที่จำเป็น",
 		})
 	}
 
-	// Hash password
-	hashedPassword, err := services.HashPassword(request.Password)
-	if err != nil {
+	// Accept the ID card typed with dashes
+	username := validation.NormalizeThaiID(request.Username)
+
+	// Refuse while the username or IP is locked or inside the rejection window of its last failure
+	if err := services.CheckLoginAllowed(username, c.IP()); err != nil {
+		var blocked *services.LoginBlockedError
+		if errors.As(err, &blocked) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int((blocked.RetryAfter+time.Second-1)/time.Second)))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"message": blocked.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "เกิดข้อผิดพลาดในการเข้ารหัส",
+			"message": "ระบบเกิดข้อผิดพลาด",
 		})
 	}
 
-	// Create new admin
-	newAdmin := models.Admin{
-		Username: request.Username,
-		Password: hashedPassword,
-		FullName: request.FullName,
+	// Unknown usernames and wrong passwords get the same response
+	admin, err := services.AuthenticateAdmin(username, request.Password)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		if err := services.RecordLoginFailure(username, c.IP()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "ระบบเกิดข้อผิดพลาด",
+			})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": err.Error(),
+		})
 	}
-
-	// Append to database
-	if err := database.DB.Create(&newAdmin).Error; err != nil {
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "เกิดข้อผิดพลาดในการสร้างผู้ใช้",
+			"message": "ระบบเกิดข้อผิดพลาด",
 		})
 	}
 
-	// Generate token
-	token, err := services.GenerateToken(newAdmin.Id.String())
-	if err != nil {
+	if err := services.ResetLoginFailures(username); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "เกิดข้อผิดพลาดในการสร้างโทเคน",
+			"message": "ระบบเกิดข้อผิดพลาด",
 		})
 	}
 
-	// is production
-	isProd := os.Getenv("ENV") == "production"
-
-	// cookie settings
-	sameSite := fiber.CookieSameSiteNoneMode
-	secure := isProd
-
-	// development settings
-	if !isProd {
-		sameSite = fiber.CookieSameSiteLaxMode
-		secure = false
+	// Open a session
+	tokens, err := services.CreateSession(admin, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ระบบเกิดข้อผิดพลาด",
+		})
 	}
 
-	// Set cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Expires:  time.Now().Add(1 * time.Hour),
-		Path:     "/",
-		Secure:   secure,
-		HTTPOnly: true,
-		SameSite: sameSite,
-	})
+	setSessionCookies(c, tokens)
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "สมัครสมาชิกสำเร็จ",
-		"data":    newAdmin,
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "เข้าสู่ระบบสำเร็จ",
+		"data":    admin,
 	})
 }
 
-func LoginAdmin(c fiber.Ctx) error {
-	var request models.AdminLogin
+func Logout(c fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+	sessionID, _ := uuid.Parse(c.Locals("session_id").(string))
 
-	// Validate data
-	if err := c.Bind().Body(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "กรุณากรอกข้อมูลที่จำเป็น",
+	if err := services.RevokeSession(userID, sessionID); err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ระบบเกิดข้อผิดพลาด",
 		})
 	}
 
-	// Find admin
-	var admin models.Admin
-	if err := database.DB.Where("username = ?", request.Username).First(&admin).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message": "เลขบัตรประชาชนหรือรหัสผ่านไม่ถูกต้อง",
-		})
-	}
+	clearSessionCookies(c)
 
-	// Check password
-	if !services.VerifyPassword(request.Password, admin.Password) {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "เลขบัตรประชาชนหรือรหัสผ่านไม่ถูกต้อง",
-		})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ออกจากระบบสำเร็จ",
+	})
+}
+
+// Refresh Session
+func RefreshSession(c fiber.Ctx) error {
+	refreshToken := c.Cookies(refreshCookieName)
+	if refreshToken == "" {
+		var request struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		_ = c.Bind().Body(&request)
+		refreshToken = request.RefreshToken
 	}
 
-	// Generate token
-	token, err := services.GenerateToken(admin.Id.String())
+	tokens, err := services.RefreshSession(refreshToken, c.Get("User-Agent"), c.IP())
 	if err != nil {
+		if errors.Is(err, services.ErrSessionInvalid) {
+			clearSessionCookies(c)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ระบบเกิดข้อผิดพลาด",
 		})
 	}
 
-	// is production
-	isProd := os.Getenv("ENV") == "production"
+	setSessionCookies(c, tokens)
 
-	// cookie settings
-	sameSite := fiber.CookieSameSiteNoneMode
-	secure := isProd
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ต่ออายุเซสชันสำเร็จ",
+		"data": fiber.Map{
+			"accessToken":      tokens.AccessToken,
+			"accessExpiresAt":  tokens.AccessExpiresAt,
+			"refreshToken":     tokens.RefreshToken,
+			"refreshExpiresAt": tokens.RefreshExpiresAt,
+		},
+	})
+}
 
-	// development settings
-	if !isProd {
-		sameSite = fiber.CookieSameSiteLaxMode
-		secure = false
-	}
+// Get Sessions
+func GetSessions(c fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+	sessionID, _ := uuid.Parse(c.Locals("session_id").(string))
 
-	// Set cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Expires:  time.Now().Add(1 * time.Hour),
-		Path:     "/",
-		Secure:   secure,
-		HTTPOnly: true,
-		SameSite: sameSite,
-	})
+	sessions, err := services.GetSessions(userID, sessionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ระบบเกิดข้อผิดพลาด",
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "เข้าสู่ระบบสำเร็จ",
-		"data":    admin,
+		"message": "ดึงข้อมูลเซสชันสำเร็จ",
+		"data":    sessions,
 	})
 }
 
-func Logout(c fiber.Ctx) error {
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    "",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		HTTPOnly: true,
-		Secure:   os.Getenv("ENV") == "production",
-		SameSite: fiber.CookieSameSiteLaxMode,
-	})
+// Revoke Session
+func RevokeSession(c fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รหัสเซสชันไม่ถูกต้อง",
+		})
+	}
+
+	if err := services.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ระบบเกิดข้อผิดพลาด",
+		})
+	}
+
+	// Revoking the session in use also signs this client out
+	if sessionID.String() == c.Locals("session_id").(string) {
+		clearSessionCookies(c)
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "ออกจากระบบสำเร็จ",
+		"message": "ยกเลิกเซสชันสำเร็จ",
 	})
 }
 
@@ -209,8 +276,17 @@ func GetMe(c fiber.Ctx) error {
 	var user models.Admin
 	database.DB.Where("id = ?", user_id).First(&user)
 
+	permissions, err := services.RolePermissions(user.Role)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ระบบเกิดข้อผิดพลาด",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"user": user,
+		"user":          user,
+		"permissions":   permissions,
+		"cooperativeId": currentCooperativeID(c),
 	})
 }
 
@@ -266,7 +342,13 @@ func UpdateAdminRole(c fiber.Ctx) error {
 		})
 	}
 
-	if request.Role != "ADMIN" && request.Role != "SUPER_ADMIN" {
+	exists, err := services.RoleExists(request.Role)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถอัปเดตสิทธิ์ได้",
+		})
+	}
+	if !exists {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "สิทธิ์ไม่ถูกต้อง",
 		})
@@ -301,11 +383,13 @@ func CreateAdmin(c fiber.Ctx) error {
 		})
 	}
 
-	if len(request.Username) < 13 {
+	username, err := validation.ValidateThaiID(request.Username)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "กรุณากรอกเลขบัตรประชาชนให้ถูกต้อง",
+			"message": err.Error(),
 		})
 	}
+	request.Username = username
 
 	if len(request.Password) < 5 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -348,7 +432,7 @@ func CreateAdmin(c fiber.Ctx) error {
 		Username: request.Username,
 		Password: hashedPassword,
 		FullName: request.FullName,
-		Role:     "ADMIN",
+		Role:     models.RoleAdmin,
 	}
 
 	// Append to database
@@ -358,6 +442,15 @@ func CreateAdmin(c fiber.Ctx) error {
 		})
 	}
 
+	// New admins start in the cooperative of the admin who created them
+	if cooperativeID := currentCooperativeID(c); cooperativeID != "" {
+		if _, err := services.SetAdminCooperatives(newAdmin.Id, []string{cooperativeID}); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "เกิดข้อผิดพลาดในการกำหนดสหกรณ์ของผู้ใช้",
+			})
+		}
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "เพิ่มผู้ใช้งานสำเร็จ",
 		"data":    newAdmin,
@@ -384,3 +477,148 @@ func DeleteAdmin(c fiber.Ctx) error {
 		"message": "ลบผู้ใช้งานสำเร็จ",
 	})
 }
+
+// Sign Out Admin Everywhere
+func SignOutAdmin(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	if err := services.SignOutAdmin(id); err != nil {
+		if errors.Is(err, services.ErrAdminNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถออกจากระบบผู้ใช้งานได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ออกจากระบบผู้ใช้งานทุกอุปกรณ์สำเร็จ",
+	})
+}
+
+// Change Own Password
+func ChangePassword(c fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+
+	var request models.AdminPasswordRequest
+	if err := c.Bind().Body(&request); err != nil || request.CurrentPassword == "" || request.NewPassword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลที่จำเป็น",
+		})
+	}
+
+	if len(request.NewPassword) < 5 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกรหัสผ่านอย่างน้อย 5 ตัวอักษร",
+		})
+	}
+
+	if err := services.ChangeOwnPassword(userID, request.CurrentPassword, request.NewPassword); err != nil {
+		if errors.Is(err, services.ErrWrongPassword) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrAdminNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถเปลี่ยนรหัสผ่านได้",
+		})
+	}
+
+	// Every session, this one included, was revoked with the old password
+	clearSessionCookies(c)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "เปลี่ยนรหัสผ่านสำเร็จ กรุณาเข้าสู่ระบบใหม่",
+	})
+}
+
+// Reset Admin Password
+func ResetAdminPassword(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	var request models.AdminPasswordRequest
+	if err := c.Bind().Body(&request); err != nil || request.NewPassword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลที่จำเป็น",
+		})
+	}
+
+	if len(request.NewPassword) < 5 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกรหัสผ่านอย่างน้อย 5 ตัวอักษร",
+		})
+	}
+
+	if err := services.UpdateAdminPassword(id, request.NewPassword); err != nil {
+		if errors.Is(err, services.ErrAdminNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถรีเซ็ตรหัสผ่านได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "รีเซ็ตรหัสผ่านสำเร็จ",
+	})
+}
+
+// Get usernames and IPs that are locked out of sign-in
+func GetLoginLockouts(c fiber.Ctx) error {
+	lockouts, err := services.GetLoginLockouts()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงข้อมูลการระงับการเข้าสู่ระบบได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลสำเร็จ",
+		"data":    lockouts,
+	})
+}
+
+// Lift a sign-in lockout before it expires
+func UnlockLogin(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+
+	if err := services.UnlockLogin(id, userID); err != nil {
+		if errors.Is(err, services.ErrLoginLockNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถปลดการระงับการเข้าสู่ระบบได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ปลดการระงับการเข้าสู่ระบบสำเร็จ",
+	})
+}
@@ -1,7 +1,12 @@
 package controllers
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
 	"github.com/gofiber/fiber/v3"
@@ -52,7 +57,19 @@ func GetCareerCategories(c fiber.Ctx) error {
 	categoryName := c.Query("categoryName")
 	searchQuery := c.Query("search")
 
-	categories, err := services.GetCareerCategories(categoryName, searchQuery)
+	// อัตรากำไรที่มีผล ณ วันที่ รูปแบบ YYYY-MM-DD (ค่าเริ่มต้นคือวันนี้)
+	asOf := time.Now()
+	if asOfStr := c.Query("asOf"); asOfStr != "" {
+		parsed, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่ไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		asOf = parsed
+	}
+
+	categories, err := services.GetCareerCategories(categoryName, searchQuery, asOf)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถดึงข้อมูลหมวดหมู่อาชีพได้",
@@ -124,18 +141,69 @@ func DeleteCareerCategory(c fiber.Ctx) error {
 	})
 }
 
+func GetCareerCategoryUsage(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	usage, err := services.GetCareerCategoryUsage(currentCooperativeID(c), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลการใช้งานหมวดหมู่อาชีพสำเร็จ",
+		"data":    usage,
+	})
+}
+
 // SubCategory Controllers
 
 type CreateSubCategoryRequest struct {
 	CategoryID      uuid.UUID `json:"categoryId"`
 	SubCategoryName string    `json:"subCategoryName"`
 	SubNetProfit    float64   `json:"subNetProfit"`
+	ValidFrom       string    `json:"validFrom"` // YYYY-MM-DD, empty = today
+	SourceRef       string    `json:"sourceRef"`
 }
 
 type UpdateSubCategoryRequest struct {
 	CategoryID      uuid.UUID `json:"categoryId"`
 	SubCategoryName string    `json:"subCategoryName"`
 	SubNetProfit    float64   `json:"subNetProfit"`
+	ValidFrom       string    `json:"validFrom"` // YYYY-MM-DD, empty = today; only used when the margin changes
+	SourceRef       string    `json:"sourceRef"`
+}
+
+type CreateSubCategoryMarginRequest struct {
+	NetProfit float64 `json:"netProfit"`
+	ValidFrom string  `json:"validFrom"` // YYYY-MM-DD, empty = today
+	SourceRef string  `json:"sourceRef"`
+}
+
+// marginVersionInput validates a margin and the date it takes effect
+func marginVersionInput(netProfit float64, validFrom string, sourceRef string) (services.MarginVersionInput, error) {
+	input := services.MarginVersionInput{NetProfit: netProfit, SourceRef: sourceRef}
+
+	if netProfit < 0 || netProfit > 100 {
+		return input, errors.New("อัตรากำไรสุทธิต้องอยู่ระหว่าง 0-100%")
+	}
+
+	if validFrom != "" {
+		parsed, err := time.Parse("2006-01-02", validFrom)
+		if err != nil {
+			return input, errors.New("รูปแบบวันที่มีผลไม่ถูกต้อง (YYYY-MM-DD)")
+		}
+		input.ValidFrom = parsed
+	}
+
+	return input, nil
 }
 
 func CreateSubCategory(c fiber.Ctx) error {
@@ -154,8 +222,15 @@ func CreateSubCategory(c fiber.Ctx) error {
 		})
 	}
 
+	margin, err := marginVersionInput(request.SubNetProfit, request.ValidFrom, request.SourceRef)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
 	// Create subcategory
-	subCategory, err := services.CreateSubCategory(request.CategoryID, request.SubCategoryName, request.SubNetProfit)
+	subCategory, err := services.CreateSubCategory(request.CategoryID, request.SubCategoryName, margin)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": err.Error(),
@@ -238,8 +313,15 @@ func UpdateSubCategory(c fiber.Ctx) error {
 		})
 	}
 
+	margin, err := marginVersionInput(request.SubNetProfit, request.ValidFrom, request.SourceRef)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
 	// Update subcategory
-	subCategory, err := services.UpdateSubCategory(id, request.CategoryID, request.SubCategoryName, request.SubNetProfit)
+	subCategory, err := services.UpdateSubCategory(id, request.CategoryID, request.SubCategoryName, margin)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": err.Error(),
@@ -274,6 +356,182 @@ func DeleteSubCategory(c fiber.Ctx) error {
 	})
 }
 
+func GetSubCategoryMargins(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	margins, err := services.GetSubCategoryMargins(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงประวัติอัตรากำไรสำเร็จ",
+		"data":    margins,
+	})
+}
+
+func CreateSubCategoryMargin(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	var request CreateSubCategoryMarginRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	input, err := marginVersionInput(request.NetProfit, request.ValidFrom, request.SourceRef)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	margin, err := services.AddSubCategoryMargin(id, input)
+	if err != nil {
+		if errors.Is(err, services.ErrMarginVersionInUse) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "บันทึกอัตรากำไรสำเร็จ",
+		"data":    margin,
+	})
+}
+
+// ExportCareerTable downloads the whole category → sub-category → margin table
+func ExportCareerTable(c fiber.Ctx) error {
+	// อัตรากำไรที่มีผล ณ วันที่ รูปแบบ YYYY-MM-DD (ค่าเริ่มต้นคือวันนี้)
+	asOf := time.Now()
+	if asOfStr := c.Query("asOf"); asOfStr != "" {
+		parsed, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่ไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		asOf = parsed
+	}
+
+	var write func(w io.Writer, asOf time.Time) error
+	filename := "careers-" + asOf.Format("20060102")
+
+	switch c.Query("format", "xlsx") {
+	case "csv":
+		write = services.WriteCareerTableCSV
+		c.Set("Content-Type", "text/csv; charset=utf-8")
+		filename += ".csv"
+	case "xlsx":
+		write = services.WriteCareerTableXLSX
+		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		filename += ".xlsx"
+	case "json":
+		write = services.WriteCareerTableJSON
+		c.Set("Content-Type", "application/json; charset=utf-8")
+		filename += ".json"
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("Unsupported format")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if err := write(w, asOf); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	})
+}
+
+// ImportCareerTable replaces the career table from a CSV/XLSX/JSON file, or only reports the diff on dryRun
+func ImportCareerTable(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณาแนบไฟล์ตารางอาชีพ (.csv .xlsx หรือ .json)",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "ไม่สามารถเปิดไฟล์ที่แนบมาได้",
+		})
+	}
+	defer file.Close()
+
+	// dryRun=true แสดงรายการที่เพิ่ม/เปลี่ยน/ลบ โดยไม่บันทึกลงฐานข้อมูล
+	// removeMissing=true ลบอาชีพที่ไม่มีในไฟล์ออกด้วย
+	options := services.CareerImportOptions{
+		DryRun:        c.Query("dryRun", c.FormValue("dryRun")) == "true",
+		RemoveMissing: c.Query("removeMissing", c.FormValue("removeMissing")) == "true",
+	}
+
+	// วันที่มีผลของแถวที่ไม่ได้ระบุ validFrom รูปแบบ YYYY-MM-DD (ค่าเริ่มต้นคือวันนี้)
+	if effectiveDate := c.Query("effectiveDate", c.FormValue("effectiveDate")); effectiveDate != "" {
+		parsed, err := time.Parse("2006-01-02", effectiveDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่มีผลไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		options.EffectiveDate = parsed
+	}
+
+	report, err := services.ImportCareerTable(fileHeader.Filename, file, options)
+	if err != nil {
+		if errors.Is(err, services.ErrCareerImportHasErrors) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+				"data":    report,
+			})
+		}
+		if errors.Is(err, services.ErrCareerImportFailed) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": services.ErrCareerImportFailed.Error(),
+				"error":   err.Error(),
+			})
+		}
+		// Unreadable file, unsupported format or missing columns
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	message := "นำเข้าตารางอาชีพสำเร็จ"
+	if options.DryRun {
+		message = "ตรวจสอบไฟล์ตารางอาชีพสำเร็จ"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    report,
+	})
+}
+
 // SeedCareerCategories seeds the pre-defined categories and subcategories into the database
 func SeedCareerCategories(c fiber.Ctx) error {
 	if err := services.SeedCareerCategoriesData(); err != nil {
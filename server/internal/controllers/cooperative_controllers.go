@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// Cooperative Controllers
+
+var cooperativeIDPattern = regexp.MustCompile(`^\d{13}$`)
+
+// currentCooperativeID is the cooperative the caller's session is working in, set by AuthMiddleware
+func currentCooperativeID(c fiber.Ctx) string {
+	cooperativeID, _ := c.Locals("cooperative_id").(string)
+	return cooperativeID
+}
+
+// currentAdminID is the signed-in admin, set by AuthMiddleware; uuid.Nil matches nobody
+func currentAdminID(c fiber.Ctx) uuid.UUID {
+	userID, _ := c.Locals("user_id").(string)
+	id, _ := uuid.Parse(userID)
+	return id
+}
+
+// cooperativeErrorStatus maps cooperative service errors to HTTP statuses
+func cooperativeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCooperativeNotFound), errors.Is(err, services.ErrAdminNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrCooperativeExists):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrCooperativeForbidden):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrSessionInvalid):
+		return fiber.StatusUnauthorized
+	}
+	return fiber.StatusInternalServerError
+}
+
+// Get the cooperatives the caller may work in, and the current one
+func GetCooperatives(c fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+
+	cooperatives, err := services.GetAdminCooperatives(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงข้อมูลสหกรณ์ได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลสหกรณ์สำเร็จ",
+		"data":    cooperatives,
+		"current": currentCooperativeID(c),
+	})
+}
+
+func CreateCooperative(c fiber.Ctx) error {
+	var request models.CooperativeRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	if !cooperativeIDPattern.MatchString(request.Id) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "เลขทะเบียนสหกรณ์ต้องมี 13 หลัก",
+		})
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกชื่อสหกรณ์",
+		})
+	}
+
+	cooperative, err := services.CreateCooperative(&request)
+	if err != nil {
+		return c.Status(cooperativeErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "สร้างสหกรณ์สำเร็จ",
+		"data":    cooperative,
+	})
+}
+
+func UpdateCooperative(c fiber.Ctx) error {
+	var request models.CooperativeRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	if strings.TrimSpace(request.Name) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกชื่อสหกรณ์",
+		})
+	}
+
+	cooperative, err := services.UpdateCooperative(c.Params("id"), &request)
+	if err != nil {
+		return c.Status(cooperativeErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "อัพเดทสหกรณ์สำเร็จ",
+		"data":    cooperative,
+	})
+}
+
+// Switch the caller's session to another cooperative
+func SwitchCooperative(c fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+	sessionID, _ := uuid.Parse(c.Locals("session_id").(string))
+
+	var request models.SwitchCooperativeRequest
+	if err := c.Bind().Body(&request); err != nil || request.CooperativeID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณาเลือกสหกรณ์",
+		})
+	}
+
+	cooperative, err := services.SwitchCooperative(userID, sessionID, request.CooperativeID)
+	if err != nil {
+		return c.Status(cooperativeErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "เปลี่ยนสหกรณ์สำเร็จ",
+		"data":    cooperative,
+	})
+}
+
+// Assign an admin to cooperatives
+func SetAdminCooperatives(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	var request models.AdminCooperativesRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	admin, err := services.SetAdminCooperatives(id, request.CooperativeIDs)
+	if err != nil {
+		return c.Status(cooperativeErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "กำหนดสหกรณ์ของผู้ใช้งานสำเร็จ",
+		"data":    admin,
+	})
+}
@@ -7,6 +7,17 @@ import (
 	"github.com/gofiber/fiber/v3"
 )
 
+// GetDashboardSummary returns the headline figures of the session's cooperative
+func GetDashboardSummary(c fiber.Ctx) error {
+	data, err := services.GetDashboardSummary(currentCooperativeID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get dashboard summary",
+		})
+	}
+	return c.JSON(data)
+}
+
 func GetDashboardOverview(c fiber.Ctx) error {
 	// Get query parameters
 	rawAccountYear := c.Query("accountYear") // ex. 2568
@@ -34,7 +45,7 @@ func GetDashboardOverview(c fiber.Ctx) error {
 	}
 
 	// Get KPI data (with filters)
-	kpiData, err := services.GetKPIDashboard(accountYear, subdistrict)
+	kpiData, err := services.GetKPIDashboard(currentCooperativeID(c), accountYear, subdistrict)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get KPI dashboard",
@@ -42,7 +53,7 @@ func GetDashboardOverview(c fiber.Ctx) error {
 	}
 
 	// Get membership growth data (no filters as per requirements)
-	growthData, err := services.GetMembershipGrowthChart()
+	growthData, err := services.GetMembershipGrowthChart(currentCooperativeID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get membership growth data",
@@ -50,7 +61,7 @@ func GetDashboardOverview(c fiber.Ctx) error {
 	}
 
 	// Get member count by subdistrict data (with filters)
-	subdistrictData, err := services.GetMembershipCountBySubdistrictChart(accountYear)
+	subdistrictData, err := services.GetMembershipCountBySubdistrictChart(currentCooperativeID(c), accountYear)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get subdistrict data",
@@ -58,7 +69,7 @@ func GetDashboardOverview(c fiber.Ctx) error {
 	}
 
 	// Get shares distribution data (with filters)
-	sharesDistributionData, err := services.GetSharesDistributionChart(accountYear, subdistrict)
+	sharesDistributionData, err := services.GetSharesDistributionChart(currentCooperativeID(c), accountYear, subdistrict)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get shares distribution data",
@@ -83,7 +94,7 @@ func GetDashboardOverview(c fiber.Ctx) error {
 // For dropdown
 
 func GetFullDropdown(c fiber.Ctx) error {
-	data, err := services.GetFullDropdown()
+	data, err := services.GetFullDropdown(currentCooperativeID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get full dropdown",
@@ -93,7 +104,7 @@ func GetFullDropdown(c fiber.Ctx) error {
 }
 
 func GetSubDistricts(c fiber.Ctx) error {
-	data, err := services.GetSubDistricts()
+	data, err := services.GetSubDistricts(currentCooperativeID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get subdistricts",
@@ -103,7 +114,7 @@ func GetSubDistricts(c fiber.Ctx) error {
 }
 
 func GetDistricts(c fiber.Ctx) error {
-	data, err := services.GetDistricts()
+	data, err := services.GetDistricts(currentCooperativeID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get districts",
@@ -113,7 +124,7 @@ func GetDistricts(c fiber.Ctx) error {
 }
 
 func GetProvinces(c fiber.Ctx) error {
-	data, err := services.GetProvinces()
+	data, err := services.GetProvinces(currentCooperativeID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get provinces",
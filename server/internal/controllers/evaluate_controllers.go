@@ -1,15 +1,85 @@
 package controllers
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/validation"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 )
 
+// validateEvaluateApplicants checks every applicant's name and ID card, storing the ID cards normalized
+func validateEvaluateApplicants(request *models.EvaluateRequest) error {
+	for i := range request.Applicants {
+		applicant := &request.Applicants[i]
+		if applicant.Name == "" || applicant.IDCard == "" {
+			return errors.New("กรุณากรอกข้อมูลให้ครบถ้วน")
+		}
+
+		idCard, err := validation.ValidateThaiID(applicant.IDCard)
+		if err != nil {
+			return err
+		}
+		applicant.IDCard = idCard
+	}
+
+	for i := range request.Result.Applicants {
+		applicant := &request.Result.Applicants[i]
+		if applicant.IDCard == "" {
+			continue
+		}
+
+		idCard, err := validation.ValidateThaiID(applicant.IDCard)
+		if err != nil {
+			return err
+		}
+		applicant.IDCard = idCard
+	}
+
+	return nil
+}
+
+// validateLoanTerms checks the optional loan terms, an empty method defaults to the reducing balance
+func validateLoanTerms(terms *models.LoanTerms) error {
+	if terms.Principal == 0 {
+		return nil
+	}
+
+	if terms.Principal < 0 {
+		return errors.New("เงื่อนไขเงินกู้ต้องไม่ติดลบ")
+	}
+
+	return validateRepaymentTerms(terms)
+}
+
+// validateRepaymentTerms checks the rate, term and method of the loan terms
+func validateRepaymentTerms(terms *models.LoanTerms) error {
+	if terms.AnnualRate < 0 {
+		return errors.New("เงื่อนไขเงินกู้ต้องไม่ติดลบ")
+	}
+
+	if terms.TermMonths <= 0 {
+		return errors.New("กรุณาระบุระยะเวลากู้ (เดือน)")
+	}
+
+	switch terms.Method {
+	case "":
+		terms.Method = models.LoanMethodReducing
+	case models.LoanMethodFlat, models.LoanMethodReducing, models.LoanMethodEqualPrincipal:
+	default:
+		return errors.New("วิธีการผ่อนชำระไม่ถูกต้อง")
+	}
+
+	return nil
+}
+
 func CreateEvaluate(c fiber.Ctx) error {
 	idParam := c.Locals("user_id").(string)
 	user_id, err := uuid.Parse(idParam)
@@ -34,23 +104,21 @@ func CreateEvaluate(c fiber.Ctx) error {
 		})
 	}
 
-	// validate name and IcCard
-	for _, applicant := range request.Applicants {
-		if applicant.Name == "" || applicant.IDCard == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
-			})
-		}
+	// validate name and IDCard
+	if err := validateEvaluateApplicants(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 
-		if len(applicant.IDCard) != 13 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"message": "เลขบัตรประชาชนไม่ถูกต้อง",
-			})
-		}
+	if err := validateLoanTerms(&request.LoanTerms); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
 	}
 
 	// Create evaluate
-	evaluate, err := services.CreateEvaluate(user_id, &request)
+	evaluate, err := services.CreateEvaluate(currentCooperativeID(c), user_id, &request)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "เกิดข้อผิดพลาดในการสร้างการประเมิน",
@@ -63,6 +131,102 @@ func CreateEvaluate(c fiber.Ctx) error {
 	})
 }
 
+// PreviewEvaluate returns the computed result for a what-if evaluation without saving it.
+func PreviewEvaluate(c fiber.Ctx) error {
+	var request models.EvaluateRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	// validate required fields
+	if request.EvaluateType == "" || request.MarginType == "" || len(request.Applicants) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	// validate name and IDCard
+	if err := validateEvaluateApplicants(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if err := validateLoanTerms(&request.LoanTerms); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	result, err := services.PreviewEvaluate(currentCooperativeID(c), &request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถคำนวณผลการประเมินได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "คำนวณผลการประเมินสำเร็จ",
+		"data":    result,
+	})
+}
+
+func MaxLoan(c fiber.Ctx) error {
+	var request models.MaxLoanRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	// validate required fields
+	if request.EvaluateType == "" || request.MarginType == "" || len(request.Applicants) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	// validate name and IDCard
+	if err := validateEvaluateApplicants(&request.EvaluateRequest); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if request.MaxDti < 0 || request.MinDscr < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "เกณฑ์การอนุมัติต้องไม่ติดลบ",
+		})
+	}
+
+	// The principal is what we solve for
+	request.LoanTerms.Principal = 0
+	if err := validateRepaymentTerms(&request.LoanTerms); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	result, err := services.CalculateMaxLoan(currentCooperativeID(c), &request)
+	if err != nil {
+		if errors.Is(err, services.ErrNoLoanLimit) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถคำนวณวงเงินกู้สูงสุดได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "คำนวณวงเงินกู้สูงสุดสำเร็จ",
+		"data":    result,
+	})
+}
+
 func GetAllEvaluates(c fiber.Ctx) error {
 	// Get query parameters
 	search := c.Query("search", "")
@@ -91,7 +255,7 @@ func GetAllEvaluates(c fiber.Ctx) error {
 	}
 
 	// Call service — uuid.Nil means no user filter (all evaluates)
-	evaluates, total, err := services.GetEvaluates(search, filterUserID, page, limit)
+	evaluates, total, err := services.GetEvaluates(currentCooperativeID(c), search, filterUserID, page, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถดึงข้อมูลการประเมินได้",
@@ -139,7 +303,7 @@ func GetEvaluates(c fiber.Ctx) error {
 	}
 
 	// Call service
-	evaluates, total, err := services.GetEvaluates(search, userID, page, limit)
+	evaluates, total, err := services.GetEvaluates(currentCooperativeID(c), search, userID, page, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถดึงข้อมูลการประเมินได้",
@@ -170,8 +334,13 @@ func GetEvaluate(c fiber.Ctx) error {
 		})
 	}
 
-	evaluate, err := services.GetEvaluateByID(id)
+	evaluate, err := services.GetEvaluateByID(currentCooperativeID(c), id, currentAdminID(c))
 	if err != nil {
+		if errors.Is(err, services.ErrEvaluateNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถดึงข้อมูลการประเมินได้",
 		})
@@ -215,22 +384,25 @@ func UpdateEvaluate(c fiber.Ctx) error {
 	}
 
 	// validate name and IDCard
-	for _, applicant := range request.Applicants {
-		if applicant.Name == "" || applicant.IDCard == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
-			})
-		}
+	if err := validateEvaluateApplicants(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 
-		if len(applicant.IDCard) != 13 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"message": "เลขบัตรประชาชนไม่ถูกต้อง",
-			})
-		}
+	if err := validateLoanTerms(&request.LoanTerms); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
 	}
 
-	evaluate, err := services.UpdateEvaluate(id, userID, &request)
+	evaluate, err := services.UpdateEvaluate(currentCooperativeID(c), id, userID, &request)
 	if err != nil {
+		if errors.Is(err, services.ErrEvaluateLocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถอัปเดตข้อมูลการประเมินได้",
 		})
@@ -259,8 +431,13 @@ func DeleteEvaluate(c fiber.Ctx) error {
 		})
 	}
 
-	err = services.DeleteEvaluate(id, userID)
+	err = services.DeleteEvaluate(currentCooperativeID(c), id, userID)
 	if err != nil {
+		if errors.Is(err, services.ErrEvaluateLocked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถลบข้อมูลการประเมินได้",
 		})
@@ -280,25 +457,39 @@ func UpdateEvaluateStatus(c fiber.Ctx) error {
 		})
 	}
 
-	var body struct {
-		Status   string `json:"status"`
-		Feedback string `json:"feedback"`
+	userIDStr := c.Locals("user_id").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ user ID ไม่ถูกต้อง",
+		})
 	}
+
+	var body models.EvaluateStatusRequest
 	if err := c.Bind().Body(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "ข้อมูลไม่ถูกต้อง",
 		})
 	}
 
-	validStatuses := map[string]bool{"รอการอนุมัติ": true, "อนุมัติ": true, "ไม่อนุมัติ": true}
-	if !validStatuses[body.Status] {
+	if !services.IsValidEvaluateStatus(body.Status) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "สถานะไม่ถูกต้อง",
 		})
 	}
 
-	evaluate, err := services.UpdateEvaluateStatus(id, body.Status, body.Feedback)
+	evaluate, err := services.TransitionEvaluateStatus(currentCooperativeID(c), id, userID, body.Status, body.Feedback)
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTransition):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		case errors.Is(err, services.ErrTransitionForbidden), errors.Is(err, services.ErrSelfReview), errors.Is(err, services.ErrReviewerApproval):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถอัปเดตสถานะได้",
 		})
@@ -310,27 +501,323 @@ func UpdateEvaluateStatus(c fiber.Ctx) error {
 	})
 }
 
+func GetEvaluateStatusHistory(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	history, err := services.GetEvaluateStatusHistory(currentCooperativeID(c), id, currentAdminID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงประวัติสถานะได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงประวัติสถานะสำเร็จ",
+		"data":    history,
+	})
+}
+
+func GetEvaluateRevisions(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	revisions, err := services.GetEvaluateRevisions(currentCooperativeID(c), id, currentAdminID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงประวัติการแก้ไขได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงประวัติการแก้ไขสำเร็จ",
+		"data":    revisions,
+	})
+}
+
+func GetEvaluateRevision(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	rev, err := strconv.Atoi(c.Params("rev"))
+	if err != nil || rev < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบเลขที่การแก้ไขไม่ถูกต้อง",
+		})
+	}
+
+	revision, err := services.GetEvaluateRevision(currentCooperativeID(c), id, rev, currentAdminID(c))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงประวัติการแก้ไขสำเร็จ",
+		"data":    revision,
+	})
+}
+
+// DiffEvaluateRevisions compares two revisions (?from=&to=) field by field.
+func DiffEvaluateRevisions(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || from < 1 || to < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณาระบุเลขที่การแก้ไขที่ต้องการเปรียบเทียบ (from, to)",
+		})
+	}
+
+	diff, err := services.DiffEvaluateRevisions(currentCooperativeID(c), id, from, to, currentAdminID(c))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "เปรียบเทียบประวัติการแก้ไขสำเร็จ",
+		"data":    diff,
+	})
+}
+
 // ExportEvaluate returns a PDF file be printed or saved as PDF by the browser.
 func ExportEvaluate(c fiber.Ctx) error {
 	idParam := c.Params("id")
-    id, err := uuid.Parse(idParam)
-    if err != nil {
-        return c.Status(fiber.StatusBadRequest).SendString("Invalid id")
-    }
-
-    evaluate, err := services.GetEvaluateByID(id)
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).SendString("Cannot fetch evaluate")
-    }
-
-    // 2. เรียกใช้ฟังก์ชันสร้าง HTML
-    htmlBytes, err := services.GenerateEvaluateHTML(evaluate)
-    if err != nil {
-        fmt.Printf("Error: %v\n", err)
-        return c.SendStatus(fiber.StatusInternalServerError)
-    }
-
-    // 3. ส่งข้อมูลกลับเป็น HTML เพื่อให้ Browser สั่ง Save PDF
-    c.Set("Content-Type", "text/html; charset=utf-8")
-    return c.Send(htmlBytes)
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid id")
+	}
+
+	evaluate, err := services.GetEvaluateByID(currentCooperativeID(c), id, currentAdminID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrEvaluateNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString("Evaluate not found")
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString("Cannot fetch evaluate")
+	}
+
+	// mode=detailed แนบรายละเอียดข้อมูลผู้กู้แต่ละคน
+	detailed := c.Query("mode") == "detailed"
+
+	switch c.Query("format", "html") {
+	case "pdf":
+		// สร้างไฟล์ PDF ฝั่งเซิร์ฟเวอร์ (ฝังฟอนต์ภาษาไทย)
+		pdfBytes, err := services.GenerateEvaluatePDF(evaluate, detailed)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			if errors.Is(err, services.ErrExportFontMissing) {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"message": err.Error(),
+				})
+			}
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		c.Set("Content-Type", "application/pdf")
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="evaluate-%s.pdf"`, evaluate.Id))
+		return c.Send(pdfBytes)
+	case "docx":
+		// สร้างไฟล์ Word สำหรับนำไปแก้ไขต่อ
+		docxBytes, err := services.GenerateEvaluateDOCX(evaluate, detailed)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="evaluate-%s.docx"`, evaluate.Id))
+		return c.Send(docxBytes)
+	case "html":
+		// เรียกใช้ฟังก์ชันสร้าง HTML
+		htmlBytes, err := services.GenerateEvaluateHTML(evaluate, detailed)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		// ส่งข้อมูลกลับเป็น HTML เพื่อให้ Browser สั่ง Save PDF
+		c.Set("Content-Type", "text/html; charset=utf-8")
+		return c.Send(htmlBytes)
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("Unsupported format")
+	}
+}
+
+func ExportAllEvaluates(c fiber.Ctx) error {
+	filter := services.EvaluateExportFilter{
+		CooperativeID: currentCooperativeID(c),
+		Search:        c.Query("search", ""),
+		Status:        c.Query("status", ""),
+		// rowPer=applicant แยกผู้กู้แต่ละคนเป็นหนึ่งแถว
+		PerApplicant: c.Query("rowPer", "evaluate") == "applicant",
+	}
+
+	if userIDStr := c.Query("userId", ""); userIDStr != "" {
+		parsed, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "userId ไม่ถูกต้อง",
+			})
+		}
+		filter.UserID = parsed
+	}
+
+	// ช่วงวันที่สร้างแบบประเมิน รูปแบบ YYYY-MM-DD (รวมวันสุดท้าย)
+	if fromStr := c.Query("from", ""); fromStr != "" {
+		from, err := time.ParseInLocation("2006-01-02", fromStr, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่เริ่มต้นไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		filter.From = from
+	}
+	if toStr := c.Query("to", ""); toStr != "" {
+		to, err := time.ParseInLocation("2006-01-02", toStr, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่สิ้นสุดไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	var write func(w io.Writer, filter services.EvaluateExportFilter) error
+	filename := "evaluates-" + time.Now().Format("20060102")
+
+	switch c.Query("format", "xlsx") {
+	case "xlsx":
+		write = services.WriteEvaluatesXLSX
+		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		filename += ".xlsx"
+	case "csv":
+		write = services.WriteEvaluatesCSV
+		c.Set("Content-Type", "text/csv; charset=utf-8")
+		filename += ".csv"
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("Unsupported format")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// ทยอยเขียนข้อมูลทีละชุด ไม่โหลดแบบประเมินทั้งหมดไว้ในหน่วยความจำ
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if err := write(w, filter); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	})
+}
+
+func GetEvaluateSchedule(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	schedule, err := services.GetEvaluateSchedule(currentCooperativeID(c), id, currentAdminID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrNoLoanTerms) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงตารางผ่อนชำระสำเร็จ",
+		"data":    schedule,
+	})
+}
+
+// validateStressShocks checks the configured scenarios; income cuts are percentages of the bucket
+func validateStressShocks(shocks []models.StressShock) error {
+	for _, shock := range shocks {
+		if shock.RateIncrease < 0 || shock.ExpenseRateIncrease < 0 || shock.LivingExpenseFloor < 0 {
+			return errors.New("ค่าปรับเพิ่มในสถานการณ์จำลองต้องไม่ติดลบ")
+		}
+		for _, reduction := range []float64{shock.SalaryReduction, shock.BusinessReduction, shock.OptionsReduction} {
+			if reduction < 0 || reduction > 100 {
+				return errors.New("สัดส่วนรายได้ที่ลดลงต้องอยู่ระหว่าง 0-100%")
+			}
+		}
+	}
+	return nil
+}
+
+func StressEvaluate(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ id ไม่ถูกต้อง",
+		})
+	}
+
+	// An empty body runs the default scenarios
+	var request models.StressRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบข้อมูลสถานการณ์จำลองไม่ถูกต้อง",
+			})
+		}
+	}
+
+	if err := validateStressShocks(request.Scenarios); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	result, err := services.StressEvaluate(currentCooperativeID(c), id, currentAdminID(c), &request)
+	if err != nil {
+		if errors.Is(err, services.ErrStressEvaluateNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถคำนวณสถานการณ์จำลองได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "คำนวณสถานการณ์จำลองสำเร็จ",
+		"data":    result,
+	})
 }
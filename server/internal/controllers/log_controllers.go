@@ -3,6 +3,7 @@ package controllers
 import (
 	"strconv"
 
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
 	"github.com/gofiber/fiber/v3"
 )
@@ -22,7 +23,16 @@ func GetEvaluateLogs(c fiber.Ctx) error {
 		limit = 10
 	}
 
-	logs, total, err := services.GetEvaluateLogs(search, page, limit)
+	// Entries outside any cooperative are for admins who oversee every cooperative
+	admin, _ := c.Locals("current_admin").(models.Admin)
+	includeShared, err := services.AdminCan(&admin, models.PermissionTenantSwitch)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงข้อมูลประวัติย้อนหลังได้",
+		})
+	}
+
+	logs, total, err := services.GetEvaluateLogs(currentCooperativeID(c), includeShared, search, page, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถดึงข้อมูลประวัติย้อนหลังได้",
@@ -1,6 +1,10 @@
 package controllers
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"strconv"
 	"time"
 
@@ -59,69 +63,42 @@ func CreateMember(c fiber.Ctx) error {
 		})
 	}
 
-	//validate required fields
-	if request.IdCard == "" || request.MemberId == "" || request.FullName == "" || request.Nationality == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "กรุณากรอกข้อมูลที่จำเป็นให้ครบถ้วน (เลขบัตรประชาชน, เลขสมาชิก, ชื่อ-นามสกุล, สัญชาติ)",
-		})
-	}
-
-	// Validate cooperativeId
-	if len(request.CooperativeID) != 13 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "เลขทะเบียนสหกรณ์ต้องมี 13 หลัก",
-		})
+	// Members always belong to the cooperative the caller is working in
+	if request.CooperativeID == "" {
+		request.CooperativeID = currentCooperativeID(c)
 	}
-
-	if len(request.IdCard) != 13 {
+	if request.CooperativeID != currentCooperativeID(c) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "เลขบัตรประชาชนไม่ถูกต้อง",
+			"message": services.ErrCooperativeMismatch.Error(),
 		})
 	}
 
-	// Validate accountYear
-	if request.AccountYear != "" {
-		num, err := strconv.Atoi(request.AccountYear)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"message": "รูปแบบปีบัญชีไม่ถูกต้อง (ต้องเป็น YYYY)",
-			})
-		}
-		numAccountYear := num - 543
-		request.AccountYear = strconv.Itoa(numAccountYear)
-	}
-
-	// Parse dates
-	joiningDate, err := time.Parse("2006-01-02", request.JoiningDate)
+	// Validate and convert to member (shared with the registry import)
+	input, err := services.ValidateMemberInput(services.MemberInput(request))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "รูปแบบวันที่เข้าร่วมไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)",
+			"message": err.Error(),
 		})
 	}
 
-	leavingDate, err := time.Parse("2006-01-02", request.LeavingDate)
-	if err != nil {
-		leavingDate = time.Time{} // Use zero time if empty or invalid
-	}
-
 	// Create member
 	member, err := services.CreateMember(
-		request.CooperativeID,
-		request.IdCard,
-		request.AccountYear,
-		request.MemberId,
-		request.FullName,
-		request.Nationality,
-		request.SharesNum,
-		request.SharesValue,
-		joiningDate,
-		request.MemberType,
-		leavingDate,
-		request.Address,
-		request.Moo,
-		request.Subdistrict,
-		request.District,
-		request.Province,
+		input.CooperativeID,
+		input.IdCard,
+		input.AccountYear,
+		input.MemberId,
+		input.FullName,
+		input.Nationality,
+		input.SharesNum,
+		input.SharesValue,
+		input.JoiningDate,
+		input.MemberType,
+		input.LeavingDate,
+		input.Address,
+		input.Moo,
+		input.Subdistrict,
+		input.District,
+		input.Province,
 	)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -165,7 +142,7 @@ func GetMembers(c fiber.Ctx) error {
 
 	// If no filters provided, return all members with pagination
 	if fullName == "" && subdistrict == "" && district == "" && province == "" {
-		members, total, err := services.GetMembersWithPagination(pageNum, limitNum)
+		members, total, err := services.GetMembersWithPagination(currentCooperativeID(c), pageNum, limitNum)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"message": "ไม่สามารถดึงข้อมูลสมาชิกได้",
@@ -185,7 +162,7 @@ func GetMembers(c fiber.Ctx) error {
 	}
 
 	// Apply filters with pagination
-	members, total, err := services.GetMembersWithFiltersAndPagination(fullName, subdistrict, district, province, pageNum, limitNum)
+	members, total, err := services.GetMembersWithFiltersAndPagination(currentCooperativeID(c), fullName, subdistrict, district, province, pageNum, limitNum)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "ไม่สามารถดึงข้อมูลสมาชิกได้",
@@ -213,7 +190,7 @@ func GetMember(c fiber.Ctx) error {
 		})
 	}
 
-	member, err := services.GetMemberByID(id)
+	member, err := services.GetMemberByID(currentCooperativeID(c), id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"message": "ไม่พบข้อมูลสมาชิก",
@@ -230,7 +207,7 @@ func SeedMembers(c fiber.Ctx) error {
 	var err error
 
 	// Use file from filesystem if custom path is provided
-	err = services.SeedMembersFromJSON()
+	err = services.SeedMembersFromJSON(currentCooperativeID(c))
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -260,84 +237,221 @@ func UpdateMember(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if request.IdCard == "" || request.MemberId == "" || request.FullName == "" || request.Nationality == "" {
+	// Members always belong to the cooperative the caller is working in
+	if request.CooperativeID == "" {
+		request.CooperativeID = currentCooperativeID(c)
+	}
+	if request.CooperativeID != currentCooperativeID(c) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "กรุณากรอกข้อมูลที่จำเป็นให้ครบถ้วน (เลขบัตรประชาชน, เลขสมาชิก, ชื่อ-นามสกุล, สัญชาติ)",
+			"message": services.ErrCooperativeMismatch.Error(),
 		})
 	}
 
-	// Validate cooperativeId
-	if len(request.CooperativeID) != 13 {
+	// Validate and convert to member (shared with the registry import)
+	input, err := services.ValidateMemberInput(services.MemberInput(request))
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "เลขทะเบียนสหกรณ์ต้องมี 13 หลัก",
+			"message": err.Error(),
 		})
 	}
 
-	if len(request.IdCard) != 13 {
+	// Update member
+	member, err := services.UpdateMember(
+		id,
+		input.CooperativeID,
+		input.IdCard,
+		input.AccountYear,
+		input.MemberId,
+		input.FullName,
+		input.Nationality,
+		input.SharesNum,
+		input.SharesValue,
+		input.JoiningDate,
+		input.MemberType,
+		input.LeavingDate,
+		input.Address,
+		input.Moo,
+		input.Subdistrict,
+		input.District,
+		input.Province,
+	)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "เลขบัตรประชาชนไม่ถูกต้อง",
+			"message": err.Error(),
 		})
 	}
 
-	// Validate accountYear
-	if request.AccountYear != "" {
-		num, err := strconv.Atoi(request.AccountYear)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"message": "รูปแบบปีบัญชีไม่ถูกต้อง (ต้องเป็น YYYY)",
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "อัพเดทข้อมูลสมาชิกสำเร็จ",
+		"data":    member,
+	})
+}
+
+func DeleteMember(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	// Delete member
+	err = services.DeleteMember(currentCooperativeID(c), id)
+	if err != nil {
+		if err.Error() == "ไม่พบข้อมูลสมาชิก" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
 			})
 		}
-		numAccountYear := num - 543
-		request.AccountYear = strconv.Itoa(numAccountYear)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": err.Error(),
+		})
 	}
 
-	// Parse dates
-	joiningDate, err := time.Parse("2006-01-02", request.JoiningDate)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ลบข้อมูลสมาชิกสำเร็จ",
+	})
+}
+
+func ImportMembers(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "รูปแบบวันที่เข้าร่วมไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)",
+			"message": "กรุณาแนบไฟล์ทะเบียนสมาชิก (.csv หรือ .xlsx)",
 		})
 	}
 
-	leavingDate, err := time.Parse("2006-01-02", request.LeavingDate)
+	file, err := fileHeader.Open()
 	if err != nil {
-		leavingDate = time.Time{} // Use zero time if empty or invalid
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "ไม่สามารถเปิดไฟล์ที่แนบมาได้",
+		})
 	}
+	defer file.Close()
 
-	// Update member
-	member, err := services.UpdateMember(
-		id,
-		request.CooperativeID,
-		request.IdCard,
-		request.AccountYear,
-		request.MemberId,
-		request.FullName,
-		request.Nationality,
-		request.SharesNum,
-		request.SharesValue,
-		joiningDate,
-		request.MemberType,
-		leavingDate,
-		request.Address,
-		request.Moo,
-		request.Subdistrict,
-		request.District,
-		request.Province,
-	)
+	// dryRun=true ตรวจสอบข้อมูลอย่างเดียว ไม่บันทึกลงฐานข้อมูล
+	dryRun := c.Query("dryRun", c.FormValue("dryRun")) == "true"
+
+	report, err := services.ImportMembers(currentCooperativeID(c), fileHeader.Filename, file, dryRun)
 	if err != nil {
+		if errors.Is(err, services.ErrImportHasErrors) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+				"data":    report,
+			})
+		}
+		if errors.Is(err, services.ErrImportFailed) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": services.ErrImportFailed.Error(),
+				"error":   err.Error(),
+			})
+		}
+		// Unreadable file, unsupported format or missing columns
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": err.Error(),
 		})
 	}
 
+	message := "นำเข้าข้อมูลสมาชิกสำเร็จ"
+	if dryRun {
+		message = "ตรวจสอบไฟล์ทะเบียนสมาชิกสำเร็จ"
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "อัพเดทข้อมูลสมาชิกสำเร็จ",
-		"data":    member,
+		"message": message,
+		"data":    report,
 	})
 }
 
-func DeleteMember(c fiber.Ctx) error {
+func ExportMembers(c fiber.Ctx) error {
+	filter := services.MemberFilter{
+		CooperativeID: currentCooperativeID(c),
+		FullName:      util.ValidateAllToEmpty(c.Query("fullName")),
+		Subdistrict:   util.ValidateAllToEmpty(c.Query("subdistrict")),
+		District:      util.ValidateAllToEmpty(c.Query("district")),
+		Province:      util.ValidateAllToEmpty(c.Query("province")),
+	}
+
+	// ปีบัญชีรับเป็น พ.ศ. (เก็บในฐานข้อมูลเป็น ค.ศ.)
+	if accountYear := util.ValidateAllToEmpty(c.Query("accountYear")); accountYear != "" {
+		num, err := strconv.Atoi(accountYear)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบปีบัญชีไม่ถูกต้อง (ต้องเป็น YYYY)",
+			})
+		}
+		if num > 2400 {
+			num -= 543
+		}
+		filter.AccountYear = strconv.Itoa(num)
+	}
+
+	if memberType := util.ValidateAllToEmpty(c.Query("memberType")); memberType != "" {
+		num, err := strconv.ParseInt(memberType, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "ประเภทสมาชิกไม่ถูกต้อง",
+			})
+		}
+		filter.MemberType = num
+	}
+
+	// ช่วงวันที่เข้าเป็นสมาชิก รูปแบบ YYYY-MM-DD (รวมวันสุดท้าย)
+	if joinedFrom := c.Query("joinedFrom"); joinedFrom != "" {
+		from, err := time.Parse("2006-01-02", joinedFrom)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่เริ่มต้นไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		filter.JoinedFrom = from
+	}
+	if joinedTo := c.Query("joinedTo"); joinedTo != "" {
+		to, err := time.Parse("2006-01-02", joinedTo)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "รูปแบบวันที่สิ้นสุดไม่ถูกต้อง (YYYY-MM-DD)",
+			})
+		}
+		filter.JoinedTo = to.AddDate(0, 0, 1)
+	}
+
+	var write func(w io.Writer, filter services.MemberFilter) error
+	filename := "members-" + time.Now().Format("20060102")
+
+	switch c.Query("format", "csv") {
+	case "csv":
+		write = services.WriteMembersCSV
+		c.Set("Content-Type", "text/csv; charset=utf-8")
+		filename += ".csv"
+	case "xlsx":
+		write = services.WriteMembersXLSX
+		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		filename += ".xlsx"
+	case "json":
+		write = services.WriteMembersJSON
+		c.Set("Content-Type", "application/json; charset=utf-8")
+		filename += ".json"
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("Unsupported format")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// ทยอยเขียนข้อมูลทีละชุด ไม่โหลดสมาชิกทั้งหมดไว้ในหน่วยความจำ
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if err := write(w, filter); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	})
+}
+
+func GetMemberEvaluates(c fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
@@ -346,8 +460,7 @@ func DeleteMember(c fiber.Ctx) error {
 		})
 	}
 
-	// Delete member
-	err = services.DeleteMember(id)
+	history, err := services.GetMemberEvaluates(currentCooperativeID(c), id, currentAdminID(c))
 	if err != nil {
 		if err.Error() == "ไม่พบข้อมูลสมาชิก" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -355,11 +468,12 @@ func DeleteMember(c fiber.Ctx) error {
 			})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
+			"message": "ไม่สามารถดึงข้อมูลการประเมินของสมาชิกได้",
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "ลบข้อมูลสมาชิกสำเร็จ",
+		"message": "ดึงข้อมูลการประเมินของสมาชิกสำเร็จ",
+		"data":    history,
 	})
 }
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// EvaluatePolicy Controllers
+
+func validatePolicyRequest(request *models.EvaluatePolicyRequest) string {
+	if request.EvaluateType == "" || request.MarginType == "" {
+		return "กรุณากรอกข้อมูลให้ครบถ้วน"
+	}
+
+	if request.MaxDti < 0 || request.MinDscr < 0 || request.MinNetIncome < 0 || request.MaxCoBorrowers < 0 || request.ShareMultiple < 0 {
+		return "เกณฑ์การอนุมัติต้องไม่ติดลบ"
+	}
+
+	return ""
+}
+
+func CreatePolicy(c fiber.Ctx) error {
+	var request models.EvaluatePolicyRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	if message := validatePolicyRequest(&request); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": message,
+		})
+	}
+
+	policy, err := services.CreatePolicy(currentCooperativeID(c), &request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "สร้างเกณฑ์การอนุมัติสำเร็จ",
+		"data":    policy,
+	})
+}
+
+func GetPolicies(c fiber.Ctx) error {
+	policies, err := services.GetPolicies(currentCooperativeID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงข้อมูลเกณฑ์การอนุมัติได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลเกณฑ์การอนุมัติสำเร็จ",
+		"data":    policies,
+	})
+}
+
+func GetPolicy(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	policy, err := services.GetPolicyByID(currentCooperativeID(c), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลเกณฑ์การอนุมัติสำเร็จ",
+		"data":    policy,
+	})
+}
+
+func UpdatePolicy(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	var request models.EvaluatePolicyRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	if message := validatePolicyRequest(&request); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": message,
+		})
+	}
+
+	policy, err := services.UpdatePolicy(currentCooperativeID(c), id, &request)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "อัพเดทเกณฑ์การอนุมัติสำเร็จ",
+		"data":    policy,
+	})
+}
+
+func DeletePolicy(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	if err := services.DeletePolicy(currentCooperativeID(c), id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ลบเกณฑ์การอนุมัติสำเร็จ",
+	})
+}
@@ -5,7 +5,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 )
 
-// GetPublicKPI returns summary stats for the public landing page (no auth).
+// GetPublicKPI returns platform-wide stats for the public landing page (no auth).
 func GetPublicKPI(c fiber.Ctx) error {
 	data, err := services.GetPublicKPI()
 	if err != nil {
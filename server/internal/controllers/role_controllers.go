@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// Role Controllers
+
+func validateRoleRequest(request *models.RoleRequest) string {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return "กรุณากรอกชื่อบทบาท"
+	}
+
+	if len(name) > 20 || strings.ContainsAny(name, " \t") {
+		return "ชื่อบทบาทต้องไม่เกิน 20 ตัวอักษรและไม่มีช่องว่าง"
+	}
+
+	return ""
+}
+
+// roleErrorStatus maps role service errors to HTTP statuses
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrRoleInUse),
+		errors.Is(err, services.ErrRoleSystem), errors.Is(err, services.ErrRoleLockout):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrUnknownPermission):
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+func GetPermissions(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลสิทธิ์สำเร็จ",
+		"data":    models.Permissions,
+	})
+}
+
+func CreateRole(c fiber.Ctx) error {
+	var request models.RoleRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	if message := validateRoleRequest(&request); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": message,
+		})
+	}
+
+	role, err := services.CreateRole(&request)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "สร้างบทบาทสำเร็จ",
+		"data":    role,
+	})
+}
+
+func GetRoles(c fiber.Ctx) error {
+	roles, err := services.GetRoles()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "ไม่สามารถดึงข้อมูลบทบาทได้",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลบทบาทสำเร็จ",
+		"data":    roles,
+	})
+}
+
+func GetRole(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	role, err := services.GetRoleByID(id)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ดึงข้อมูลบทบาทสำเร็จ",
+		"data":    role,
+	})
+}
+
+func UpdateRole(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	var request models.RoleRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "กรุณากรอกข้อมูลให้ครบถ้วน",
+		})
+	}
+
+	if message := validateRoleRequest(&request); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": message,
+		})
+	}
+
+	role, err := services.UpdateRole(id, &request)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "อัพเดทบทบาทสำเร็จ",
+		"data":    role,
+	})
+}
+
+func DeleteRole(c fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "รูปแบบ ID ไม่ถูกต้อง",
+		})
+	}
+
+	if err := services.DeleteRole(id); err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ลบบทบาทสำเร็จ",
+	})
+}
@@ -0,0 +1,98 @@
+package database
+
+import "log"
+
+// BackfillApplicantCareers links applicants saved before career references existed to the catalogue
+// by category and career name. Rows that are already linked or match nothing are left alone.
+func BackfillApplicantCareers() {
+	if err := DB.Exec(`UPDATE applicants SET career_category_id = career_categories.id
+		FROM career_categories
+		WHERE applicants.career_category_id IS NULL
+		AND REPLACE(career_categories.category_name, ' ', '') = REPLACE(applicants.career_category, ' ', '')`).Error; err != nil {
+		log.Printf("Failed to backfill applicant career categories: %v", err)
+	}
+
+	if err := DB.Exec(`UPDATE applicants SET sub_category_id = sub_categories.id
+		FROM sub_categories
+		WHERE applicants.sub_category_id IS NULL
+		AND sub_categories.category_id = applicants.career_category_id
+		AND REPLACE(sub_categories.sub_category_name, ' ', '') = REPLACE(applicants.career, ' ', '')`).Error; err != nil {
+		log.Printf("Failed to backfill applicant sub-categories: %v", err)
+	}
+}
+
+// BackfillCooperatives creates a cooperative for every registration number already on the member
+// registry and moves existing evaluates, logs and admins into it. A single-cooperative deployment
+// ends up with all of its data and admins in that one cooperative.
+func BackfillCooperatives() {
+	if err := DB.Exec(`INSERT INTO cooperatives (id, name, created_at, updated_at)
+		SELECT DISTINCT cooperative_id, cooperative_id, NOW(), NOW() FROM members
+		WHERE cooperative_id <> ''
+		ON CONFLICT (id) DO NOTHING`).Error; err != nil {
+		log.Printf("Failed to backfill cooperatives: %v", err)
+		return
+	}
+
+	// Evaluates take the cooperative of the members applying on them
+	if err := DB.Exec(`UPDATE evaluates SET cooperative_id = members.cooperative_id
+		FROM applicants, members
+		WHERE evaluates.cooperative_id = ''
+		AND applicants.evaluate_id = evaluates.id
+		AND members.id = applicants.member_id`).Error; err != nil {
+		log.Printf("Failed to backfill evaluate cooperatives: %v", err)
+	}
+
+	var count int64
+	if err := DB.Table("cooperatives").Count(&count).Error; err != nil || count != 1 {
+		return
+	}
+
+	// Only one cooperative: everything unassigned belongs to it
+	if err := DB.Exec(`UPDATE evaluates SET cooperative_id = (SELECT id FROM cooperatives)
+		WHERE cooperative_id = ''`).Error; err != nil {
+		log.Printf("Failed to backfill evaluate cooperatives: %v", err)
+	}
+	if err := DB.Exec(`UPDATE evaluate_logs SET cooperative_id = (SELECT id FROM cooperatives)
+		WHERE cooperative_id = ''`).Error; err != nil {
+		log.Printf("Failed to backfill evaluate log cooperatives: %v", err)
+	}
+	if err := DB.Exec(`INSERT INTO admin_cooperatives (admin_id, cooperative_id)
+		SELECT admins.id, cooperatives.id FROM admins, cooperatives
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		log.Printf("Failed to backfill admin cooperatives: %v", err)
+	}
+}
+
+// BackfillPerCooperativeData drops the registry-wide unique indexes on member ID cards and numbers,
+// which are now unique per cooperative, and gives every cooperative its own copy of the approval
+// policies that were shared before.
+func BackfillPerCooperativeData() {
+	for _, index := range []string{"idx_members_id_card", "idx_members_member_id", "idx_evaluate_policies_type"} {
+		if err := DB.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			log.Printf("Failed to drop %s: %v", index, err)
+		}
+	}
+
+	// A single cooperative keeps the policies as they are, so evaluates still point at the policy
+	// they were checked against
+	if err := DB.Exec(`UPDATE evaluate_policies SET cooperative_id = (SELECT id FROM cooperatives)
+		WHERE cooperative_id = '' AND (SELECT COUNT(*) FROM cooperatives) = 1`).Error; err != nil {
+		log.Printf("Failed to backfill approval policy cooperatives: %v", err)
+		return
+	}
+
+	if err := DB.Exec(`INSERT INTO evaluate_policies (cooperative_id, evaluate_type, margin_type, max_dti, min_dscr,
+			min_net_income, max_co_borrowers, share_multiple, created_at, updated_at)
+		SELECT cooperatives.id, p.evaluate_type, p.margin_type, p.max_dti, p.min_dscr,
+			p.min_net_income, p.max_co_borrowers, p.share_multiple, p.created_at, NOW()
+		FROM evaluate_policies p, cooperatives
+		WHERE p.cooperative_id = ''
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		log.Printf("Failed to copy approval policies to cooperatives: %v", err)
+		return
+	}
+	if err := DB.Exec(`DELETE FROM evaluate_policies WHERE cooperative_id = ''
+		AND EXISTS (SELECT 1 FROM cooperatives)`).Error; err != nil {
+		log.Printf("Failed to remove shared approval policies: %v", err)
+	}
+}
@@ -48,15 +48,27 @@ func Connect() {
 	env := os.Getenv("ENV")
 	if env != "production" {
 		log.Println("Running database migrations...")
+		db.AutoMigrate(&models.Cooperative{})
 		db.AutoMigrate(&models.Admin{})
 		db.AutoMigrate(&models.CareerCategory{})
 		db.AutoMigrate(&models.SubCategory{})
+		db.AutoMigrate(&models.SubCategoryMargin{})
 		db.AutoMigrate(&models.Member{})
 		db.AutoMigrate(&models.Evaluate{})
 		db.AutoMigrate(&models.Applicant{})
 		db.AutoMigrate(&models.EvaluateResult{})
 		db.AutoMigrate(&models.ResultApplicant{})
 		db.AutoMigrate(&models.EvaluateLog{})
+		db.AutoMigrate(&models.EvaluatePolicy{})
+		db.AutoMigrate(&models.EvaluateStatusHistory{})
+		db.AutoMigrate(&models.EvaluateRevision{})
+		db.AutoMigrate(&models.Session{})
+		db.AutoMigrate(&models.LoginThrottle{})
+		db.AutoMigrate(&models.Role{})
+		BackfillApplicantCareers()
+		BackfillCooperatives()
+		BackfillPerCooperativeData()
+		SeedRoles()
 		log.Println("Database migrations completed")
 	} else {
 		log.Println("Production mode: Skipping auto-migrations")
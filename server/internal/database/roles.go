@@ -0,0 +1,43 @@
+package database
+
+import (
+	"log"
+	"slices"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// SeedRoles creates the built-in roles that do not exist yet and adds the default permissions a
+// built-in role is missing. Custom roles are left as they are.
+func SeedRoles() {
+	for _, builtIn := range models.DefaultRoles {
+		role := builtIn
+		if err := DB.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+			Create(&role).Error; err != nil {
+			log.Printf("Failed to seed role %s: %v", builtIn.Name, err)
+			continue
+		}
+
+		var stored models.Role
+		if err := DB.Where("name = ?", builtIn.Name).First(&stored).Error; err != nil {
+			log.Printf("Failed to load role %s: %v", builtIn.Name, err)
+			continue
+		}
+
+		permissions := slices.Clone(stored.Permissions)
+		for _, permission := range builtIn.Permissions {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+		if len(permissions) == len(stored.Permissions) {
+			continue
+		}
+
+		if err := DB.Model(&models.Role{}).Where("id = ?", stored.Id).Select("permissions").
+			Updates(&models.Role{Permissions: permissions}).Error; err != nil {
+			log.Printf("Failed to update role %s: %v", builtIn.Name, err)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package middlewares
 
 import (
-	"os"
+	"strings"
 
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
 	"github.com/gofiber/fiber/v3"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() fiber.Handler {
+// AuthMiddleware admits requests with a live session. A session without a cooperative only reaches
+// the paths listed in withoutCooperative, relative to the group the middleware is mounted on, since
+// every other route reads or writes the data of the session's cooperative.
+func AuthMiddleware(withoutCooperative ...string) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		// Prefer cookie-based JWT, but also accept Authorization: Bearer <token>
 		tokenString := c.Cookies("jwt")
@@ -26,18 +29,41 @@ func AuthMiddleware() fiber.Handler {
 			})
 		}
 
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := services.ParseToken(tokenString)
+		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"message": "กรุณาเข้าสู่ระบบ",
 			})
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
-		c.Locals("user_id", claims["user_id"])
+		// The token is only good while its session is live and the admin has not been signed out,
+		// deleted or had their role changed since it was issued
+		cooperativeID, err := services.ValidateSession(claims)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": services.ErrSessionInvalid.Error(),
+			})
+		}
+
+		if cooperativeID == "" && !underAnyPath(strings.TrimPrefix(c.Path(), c.Route().Path), withoutCooperative) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": services.ErrNoCooperative.Error(),
+			})
+		}
+
+		c.Locals("user_id", claims.UserID)
+		c.Locals("session_id", claims.SessionID)
+		c.Locals("cooperative_id", cooperativeID)
 		return c.Next()
 	}
 }
+
+// underAnyPath reports whether path is one of paths or below one of them
+func underAnyPath(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,13 @@ package middlewares
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/services"
 	"github.com/gofiber/fiber/v3"
 )
 
-func SuperAdminMiddleware() fiber.Handler {
+// RequirePermission lets the request through only when the signed-in admin's role grants every
+// listed permission. Must run after AuthMiddleware.
+func RequirePermission(permissions ...string) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		userIDStr, ok := c.Locals("user_id").(string)
 		if !ok || userIDStr == "" {
@@ -22,9 +25,15 @@ func SuperAdminMiddleware() fiber.Handler {
 			})
 		}
 
-		if admin.Role != "SUPER_ADMIN" {
+		allowed, err := services.AdminCan(&admin, permissions...)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "ระบบเกิดข้อผิดพลาด",
+			})
+		}
+		if !allowed {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "ไม่มีสิทธิ์ในการเข้าถึงข้อมูลส่วนนี้",
+				"message": services.ErrPermissionForbidden.Error(),
 			})
 		}
 
@@ -4,21 +4,51 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CareerCategory struct {
-	Id           uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
-	CategoryName string        `gorm:"uniqueIndex;not null" json:"categoryName"`
-	SubCategory  []SubCategory `gorm:"foreignKey:CategoryID" json:"subCategory"`
-	CreatedAt    time.Time     `gorm:"type:timestamp;default:now()" json:"createdAt"`
-	UpdatedAt    time.Time     `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+	Id           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	CategoryName string         `gorm:"uniqueIndex;not null" json:"categoryName"`
+	SubCategory  []SubCategory  `gorm:"foreignKey:CategoryID" json:"subCategory"`
+	CreatedAt    time.Time      `gorm:"type:timestamp;default:now()" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"` // soft delete keeps evaluations that reference it intact
 }
 
 type SubCategory struct {
-	Id              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
-	CategoryID      uuid.UUID `gorm:"not null" json:"categoryId"`
-	SubCategoryName string    `gorm:"not null" json:"subCategoryName"`
-	SubNetProfit    float64   `gorm:"not null" json:"subNetProfit"`
-	CreatedAt       time.Time `gorm:"type:timestamp;default:now()" json:"createdAt"`
-	UpdatedAt       time.Time `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+	Id              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	CategoryID      uuid.UUID      `gorm:"not null" json:"categoryId"`
+	SubCategoryName string         `gorm:"not null" json:"subCategoryName"`
+	SubNetProfit    float64        `gorm:"not null" json:"subNetProfit"` // margin in effect today, or as of the requested date
+	MarginID        *uuid.UUID     `gorm:"-" json:"marginId,omitempty"`  // version SubNetProfit was taken from
+	CreatedAt       time.Time      `gorm:"type:timestamp;default:now()" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+}
+
+// CareerUsage counts the evaluations and applicants that reference a catalogue node
+type CareerUsage struct {
+	Id         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Deleted    bool      `json:"deleted"`
+	Evaluates  int64     `json:"evaluates"`
+	Applicants int64     `json:"applicants"`
+}
+
+type CareerCategoryUsage struct {
+	CareerUsage
+	SubCategories []CareerUsage `json:"subCategories"`
+}
+
+// SubCategoryMargin is one effective-dated net-profit margin of a sub-category. The versions of a
+// sub-category form a timeline without gaps or overlaps; ValidTo is exclusive and nil while open.
+type SubCategoryMargin struct {
+	Id            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	SubCategoryID uuid.UUID  `gorm:"type:uuid;not null;index" json:"subCategoryId"`
+	NetProfit     float64    `gorm:"not null" json:"netProfit"`
+	ValidFrom     time.Time  `gorm:"type:date;not null" json:"validFrom"`
+	ValidTo       *time.Time `gorm:"type:date" json:"validTo"`
+	SourceRef     string     `gorm:"default:''" json:"sourceRef"` // regulator announcement or document the margin comes from
+	CreatedAt     time.Time  `gorm:"type:timestamp;default:now()" json:"createdAt"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Cooperative is a tenant of the deployment. Its ID is the 13-digit cooperative registration number
+// that members, evaluates and logs carry in their CooperativeID.
+type Cooperative struct {
+	Id        string    `gorm:"type:varchar(13);primarykey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:now()" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+}
+
+type CooperativeRequest struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type AdminCooperativesRequest struct {
+	CooperativeIDs []string `json:"cooperativeIds"`
+}
+
+type SwitchCooperativeRequest struct {
+	CooperativeID string `json:"cooperativeId"`
+}
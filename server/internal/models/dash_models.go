@@ -46,4 +46,11 @@ type SharesDistributionData struct {
 
 type SharesDistributionResponse struct {
 	Data []SharesDistributionData `json:"data"`
-}
\ No newline at end of file
+}
+
+// DashboardSummaryResponse holds the headline figures of the signed-in admin's cooperative
+type DashboardSummaryResponse struct {
+	TotalMembers     int64 `json:"totalMembers"`
+	TotalEvaluations int64 `json:"totalEvaluations"`
+	TotalShares      int64 `json:"totalShares"`
+}
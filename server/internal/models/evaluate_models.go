@@ -4,25 +4,31 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Evaluate struct {
-	Id           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
-	UserID       uuid.UUID      `gorm:"not null" json:"userID"`
-	User         *Admin         `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	EvaluateType string         `gorm:"not null" json:"evaluateType"`
-	MarginType   string         `gorm:"not null" json:"marginType"`
-	Status       string         `gorm:"not null;default:'รอการอนุมัติ'" json:"status"`
-	Feedback     string         `gorm:"default:''" json:"feedback"`
-	Applicants   []Applicant    `gorm:"foreignKey:EvaluateID" json:"applicants"`
-	Result       EvaluateResult `gorm:"foreignKey:EvaluateID" json:"result"`
-	CreatedAt    time.Time      `gorm:"not null" json:"createdAt"`
-	UpdatedAt    time.Time      `gorm:"not null" json:"updatedAt"`
+	Id            uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	CooperativeID string         `gorm:"type:varchar(13);not null;default:'';index" json:"cooperativeId"`
+	UserID        uuid.UUID      `gorm:"not null" json:"userID"`
+	User          *Admin         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	EvaluateType  string         `gorm:"not null" json:"evaluateType"`
+	MarginType    string         `gorm:"not null" json:"marginType"`
+	Status        string         `gorm:"not null;default:'ฉบับร่าง'" json:"status"`
+	Feedback      string         `gorm:"default:''" json:"feedback"`
+	ReviewerID    *uuid.UUID     `gorm:"type:uuid" json:"reviewerId"` // checker who took the evaluate under review
+	LoanTerms     LoanTerms      `gorm:"embedded;embeddedPrefix:loan_" json:"loanTerms"`
+	Applicants    []Applicant    `gorm:"foreignKey:EvaluateID" json:"applicants"`
+	Result        EvaluateResult `gorm:"foreignKey:EvaluateID" json:"result"`
+	CreatedAt     time.Time      `gorm:"not null" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"not null" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deletedAt"` // soft delete keeps revisions and workflow history for audit
 }
 
 type EvaluateRequest struct {
 	EvaluateType string                `json:"evaluateType"`
 	MarginType   string                `json:"marginType"`
+	LoanTerms    LoanTerms             `json:"loanTerms"`
 	Applicants   []ApplicantRequest    `json:"applicants"`
 	Result       EvaluateResultRequest `json:"result"`
 }
@@ -31,11 +37,20 @@ type Applicant struct {
 	Id                   uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
 	EvaluateID           uuid.UUID        `gorm:"type:uuid;not null" json:"evaluateId"`
 	ApplicantID          uuid.UUID        `gorm:"type:uuid;not null" json:"applicantId"`
-	CareerCategory       string           `gorm:"not null" json:"careerCategory"`
+	Position             int              `gorm:"not null;default:0" json:"position"` // order on the evaluate, the main borrower first
+	CareerCategory       string           `gorm:"not null" json:"careerCategory"`     // name as evaluated; see CareerCategoryRef for the current one
 	Career               string           `gorm:"not null" json:"career"`
 	OtherCareer          string           `gorm:"" json:"otherCareer"`
+	CareerCategoryID     *uuid.UUID       `gorm:"type:uuid;index" json:"careerCategoryId"`
+	CareerCategoryRef    *CareerCategory  `gorm:"foreignKey:CareerCategoryID;constraint:OnDelete:RESTRICT" json:"careerCategoryRef,omitempty"`
+	SubCategoryID        *uuid.UUID       `gorm:"type:uuid;index" json:"subCategoryId"`
+	SubCategoryRef       *SubCategory     `gorm:"foreignKey:SubCategoryID;constraint:OnDelete:RESTRICT" json:"subCategoryRef,omitempty"`
 	Name                 string           `gorm:"not null" json:"name"`
 	IDCard               string           `gorm:"not null;index" json:"idCard"`
+	MemberID             *uuid.UUID       `gorm:"type:uuid;index" json:"memberId"` // resolved by ID card, nil if not a registered member
+	Member               *Member          `gorm:"foreignKey:MemberID;constraint:OnDelete:SET NULL" json:"member,omitempty"`
+	MarginID             *uuid.UUID       `gorm:"type:uuid" json:"marginId"`       // career margin version used, nil if none was on file
+	MarginValue          *float64         `gorm:"default:null" json:"marginValue"` // career margin (%) used, nil on evaluations saved before versioning
 	BusinessActivity     BusinessActivity `gorm:"embedded" json:"businessActivity"`
 	ExpenseItem          ExpenseItem      `gorm:"embedded" json:"expenseItem"`
 	ProfileLost          ProfileLost      `gorm:"embedded" json:"profileLost"`
@@ -49,11 +64,16 @@ type Applicant struct {
 }
 
 type ApplicantRequest struct {
+	ID                   *uuid.UUID       `json:"id"` // existing applicant to update in place
 	CareerCategory       string           `json:"careerCategory"`
 	Career               string           `json:"career"`
 	OtherCareer          string           `json:"otherCareer"`
+	CareerCategoryID     *uuid.UUID       `json:"careerCategoryId"` // optional; resolved from the names when absent
+	SubCategoryID        *uuid.UUID       `json:"subCategoryId"`
 	Name                 string           `json:"name"`
 	IDCard               string           `json:"idCard"`
+	MarginID             *uuid.UUID       `json:"marginId"`    // set by the server
+	MarginValue          *float64         `json:"marginValue"` // set by the server
 	BusinessActivity     BusinessActivity `json:"businessActivity"`
 	ExpenseItem          ExpenseItem      `json:"expenseItem"`
 	ProfileLost          ProfileLost      `json:"profileLost"`
@@ -128,29 +148,34 @@ type OptionsSalary struct {
 }
 
 type EvaluateResult struct {
-	Id           uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
-	EvaluateID   uuid.UUID         `gorm:"type:uuid;not null" json:"evaluateId"`
-	EvaluateType string            `gorm:"not null" json:"evaluateType"`
-	Applicants   []ResultApplicant `gorm:"foreignKey:ResultID" json:"applicants"`
-	DebtDetail   DebtDetail        `gorm:"embedded" json:"debtDetail"`
-	Dti          float64           `gorm:"not null;default:0" json:"dti"`
-	Dscr         float64           `gorm:"not null;default:0" json:"dscr"`
-	CreatedAt    time.Time         `gorm:"not null" json:"createdAt"`
-	UpdatedAt    time.Time         `gorm:"not null" json:"updatedAt"`
+	Id             uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	EvaluateID     uuid.UUID         `gorm:"type:uuid;not null" json:"evaluateId"`
+	EvaluateType   string            `gorm:"not null" json:"evaluateType"`
+	Applicants     []ResultApplicant `gorm:"foreignKey:ResultID" json:"applicants"`
+	DebtDetail     DebtDetail        `gorm:"embedded" json:"debtDetail"`
+	Dti            float64           `gorm:"not null;default:0" json:"dti"`
+	Dscr           float64           `gorm:"not null;default:0" json:"dscr"`
+	ShareCredit    ShareCredit       `gorm:"embedded;embeddedPrefix:share_credit_" json:"shareCredit"`
+	Recommendation Recommendation    `gorm:"embedded;embeddedPrefix:recommendation_" json:"recommendation"`
+	CreatedAt      time.Time         `gorm:"not null" json:"createdAt"`
+	UpdatedAt      time.Time         `gorm:"not null" json:"updatedAt"`
 }
 
 type EvaluateResultRequest struct {
-	EvaluateType string                   `json:"evaluateType"`
-	Applicants   []ResultApplicantRequest `json:"applicants"`
-	DebtDetail   DebtDetail               `json:"debtDetail"`
-	Dti          float64                  `json:"dti"`
-	Dscr         float64                  `json:"dscr"`
+	EvaluateType   string                   `json:"evaluateType"`
+	Applicants     []ResultApplicantRequest `json:"applicants"`
+	DebtDetail     DebtDetail               `json:"debtDetail"`
+	Dti            float64                  `json:"dti"`
+	Dscr           float64                  `json:"dscr"`
+	ShareCredit    ShareCredit              `json:"shareCredit"`
+	Recommendation Recommendation           `json:"recommendation"`
 }
 
 type ResultApplicant struct {
 	Id                     uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
 	EvaluateID             uuid.UUID `gorm:"type:uuid;not null" json:"evaluateId"`
 	ResultID               uuid.UUID `gorm:"type:uuid;not null" json:"resultId"`
+	Position               int       `gorm:"not null;default:0" json:"position"`
 	Name                   string    `gorm:"not null" json:"name"`
 	IDCard                 string    `gorm:"not null;index" json:"idCard"`
 	Salary                 float64   `gorm:"not null;default:0" json:"salary"`
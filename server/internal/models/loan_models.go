@@ -0,0 +1,62 @@
+package models
+
+// Repayment methods of LoanTerms.Method
+const (
+	LoanMethodFlat           = "flat"            // ดอกเบี้ยคงที่ (flat rate)
+	LoanMethodReducing       = "reducing"        // ดอกเบี้ยลดต้นลดดอก เงินงวดเท่ากัน (effective rate)
+	LoanMethodEqualPrincipal = "equal_principal" // เงินต้นเท่ากันทุกงวด
+)
+
+// LoanTerms is the loan applied for. A zero principal means no terms were given and
+// DebtDetail.DebtAmount is typed by hand.
+type LoanTerms struct {
+	Principal  float64 `gorm:"not null;default:0" json:"principal"`
+	AnnualRate float64 `gorm:"not null;default:0" json:"annualRate"` // percent per year
+	TermMonths int64   `gorm:"not null;default:0" json:"termMonths"`
+	Method     string  `gorm:"default:''" json:"method"`
+}
+
+type AmortizationRow struct {
+	Period    int64   `json:"period"`
+	Payment   float64 `json:"payment"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Balance   float64 `json:"balance"`
+}
+
+// LoanSchedule is the amortization of LoanTerms, MonthlyInstallment being the first (largest) payment
+type LoanSchedule struct {
+	Terms              LoanTerms         `json:"terms"`
+	MonthlyInstallment float64           `json:"monthlyInstallment"`
+	TotalPayment       float64           `json:"totalPayment"`
+	TotalInterest      float64           `json:"totalInterest"`
+	Rows               []AmortizationRow `json:"rows"`
+}
+
+// MaxLoanRequest is an evaluate request whose DebtDetail.DebtAmount is solved for. LoanTerms carries the
+// rate, term and method used to turn the installment into a principal; zero caps fall back to the policy.
+type MaxLoanRequest struct {
+	EvaluateRequest
+	MaxDti  float64 `json:"maxDti"`
+	MinDscr float64 `json:"minDscr"`
+}
+
+// MaxLoanLimit is the largest new installment and principal one constraint allows
+type MaxLoanLimit struct {
+	Limit          float64 `json:"limit"` // the DTI (%) or DSCR (times) cap applied
+	MaxInstallment float64 `json:"maxInstallment"`
+	MaxPrincipal   float64 `json:"maxPrincipal"`
+}
+
+type MaxLoanResult struct {
+	Terms          LoanTerms     `json:"terms"`
+	ExistingDebt   float64       `json:"existingDebt"` // monthly obligations already in DebtDetail
+	TotalSalary    float64       `json:"totalSalary"`
+	ResultIncome   float64       `json:"resultIncome"`
+	TotalExpenses  float64       `json:"totalExpenses"`
+	Dti            *MaxLoanLimit `json:"dti"`  // nil when no DTI cap applies
+	Dscr           *MaxLoanLimit `json:"dscr"` // nil when no DSCR floor applies
+	MaxInstallment float64       `json:"maxInstallment"`
+	MaxPrincipal   float64       `json:"maxPrincipal"`
+	Binding        string        `json:"binding"` // "dti" or "dscr", whichever allows less
+}
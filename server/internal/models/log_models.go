@@ -7,10 +7,11 @@ import (
 )
 
 type EvaluateLog struct {
-	Id        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"logs_id"`
-	Timestamp time.Time `gorm:"type:timestamp;default:now()" json:"timestamp"`
-	Username  string    `gorm:"not null" json:"username"`
-	FullName  string    `gorm:"not null" json:"fullname"`
-	Role      string    `gorm:"not null" json:"role"`
-	Action    string    `gorm:"not null" json:"action"`
+	Id            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"logs_id"`
+	CooperativeID string    `gorm:"type:varchar(13);not null;default:'';index" json:"cooperativeId"`
+	Timestamp     time.Time `gorm:"type:timestamp;default:now()" json:"timestamp"`
+	Username      string    `gorm:"not null" json:"username"`
+	FullName      string    `gorm:"not null" json:"fullname"`
+	Role          string    `gorm:"not null" json:"role"`
+	Action        string    `gorm:"not null" json:"action"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Login throttle kinds: failed sign-ins are counted per typed username and per client IP
+const (
+	LoginThrottleUsername = "username"
+	LoginThrottleIP       = "ip"
+)
+
+// LoginThrottle counts recent failed sign-ins for one username or IP. Unknown usernames are tracked
+// the same way as existing ones so a lockout does not reveal which ID cards have accounts.
+type LoginThrottle struct {
+	Id            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	Kind          string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_login_throttles_kind_value" json:"kind"`
+	Value         string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_login_throttles_kind_value" json:"value"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastUsername  string     `gorm:"type:varchar(64);not null;default:''" json:"lastUsername"` // last username tried from an IP
+	LastFailureAt time.Time  `gorm:"not null" json:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil"`
+}
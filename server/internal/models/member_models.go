@@ -8,10 +8,10 @@ import (
 
 type Member struct {
 	Id            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
-	CooperativeID string    `gorm:"not null" json:"cooperativeId"`
-	IdCard        string    `gorm:"uniqueIndex;not null" json:"idCard"`
+	CooperativeID string    `gorm:"not null;uniqueIndex:idx_members_cooperative_id_card;uniqueIndex:idx_members_cooperative_member_id" json:"cooperativeId"`
+	IdCard        string    `gorm:"uniqueIndex:idx_members_cooperative_id_card;not null" json:"idCard"` // unique per cooperative, a person may belong to several
 	AccountYear   string    `gorm:"not null" json:"accountYear"`
-	MemberId      string    `gorm:"uniqueIndex;not null" json:"memberId"`
+	MemberId      string    `gorm:"uniqueIndex:idx_members_cooperative_member_id;not null" json:"memberId"`
 	FullName      string    `gorm:"not null" json:"fullName"`
 	Nationality   string    `gorm:"not null" json:"nationality"`
 	SharesNum     float64   `gorm:"not null" json:"sharesNum"`
@@ -27,3 +27,14 @@ type Member struct {
 	CreatedAt     time.Time `gorm:"not null" json:"createdAt"`
 	UpdatedAt     time.Time `gorm:"not null" json:"updatedAt"`
 }
+
+// MemberEvaluate is an evaluation the member took part in, with the member's role on it
+type MemberEvaluate struct {
+	Evaluate
+	Role string `json:"role"` // ผู้กู้ or ผู้ร่วม (คนที่ n)
+}
+
+type MemberEvaluateHistory struct {
+	Member    Member           `json:"member"`
+	Evaluates []MemberEvaluate `json:"evaluates"`
+}
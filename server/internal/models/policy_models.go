@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EvaluatePolicy holds a cooperative's approval thresholds for one loan type and margin type.
+// A zero threshold is not enforced.
+type EvaluatePolicy struct {
+	Id             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	CooperativeID  string    `gorm:"not null;default:'';uniqueIndex:idx_evaluate_policies_cooperative_type" json:"cooperativeId"`
+	EvaluateType   string    `gorm:"not null;uniqueIndex:idx_evaluate_policies_cooperative_type" json:"evaluateType"`
+	MarginType     string    `gorm:"not null;uniqueIndex:idx_evaluate_policies_cooperative_type" json:"marginType"`
+	MaxDti         float64   `gorm:"not null;default:0" json:"maxDti"`
+	MinDscr        float64   `gorm:"not null;default:0" json:"minDscr"`
+	MinNetIncome   float64   `gorm:"not null;default:0" json:"minNetIncome"`
+	MaxCoBorrowers int64     `gorm:"not null;default:0" json:"maxCoBorrowers"`
+	ShareMultiple  float64   `gorm:"not null;default:0" json:"shareMultiple"` // max loan as a multiple of the borrower's share capital
+	CreatedAt      time.Time `gorm:"type:timestamp;default:now()" json:"createdAt"`
+	UpdatedAt      time.Time `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+}
+
+type EvaluatePolicyRequest struct {
+	EvaluateType   string  `json:"evaluateType"`
+	MarginType     string  `json:"marginType"`
+	MaxDti         float64 `json:"maxDti"`
+	MinDscr        float64 `json:"minDscr"`
+	MinNetIncome   float64 `json:"minNetIncome"`
+	MaxCoBorrowers int64   `json:"maxCoBorrowers"`
+	ShareMultiple  float64 `json:"shareMultiple"`
+}
+
+// ShareCredit is the share-backed credit limit of the borrower when they are a registered member
+type ShareCredit struct {
+	MemberID    *uuid.UUID `gorm:"type:uuid" json:"memberId"`
+	SharesNum   float64    `gorm:"not null;default:0" json:"sharesNum"`
+	SharesValue float64    `gorm:"not null;default:0" json:"sharesValue"`
+	Multiple    float64    `gorm:"not null;default:0" json:"multiple"`
+	MaxLoan     float64    `gorm:"not null;default:0" json:"maxLoan"` // 0 = no limit configured
+	Exceeded    bool       `gorm:"not null;default:false" json:"exceeded"`
+	Warning     string     `gorm:"default:''" json:"warning"`
+}
+
+// Recommendation is the machine pass/fail verdict of an evaluate against its policy
+type Recommendation struct {
+	Passed   bool       `gorm:"not null;default:false" json:"passed"`
+	PolicyID *uuid.UUID `gorm:"type:uuid" json:"policyId"`
+	Reasons  []string   `gorm:"type:jsonb;serializer:json" json:"reasons"`
+}
@@ -1,8 +1,7 @@
 package models
 
-// PublicKPIResponse is the response model for the public (no-auth) KPI endpoint
+// PublicKPIResponse is the response model for the public (no-auth) KPI endpoint. It only carries
+// platform-wide figures; anything belonging to a cooperative is in DashboardSummaryResponse.
 type PublicKPIResponse struct {
-	TotalMembers     int64 `json:"totalMembers"`
-	TotalEvaluations int64 `json:"totalEvaluations"`
-	TotalShares      int64 `json:"totalShares"`
+	TotalCooperatives int64 `json:"totalCooperatives"`
 }
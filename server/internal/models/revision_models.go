@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EvaluateRevision is an immutable snapshot of an evaluate taken on every create and update
+type EvaluateRevision struct {
+	Id         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	EvaluateID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_evaluate_revisions_revision" json:"evaluateId"`
+	Revision   int       `gorm:"not null;uniqueIndex:idx_evaluate_revisions_revision" json:"revision"`
+	EditorID   uuid.UUID `gorm:"type:uuid;not null" json:"editorId"`
+	Editor     *Admin    `gorm:"foreignKey:EditorID" json:"editor,omitempty"`
+	Snapshot   *Evaluate `gorm:"type:jsonb;serializer:json" json:"snapshot,omitempty"`
+	CreatedAt  time.Time `gorm:"not null" json:"createdAt"`
+}
+
+// RevisionFieldChange is one changed field between two revisions, addressed by JSON path
+type RevisionFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type EvaluateRevisionDiff struct {
+	From    EvaluateRevision      `json:"from"`
+	To      EvaluateRevision      `json:"to"`
+	Changes []RevisionFieldChange `json:"changes"`
+}
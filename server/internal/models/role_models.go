@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permissions granted through roles. Routes that are not guarded by one are open to every signed-in admin.
+const (
+	PermissionMemberWrite     = "member:write"      // create, edit, delete, seed and import members
+	PermissionCareerWrite     = "career:write"      // edit the career catalogue and its margin tables
+	PermissionPolicyWrite     = "policy:write"      // manage approval policies
+	PermissionEvaluateWrite   = "evaluate:write"    // create and edit evaluates and move them through the workflow
+	PermissionEvaluateDelete  = "evaluate:delete"   // delete evaluates
+	PermissionEvaluateApprove = "evaluate:approve"  // review, approve, reject and cancel approved evaluates
+	PermissionEvaluateReadAll = "evaluate:read:all" // list and export evaluates of every admin
+	PermissionDashboardRead   = "dashboard:read"    // read the dashboard and its address filters
+	PermissionLogsRead        = "logs:read"         // read the evaluate activity log
+	PermissionAdminManage     = "admin:manage"      // manage admins, their sessions and roles
+	PermissionTenantSwitch    = "tenant:switch"     // work in any cooperative, not only the assigned ones
+)
+
+// Permissions lists every permission in display order
+var Permissions = []string{
+	PermissionMemberWrite,
+	PermissionCareerWrite,
+	PermissionPolicyWrite,
+	PermissionEvaluateWrite,
+	PermissionEvaluateDelete,
+	PermissionEvaluateApprove,
+	PermissionEvaluateReadAll,
+	PermissionDashboardRead,
+	PermissionLogsRead,
+	PermissionAdminManage,
+	PermissionTenantSwitch,
+}
+
+// Built-in role names
+const (
+	RoleSuperAdmin = "SUPER_ADMIN"
+	RoleAdmin      = "ADMIN"
+	RoleTeller     = "TELLER"
+
+	// RolePending is given to self-registered admins; it grants nothing until a manager assigns a role
+	RolePending = "PENDING"
+)
+
+// Role is a named set of permissions. Admin.Role holds the role name.
+type Role struct {
+	Id          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	Name        string    `gorm:"type:varchar(20);not null;uniqueIndex" json:"name"`
+	Description string    `gorm:"default:''" json:"description"`
+	Permissions []string  `gorm:"type:jsonb;serializer:json" json:"permissions"`
+	System      bool      `gorm:"not null;default:false" json:"system"` // built-in roles cannot be renamed or deleted
+	CreatedAt   time.Time `gorm:"type:timestamp;default:now()" json:"createdAt"`
+	UpdatedAt   time.Time `gorm:"type:timestamp;default:now()" json:"updatedAt"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// DefaultRoles are seeded on migration and used as a fallback when a built-in role row is missing
+var DefaultRoles = []Role{
+	{
+		Name:        RoleSuperAdmin,
+		Description: "ผู้ดูแลระบบสูงสุด",
+		Permissions: Permissions,
+		System:      true,
+	},
+	{
+		Name:        RoleAdmin,
+		Description: "เจ้าหน้าที่สินเชื่อ",
+		Permissions: []string{PermissionMemberWrite, PermissionCareerWrite, PermissionEvaluateWrite, PermissionEvaluateDelete, PermissionDashboardRead},
+		System:      true,
+	},
+	{
+		Name:        RoleTeller,
+		Description: "พนักงานสาขา",
+		Permissions: []string{PermissionMemberWrite, PermissionEvaluateWrite, PermissionDashboardRead},
+		System:      true,
+	},
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. The refresh token is stored only as a hash and rotated on every
+// refresh; presenting an already rotated token revokes the session.
+type Session struct {
+	Id                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	AdminID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"adminId"`
+	Admin             *Admin     `gorm:"foreignKey:AdminID;constraint:OnDelete:CASCADE" json:"-"`
+	RefreshTokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	PreviousTokenHash string     `gorm:"index" json:"-"`
+	Device            string     `gorm:"default:''" json:"device"`
+	IP                string     `gorm:"default:''" json:"ip"`
+	UserAgent         string     `gorm:"default:''" json:"userAgent"`
+	LastSeenAt        time.Time  `gorm:"not null" json:"lastSeenAt"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expiresAt"`
+	CooperativeID     string     `gorm:"type:varchar(13);not null;default:''" json:"cooperativeId"` // tenant the session is working in
+	RevokedAt         *time.Time `json:"revokedAt"`
+	Current           bool       `gorm:"-" json:"current"` // the session of the request
+	CreatedAt         time.Time  `gorm:"not null" json:"createdAt"`
+	UpdatedAt         time.Time  `gorm:"not null" json:"updatedAt"`
+}
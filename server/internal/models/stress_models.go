@@ -0,0 +1,37 @@
+package models
+
+import "github.com/google/uuid"
+
+// StressShock is one adverse scenario applied on top of an evaluate. Zero fields leave that input unchanged.
+type StressShock struct {
+	Name                string  `json:"name"`
+	RateIncrease        float64 `json:"rateIncrease"`        // percentage points added to the loan's annual rate
+	SalaryReduction     float64 `json:"salaryReduction"`     // % cut to salary and other regular income
+	BusinessReduction   float64 `json:"businessReduction"`   // % cut to the business net profit share
+	OptionsReduction    float64 `json:"optionsReduction"`    // % cut to other documented income (OptionsSalary)
+	ExpenseRateIncrease float64 `json:"expenseRateIncrease"` // percentage points added to the consumption expense rate
+	LivingExpenseFloor  float64 `json:"livingExpenseFloor"`  // minimum living expenses per applicant, บาท/เดือน
+}
+
+type StressRequest struct {
+	Scenarios []StressShock `json:"scenarios"` // empty = the default scenarios
+}
+
+type StressScenarioResult struct {
+	Shock          StressShock    `json:"shock"`
+	DebtAmount     float64        `json:"debtAmount"`
+	TotalDebt      float64        `json:"totalDebt"`
+	Dti            float64        `json:"dti"`
+	Dscr           float64        `json:"dscr"`
+	DtiChange      float64        `json:"dtiChange"`  // against the baseline
+	DscrChange     float64        `json:"dscrChange"` // against the baseline
+	Breaks         bool           `json:"breaks"`     // the scenario fails the policy
+	Recommendation Recommendation `json:"recommendation"`
+	Warning        string         `json:"warning,omitempty"`
+}
+
+type StressTestResult struct {
+	EvaluateID uuid.UUID              `json:"evaluateId"`
+	Baseline   StressScenarioResult   `json:"baseline"`
+	Scenarios  []StressScenarioResult `json:"scenarios"`
+}
@@ -14,6 +14,12 @@ type Admin struct {
 	Role      string    `gorm:"type:varchar(20);not null;default:'ADMIN'" json:"role"`
 	CreatedAt time.Time `gorm:"type:timestamp;default:now()" json:"created_at"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:now()" json:"updated_at"`
+
+	// TokenVersion is stamped into access tokens; bumping it invalidates every token already issued
+	TokenVersion int `gorm:"not null;default:0" json:"-"`
+
+	// Cooperatives the admin works in
+	Cooperatives []Cooperative `gorm:"many2many:admin_cooperatives;constraint:OnDelete:CASCADE" json:"cooperatives,omitempty"`
 }
 
 type AdminRegister struct {
@@ -31,4 +37,10 @@ type AdminUpdateRoleRequest struct {
 	Role string `json:"role"`
 }
 
+// AdminPasswordRequest changes a password; CurrentPassword is only checked when admins change their own
+type AdminPasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
 
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Evaluate workflow states
+const (
+	EvaluateStatusDraft       = "ฉบับร่าง"
+	EvaluateStatusSubmitted   = "รอการอนุมัติ"
+	EvaluateStatusUnderReview = "อยู่ระหว่างพิจารณา"
+	EvaluateStatusApproved    = "อนุมัติ"
+	EvaluateStatusRejected    = "ไม่อนุมัติ"
+	EvaluateStatusDisbursed   = "เบิกจ่ายแล้ว"
+	EvaluateStatusCancelled   = "ยกเลิก"
+)
+
+// EvaluateStatusHistory records one workflow transition of an evaluate
+type EvaluateStatusHistory struct {
+	Id         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primarykey" json:"id"`
+	EvaluateID uuid.UUID `gorm:"type:uuid;not null;index" json:"evaluateId"`
+	ActorID    uuid.UUID `gorm:"type:uuid;not null" json:"actorId"`
+	Actor      *Admin    `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	FromStatus string    `gorm:"not null" json:"fromStatus"`
+	ToStatus   string    `gorm:"not null" json:"toStatus"`
+	Feedback   string    `gorm:"default:''" json:"feedback"`
+	CreatedAt  time.Time `gorm:"not null" json:"createdAt"`
+}
+
+type EvaluateStatusRequest struct {
+	Status   string `json:"status"`
+	Feedback string `json:"feedback"`
+}
@@ -3,23 +3,37 @@ package routes
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/gofiber/fiber/v3"
 )
 
 func setUpAuthRoutes(authRoute fiber.Router) {
 	authRoute.Post("/register-admin", controllers.RegisterAdmin)
 	authRoute.Post("/login-admin", controllers.LoginAdmin)
+	authRoute.Post("/refresh", controllers.RefreshSession) // rotate the refresh token, issue a new access token
 }
 
 func setUpAuthWithProtectedRoutes(protectedRoute fiber.Router) {
 	protectedRoute.Post("/logout", controllers.Logout)
 	protectedRoute.Get("/me", controllers.GetMe)
+	protectedRoute.Put("/me/password", controllers.ChangePassword)    // change own password, signs out every device
+	protectedRoute.Get("/sessions", controllers.GetSessions)          // list own signed-in devices
+	protectedRoute.Delete("/sessions/:id", controllers.RevokeSession) // sign one device out
 
-	// Super Admin endpoints
-	protectedRoute.Get("/admins", controllers.GetAdmins, middlewares.SuperAdminMiddleware())
-	protectedRoute.Patch("/admins/:id/role", controllers.UpdateAdminRole, middlewares.SuperAdminMiddleware())
-	protectedRoute.Get("/evaluate-logs", controllers.GetEvaluateLogs, middlewares.SuperAdminMiddleware())
-	protectedRoute.Post("/admins", controllers.CreateAdmin, middlewares.SuperAdminMiddleware())
-	protectedRoute.Delete("/admins/:id", controllers.DeleteAdmin, middlewares.SuperAdminMiddleware())
-	protectedRoute.Get("/all-evaluates", controllers.GetAllEvaluates, middlewares.SuperAdminMiddleware())
+	// Admin management
+	manageAdmins := middlewares.RequirePermission(models.PermissionAdminManage)
+	protectedRoute.Get("/admins", manageAdmins, controllers.GetAdmins)
+	protectedRoute.Patch("/admins/:id/role", manageAdmins, controllers.UpdateAdminRole)
+	protectedRoute.Post("/admins", manageAdmins, controllers.CreateAdmin)
+	protectedRoute.Delete("/admins/:id", manageAdmins, controllers.DeleteAdmin)
+	protectedRoute.Post("/admins/:id/sign-out", manageAdmins, controllers.SignOutAdmin)            // revoke every session of the admin
+	protectedRoute.Put("/admins/:id/password", manageAdmins, controllers.ResetAdminPassword)       // set a new password and revoke every session
+	protectedRoute.Put("/admins/:id/cooperatives", manageAdmins, controllers.SetAdminCooperatives) // assign the admin to cooperatives
+	protectedRoute.Get("/login-lockouts", manageAdmins, controllers.GetLoginLockouts)              // usernames and IPs locked out of sign-in
+	protectedRoute.Delete("/login-lockouts/:id", manageAdmins, controllers.UnlockLogin)            // lift a sign-in lockout
+
+	// Activity log and evaluates of every admin
+	protectedRoute.Get("/evaluate-logs", middlewares.RequirePermission(models.PermissionLogsRead), controllers.GetEvaluateLogs)
+	protectedRoute.Get("/all-evaluates", middlewares.RequirePermission(models.PermissionEvaluateReadAll), controllers.GetAllEvaluates)
+	protectedRoute.Get("/all-evaluates/export", middlewares.RequirePermission(models.PermissionEvaluateReadAll), controllers.ExportAllEvaluates)
 }
@@ -2,22 +2,36 @@ package routes
 
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/gofiber/fiber/v3"
 )
 
 func setUpCareerRoutes(careerRoute fiber.Router) {
+	// Reading the catalogue is open to every admin; changing it needs career:write
+	canWrite := middlewares.RequirePermission(models.PermissionCareerWrite)
+
 	// Career Category routes
-	careerRoute.Post("/categories", controllers.CreateCareerCategory)
+	careerRoute.Post("/categories", canWrite, controllers.CreateCareerCategory)
 	careerRoute.Get("/categories", controllers.GetCareerCategories)
-	careerRoute.Put("/categories/:id", controllers.UpdateCareerCategory)
-	careerRoute.Delete("/categories/:id", controllers.DeleteCareerCategory)
+	careerRoute.Put("/categories/:id", canWrite, controllers.UpdateCareerCategory)
+	careerRoute.Delete("/categories/:id", canWrite, controllers.DeleteCareerCategory)
+	careerRoute.Get("/categories/:id/usage", controllers.GetCareerCategoryUsage)
 
 	// Sub Category routes
-	careerRoute.Post("/subcategories", controllers.CreateSubCategory)
+	careerRoute.Post("/subcategories", canWrite, controllers.CreateSubCategory)
 	careerRoute.Get("/categories/:categoryId/subcategories", controllers.GetSubCategoriesByCategory)
-	careerRoute.Put("/subcategories/:id", controllers.UpdateSubCategory)
-	careerRoute.Delete("/subcategories/:id", controllers.DeleteSubCategory)
+	careerRoute.Put("/subcategories/:id", canWrite, controllers.UpdateSubCategory)
+	careerRoute.Delete("/subcategories/:id", canWrite, controllers.DeleteSubCategory)
+
+	// Effective-dated margin versions
+	careerRoute.Get("/subcategories/:id/margins", controllers.GetSubCategoryMargins)
+	careerRoute.Post("/subcategories/:id/margins", canWrite, controllers.CreateSubCategoryMargin)
+
+	// Bulk maintenance of the whole table (?format=csv|xlsx|json)
+	careerRoute.Get("/export", controllers.ExportCareerTable)
+	careerRoute.Post("/import", canWrite, controllers.ImportCareerTable)
 
 	// Seed operation
-	careerRoute.Post("/seed", controllers.SeedCareerCategories)
+	careerRoute.Post("/seed", canWrite, controllers.SeedCareerCategories)
 }
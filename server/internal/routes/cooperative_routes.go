@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/gofiber/fiber/v3"
+)
+
+func setUpCooperativeRoutes(protectedRoute fiber.Router) {
+	// Cooperative (tenant) management
+	manageAdmins := middlewares.RequirePermission(models.PermissionAdminManage)
+	cooperativeGroup := protectedRoute.Group("/cooperatives")
+	cooperativeGroup.Get("/", controllers.GetCooperatives)                    // Cooperatives the caller may work in
+	cooperativeGroup.Post("/switch", controllers.SwitchCooperative)           // Switch the session's cooperative
+	cooperativeGroup.Post("/", manageAdmins, controllers.CreateCooperative)   // Create new cooperative
+	cooperativeGroup.Put("/:id", manageAdmins, controllers.UpdateCooperative) // Rename cooperative
+}
@@ -2,13 +2,16 @@ package routes
 
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/gofiber/fiber/v3"
 )
 
 func setUpDashboardRoutes(protectedRoute fiber.Router) {
 	// Dashboard routes
-	dashboardGroup := protectedRoute.Group("/dashboard")
+	dashboardGroup := protectedRoute.Group("/dashboard", middlewares.RequirePermission(models.PermissionDashboardRead))
 
 	// Dashboard data
 	dashboardGroup.Get("/overview", controllers.GetDashboardOverview) // Get dashboard data
+	dashboardGroup.Get("/summary", controllers.GetDashboardSummary)   // Members, evaluations and shares of the cooperative
 }
@@ -46,7 +46,7 @@ func ServeAPIDocs(c fiber.Ctx) error {
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/public/kpi</span></div>
         </div>
-        <div class="description">Get public KPI data for the landing page (no auth required).</div>
+        <div class="description">Get public KPI data for the landing page (no auth required): only the number of cooperatives on the platform. Member, evaluation and share figures belong to a cooperative and are served by <code>/protected/dashboard/summary</code>.</div>
     </div>
 
     <h2>Authentication</h2>
@@ -54,72 +54,252 @@ func ServeAPIDocs(c fiber.Ctx) error {
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/auth/register-admin</span></div>
         </div>
-        <div class="description">Register a new administrator into the system.</div>
+        <div class="description">Request an administrator account. Always answers 202 with the same message once the input is valid, whether or not the ID card or name is already registered, and opens no session. New accounts get the PENDING role, which grants no permissions until an admin with <code>admin:manage</code> assigns a role and cooperatives.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/auth/login-admin</span></div>
         </div>
-        <div class="description">Login functionality that sets the secure authentication JWT cookie.</div>
+        <div class="description">Login functionality that opens a session and sets a 15-minute access token cookie (<code>jwt</code>) plus a rotating refresh token cookie. An unknown ID card and a wrong password get the same 401. After 3 failures per ID card (10 per IP) each further failure opens a rejection window (1s, 2s, 4s … up to 30s) during which attempts are refused immediately with 429 and <code>Retry-After</code>; requests are never delayed. 5 failures per ID card (20 per IP) refuse sign-in for 15 minutes the same way.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/auth/refresh</span></div>
+        </div>
+        <div class="description">Exchange the refresh token (cookie or <code>refreshToken</code> in the body) for a new access token. The refresh token is rotated on every call; replaying an old one revokes the session.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/me</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Fetch currently authenticated administrator's profile.</div>
+        <div class="description">Fetch currently authenticated administrator's profile and the permissions granted by their role.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/me/password</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Change your own password (<code>currentPassword</code>, <code>newPassword</code>). Every session, the current one included, is revoked and all issued tokens stop working.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/logout</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Destroy the local session and clear the authentication cookie.</div>
+        <div class="description">Revoke the current session and clear the authentication cookies.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/sessions</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">List the caller's active sessions with device, IP, user agent and last seen time. The session in use is flagged <code>current</code>.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/sessions/:id</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Revoke one of the caller's own sessions. Access tokens issued to it stop working immediately.</div>
     </div>
 
-    <h2>Super Admin Controls</h2>
+    <h2>Administration</h2>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/admins</span></div>
-            <div class="badges"><span class="auth-badge super-admin">Super Admin Only</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
         </div>
         <div class="description">List and search system administrators (query parameters: ?search=&page=&limit=).</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/admins</span></div>
-            <div class="badges"><span class="auth-badge super-admin">Super Admin Only</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
         </div>
         <div class="description">Create a new administrator account.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method patch">PATCH</span><span class="path">/api/v1/protected/admins/:id/role</span></div>
-            <div class="badges"><span class="auth-badge super-admin">Super Admin Only</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
         </div>
-        <div class="description">Modify an administrator's privilege level (Role: "ADMIN" | "SUPER_ADMIN").</div>
+        <div class="description">Modify an administrator's privilege level (<code>role</code>: the name of an existing role, e.g. "ADMIN", "TELLER", "SUPER_ADMIN"). A changed role signs the admin out of every device.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/admins/:id</span></div>
-            <div class="badges"><span class="auth-badge super-admin">Super Admin Only</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Delete an administrator from the system. Their tokens stop working immediately.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/admins/:id/sign-out</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Sign an administrator out everywhere by revoking all of their sessions and invalidating every access token already issued.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/admins/:id/password</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Reset an administrator's password (<code>newPassword</code>). Like every password change it revokes all of their sessions and invalidates every access token already issued.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/admins/:id/cooperatives</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Replace the cooperatives an administrator works in (<code>cooperativeIds</code>). A changed assignment signs the admin out of every device.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/login-lockouts</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">List ID cards and IPs that are currently locked out of sign-in.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/login-lockouts/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
         </div>
-        <div class="description">Delete an administrator from the system.</div>
+        <div class="description">Lift a sign-in lockout before it expires. The unlock is recorded in the evaluate log.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluate-logs</span></div>
-            <div class="badges"><span class="auth-badge super-admin">Super Admin Only</span></div>
+            <div class="badges"><span class="auth-badge super-admin">logs:read</span></div>
         </div>
-        <div class="description">Access the activity logging for evaluate tracking (Create, Update, Delete).</div>
+        <div class="description">Access the activity logging for evaluate tracking (Create, Update, Delete) and sign-in lockouts and unlocks. Admins with <code>tenant:switch</code> also see entries that belong to no cooperative, such as IP lockouts.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/all-evaluates</span></div>
-            <div class="badges"><span class="auth-badge super-admin">Super Admin Only</span></div>
+            <div class="badges"><span class="auth-badge super-admin">evaluate:read:all</span></div>
         </div>
         <div class="description">Get all evaluations across all administrators (query parameters: ?search=&page=&limit=).</div>
     </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/all-evaluates/export</span></div>
+            <div class="badges"><span class="auth-badge super-admin">evaluate:read:all</span></div>
+        </div>
+        <div class="description">Download all evaluations as a spreadsheet, streamed in batches (query parameters: ?format=xlsx|csv&rowPer=evaluate|applicant&search=&userId=&status=&from=YYYY-MM-DD&to=YYYY-MM-DD).</div>
+    </div>
+
+    <h2>Roles &amp; Permissions</h2>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/roles/permissions</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">List every permission that can be granted: <code>member:write</code>, <code>career:write</code>, <code>policy:write</code>, <code>evaluate:write</code>, <code>evaluate:delete</code>, <code>evaluate:approve</code>, <code>evaluate:read:all</code>, <code>dashboard:read</code>, <code>logs:read</code>, <code>admin:manage</code>, <code>tenant:switch</code>.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/roles</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">List roles with their permissions. SUPER_ADMIN, ADMIN and TELLER are built in. On start-up built-in roles are created if missing and given any default permission they lack; custom roles are left untouched. The name PENDING is reserved.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/roles</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Create a role (<code>name</code>, <code>description</code>, <code>permissions</code>).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/roles/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Get a role by ID.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/roles/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Update a role. Built-in roles keep their name; renaming a custom role moves its admins along and signs them out everywhere. Permission changes apply on the next request. At least one role must keep <code>admin:manage</code>.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/roles/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Delete a custom role that no admin holds.</div>
+    </div>
+
+    <h2>Cooperatives</h2>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/cooperatives</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">List the cooperatives the caller may work in and the one the session currently works in (<code>current</code>). Members, evaluations, dashboards, dropdowns and evaluate logs only ever show data of the current cooperative; career tables are shared. Member ID cards and member numbers are unique within a cooperative, so one person may be a member of several. A session without a cooperative gets 403 everywhere except its own account and sessions, <code>/cooperatives</code>, <code>/admins</code>, <code>/login-lockouts</code> and <code>/roles</code>.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/cooperatives/switch</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Switch the current session to another cooperative (<code>cooperativeId</code>). Admins may switch between the cooperatives assigned to them; <code>tenant:switch</code> allows any cooperative.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/cooperatives</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Create a cooperative (<code>id</code>: 13-digit registration number, <code>name</code>).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/cooperatives/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">admin:manage</span></div>
+        </div>
+        <div class="description">Rename a cooperative (<code>name</code>).</div>
+    </div>
+
+    <h2>Approval Policies</h2>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/policies</span></div>
+            <div class="badges"><span class="auth-badge super-admin">policy:write</span></div>
+        </div>
+        <div class="description">List the current cooperative's approval policies (DTI/DSCR thresholds per loan type and margin type). Each cooperative sets its own policies.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/policies</span></div>
+            <div class="badges"><span class="auth-badge super-admin">policy:write</span></div>
+        </div>
+        <div class="description">Create an approval policy (maxDti, minDscr, minNetIncome, maxCoBorrowers, shareMultiple = max loan as a multiple of the borrower's share capital, checked against loanTerms.principal and only reported when no principal is given; 0 = not enforced).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/policies/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">policy:write</span></div>
+        </div>
+        <div class="description">Get an approval policy by ID.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/policies/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">policy:write</span></div>
+        </div>
+        <div class="description">Update an approval policy.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/policies/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">policy:write</span></div>
+        </div>
+        <div class="description">Delete an approval policy.</div>
+    </div>
 
     <h2>Career Categories</h2>
     <div class="endpoint">
@@ -127,28 +307,35 @@ func ServeAPIDocs(c fiber.Ctx) error {
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/career/categories</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">List all career categories.</div>
+        <div class="description">List all career categories. Sub-category margins (subNetProfit, marginId) are the versions in effect today, or on <code>?asOf=YYYY-MM-DD</code>.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/career/categories</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
         </div>
         <div class="description">Create a new career category.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/career/categories/:id</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
         </div>
         <div class="description">Update an existing career category.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/career/categories/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
+        </div>
+        <div class="description">Soft-delete a career category and its sub-categories. They leave the catalogue, but evaluations that reference them keep resolving; creating a category with the same name restores it.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/career/categories/:id/usage</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Delete a career category.</div>
+        <div class="description">Number of the current cooperative's evaluations and applicants that reference the category and each of its sub-categories (deleted ones included, flagged with <code>deleted</code>).</div>
     </div>
 
     <h2>Career Sub-Categories</h2>
@@ -162,29 +349,57 @@ func ServeAPIDocs(c fiber.Ctx) error {
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/career/subcategories</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
         </div>
-        <div class="description">Create a new career sub-category.</div>
+        <div class="description">Create a new career sub-category. Body: { categoryId, subCategoryName, subNetProfit, validFrom (YYYY-MM-DD, default today), sourceRef }. The margin is recorded as the first margin version.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/career/subcategories/:id</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
         </div>
-        <div class="description">Update an existing career sub-category.</div>
+        <div class="description">Update an existing career sub-category. A changed subNetProfit is recorded as a new margin version effective from validFrom (default today) with sourceRef; earlier versions are kept.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/career/subcategories/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
+        </div>
+        <div class="description">Soft-delete a career sub-category; evaluations that reference it keep resolving.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/career/subcategories/:id/margins</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Delete a career sub-category.</div>
+        <div class="description">List the effective-dated margin versions of a sub-category (netProfit, validFrom, validTo exclusive, sourceRef), latest first.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
-            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/career/seed</span></div>
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/career/subcategories/:id/margins</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
+        </div>
+        <div class="description">Add a margin version. Body: { netProfit, validFrom (YYYY-MM-DD, default today), sourceRef }. The version covering validFrom is closed on that day. Returns 409 when a version starting the same day is already used by an evaluation. Evaluations record the margin version (marginId, marginValue) each applicant was calculated with.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/career/export</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
+        <div class="description">Download the whole career table with the margins in effect today or on <code>?asOf=YYYY-MM-DD</code>. <code>?format=xlsx|csv</code> gives one row per sub-category (categoryName, subCategoryName, netProfit, validFrom, sourceRef); <code>json</code> gives the category → sub-category tree.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/career/import</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
+        </div>
+        <div class="description">Upload the career table (multipart field <code>file</code>, .csv/.xlsx in the export layout or .json tree). Returns the diff per row (added, changed, unchanged, removed, error). Changed margins become new margin versions from validFrom, or <code>effectiveDate</code> when a row has none. <code>dryRun=true</code> only reports the diff; <code>removeMissing=true</code> also deletes the sub-categories and categories missing from the file. Applied in one transaction; nothing is written when any row is invalid (422 with the report).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/career/seed</span></div>
+            <div class="badges"><span class="auth-badge super-admin">career:write</span></div>
+        </div>
         <div class="description">Seed career categories and sub-categories with default data.</div>
     </div>
 
@@ -198,9 +413,16 @@ func ServeAPIDocs(c fiber.Ctx) error {
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
-            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/members</span></div>
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/members/export</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
+        <div class="description">Download the full member registry in the seed file shape, re-importable via /members/import (query parameters: ?format=csv|xlsx|json&fullName=&subdistrict=&district=&province=&accountYear=&memberType=&joinedFrom=YYYY-MM-DD&joinedTo=YYYY-MM-DD).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/members</span></div>
+            <div class="badges"><span class="auth-badge super-admin">member:write</span></div>
+        </div>
         <div class="description">Create a new member.</div>
     </div>
     <div class="endpoint">
@@ -212,61 +434,82 @@ func ServeAPIDocs(c fiber.Ctx) error {
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
-            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/members/:id</span></div>
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/members/:id/evaluates</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
+        <div class="description">Get the member (including shareholding) and every evaluation they were the borrower or a co-borrower on, with their role on each. Evaluations the caller may not read (see <code>GET /evaluates/:id</code>) are left out.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/members/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">member:write</span></div>
+        </div>
         <div class="description">Update a member by ID.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/members/:id</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">member:write</span></div>
         </div>
         <div class="description">Delete a member by ID.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/members/seed</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">member:write</span></div>
         </div>
         <div class="description">Seed members from a JSON data file.</div>
     </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/members/import</span></div>
+            <div class="badges"><span class="auth-badge super-admin">member:write</span></div>
+        </div>
+        <div class="description">Import members from a CSV/XLSX registry file (multipart field <code>file</code>, ?dryRun=true to validate only). Returns a per-row report; rows are upserted by ID card within the current cooperative, member IDs and names must not clash with its other members, all in one transaction and nothing is saved if any row is invalid (422).</div>
+    </div>
 
     <h2>Dashboard</h2>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/dashboard/overview</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">dashboard:read</span></div>
         </div>
         <div class="description">Get dashboard overview data.</div>
     </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/dashboard/summary</span></div>
+            <div class="badges"><span class="auth-badge super-admin">dashboard:read</span></div>
+        </div>
+        <div class="description">Total members, evaluations and share value of the session's cooperative, shown on the landing page once signed in.</div>
+    </div>
 
     <h2>Dropdown</h2>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/dropdown/full</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">dashboard:read</span></div>
         </div>
         <div class="description">Get full dropdown data (all provinces, districts, sub-districts).</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/dropdown/subdistricts</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">dashboard:read</span></div>
         </div>
         <div class="description">Get sub-districts for dropdown selection.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/dropdown/districts</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">dashboard:read</span></div>
         </div>
         <div class="description">Get districts for dropdown selection.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/dropdown/provinces</span></div>
-            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+            <div class="badges"><span class="auth-badge super-admin">dashboard:read</span></div>
         </div>
         <div class="description">Get provinces for dropdown selection.</div>
     </div>
@@ -282,37 +525,100 @@ func ServeAPIDocs(c fiber.Ctx) error {
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/evaluates</span></div>
+            <div class="badges"><span class="auth-badge super-admin">evaluate:write</span></div>
+        </div>
+        <div class="description">Process and persist a totally new credit evaluation transaction. Each applicant is linked to the career catalogue by careerCategoryId/subCategoryId when sent, otherwise by the category and career names.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/evaluates/preview</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Compute the evaluation result (income/expense rollups, total debt, DTI, DSCR) without saving anything. Applicant names and ID cards are validated as on create.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/evaluates/max-loan</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Process and persist a totally new credit evaluation transaction.</div>
+        <div class="description">Solve for the largest new monthly installment and principal that still passes. Body is an evaluate request (existing debts in result.debtDetail, rate/term/method in loanTerms) plus optional maxDti/minDscr, defaulting to the policy; applicant names and ID cards are validated as on create. Returns the limit under each constraint and which one binds.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Get a single targeted evaluate result format via its UUID.</div>
+        <div class="description">Get a single targeted evaluate result format via its UUID. Applicants keep the career names they were evaluated with; careerCategoryRef/subCategoryRef carry the current catalogue entries for display. Only the admin who prepared the evaluation, or one whose role holds <code>evaluate:read:all</code>, can read it (404 otherwise); the same rule covers its history, schedule, stress test, revisions and export.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method put">PUT</span><span class="path">/api/v1/protected/evaluates/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">evaluate:write</span></div>
+        </div>
+        <div class="description">Update an existing evaluation. Applicant and result rows are updated in place, matched by applicant <code>id</code> and then by ID card; applicants left out of the body are removed. Only a ฉบับร่าง evaluation can be edited (409 otherwise); send it back to draft first.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method patch">PATCH</span><span class="path">/api/v1/protected/evaluates/:id/status</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Update an existing evaluation. Overwrites sub-fields carefully.</div>
+        <div class="description">Move the evaluation through the workflow (ฉบับร่าง → รอการอนุมัติ → อยู่ระหว่างพิจารณา → อนุมัติ/ไม่อนุมัติ → เบิกจ่ายแล้ว/ยกเลิก). Review and approval must be done by someone other than the preparer, and approval by someone other than the checker who took it under review; approve/reject/send back and cancelling an approved evaluation need <code>evaluate:approve</code>, every other move needs <code>evaluate:write</code>. A rejected evaluation can be cancelled.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
-            <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/evaluates/:id</span></div>
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id/history</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">List every workflow transition of the evaluation (actor, from, to, feedback, timestamp).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id/schedule</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Delete the evaluation.</div>
+        <div class="description">Get the amortization schedule of the evaluate's loan terms (principal, annualRate, termMonths, method = flat|reducing|equal_principal). With loan terms the debt amount of this loan is derived from them.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method post">POST</span><span class="path">/api/v1/protected/evaluates/:id/stress</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Recompute DTI/DSCR of a saved evaluation under stress scenarios and flag the ones that fail its policy. Body: { scenarios: [{ name, rateIncrease (percentage points), salaryReduction, businessReduction, optionsReduction (% cut per income bucket), expenseRateIncrease (percentage points), livingExpenseFloor (บาท/เดือน per applicant) }] }. An empty body runs the default scenarios (rate +1/+2/+3%, income cuts, higher expense rate).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id/revisions</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">List every revision of the evaluation (revision number, editor, timestamp). Revisions of deleted evaluations stay readable.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id/revisions/:rev</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Get the full snapshot of one revision.</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id/revisions/diff</span></div>
+            <div class="badges"><span class="auth-badge">Auth Required</span></div>
+        </div>
+        <div class="description">Field-level diff between two revisions. Applicants are paired by ID card, so reordering co-borrowers only shows the fields that changed (query parameters: ?from=&to=).</div>
+    </div>
+    <div class="endpoint">
+        <div class="endpoint-header">
+            <div class="endpoint-route"><span class="method delete">DELETE</span><span class="path">/api/v1/protected/evaluates/:id</span></div>
+            <div class="badges"><span class="auth-badge super-admin">evaluate:delete</span></div>
+        </div>
+        <div class="description">Delete the evaluation. Only a ฉบับร่าง evaluation can be deleted (409 otherwise); cancel it through the workflow instead. The evaluation is soft-deleted: its revisions and workflow history are kept for audit.</div>
     </div>
     <div class="endpoint">
         <div class="endpoint-header">
             <div class="endpoint-route"><span class="method get">GET</span><span class="path">/api/v1/protected/evaluates/:id/export</span></div>
             <div class="badges"><span class="auth-badge">Auth Required</span></div>
         </div>
-        <div class="description">Export an evaluation as rendered HTML for printing/PDF.</div>
+        <div class="description">Export an evaluation as rendered HTML for printing, as an A4 PDF generated on the server with the Thai font embedded, or as an editable Word document. <code>mode=detailed</code> appends a worksheet page per applicant with career, business, P&amp;L, shareholding and itemised salary figures (query parameters: ?format=html|pdf|docx&amp;mode=detailed).</div>
     </div>
 </body>
 </html>`
@@ -2,12 +2,14 @@ package routes
 
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/gofiber/fiber/v3"
 )
 
 func setUpDropdownRoutes(protectedRoute fiber.Router) {
 	// Dropdown routes
-	dropdownGroup := protectedRoute.Group("/dropdown")
+	dropdownGroup := protectedRoute.Group("/dropdown", middlewares.RequirePermission(models.PermissionDashboardRead))
 
 	// Dropdown data
 	dropdownGroup.Get("/full", controllers.GetFullDropdown) // Get full dropdown
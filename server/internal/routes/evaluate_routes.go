@@ -2,6 +2,8 @@ package routes
 
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -9,13 +11,25 @@ func setUpEvaluateRoutes(protectedRoute fiber.Router) {
 	// Evaluate management routes
 	evaluateGroup := protectedRoute.Group("/evaluates")
 
-	// Basic CRUD operations
-	evaluateGroup.Post("/", controllers.CreateEvaluate)      // Create new evaluate
-	evaluateGroup.Get("/", controllers.GetEvaluates)         // Get all evaluates
-	evaluateGroup.Get("/:id", controllers.GetEvaluate)       // Get evaluate by ID
-	evaluateGroup.Put("/:id", controllers.UpdateEvaluate)          // Update evaluate by ID
-	evaluateGroup.Patch("/:id/status", controllers.UpdateEvaluateStatus) // Update status & feedback
-	evaluateGroup.Delete("/:id", controllers.DeleteEvaluate)        // Delete evaluate by ID
-	// Export evaluate (rendered HTML for printing/PDF)
+	// Basic CRUD operations; status changes check their own permission per transition
+	writeEvaluates := middlewares.RequirePermission(models.PermissionEvaluateWrite)
+	deleteEvaluates := middlewares.RequirePermission(models.PermissionEvaluateDelete)
+	evaluateGroup.Post("/", writeEvaluates, controllers.CreateEvaluate)       // Create new evaluate
+	evaluateGroup.Get("/", controllers.GetEvaluates)                          // Get all evaluates
+	evaluateGroup.Post("/preview", controllers.PreviewEvaluate)               // Compute result without saving
+	evaluateGroup.Post("/max-loan", controllers.MaxLoan)                      // Largest installment/principal that passes DTI and DSCR
+	evaluateGroup.Get("/:id", controllers.GetEvaluate)                        // Get evaluate by ID
+	evaluateGroup.Put("/:id", writeEvaluates, controllers.UpdateEvaluate)     // Update evaluate by ID
+	evaluateGroup.Patch("/:id/status", controllers.UpdateEvaluateStatus)      // Move status through the workflow
+	evaluateGroup.Get("/:id/history", controllers.GetEvaluateStatusHistory)   // Workflow transition history
+	evaluateGroup.Get("/:id/schedule", controllers.GetEvaluateSchedule)       // Loan amortization schedule
+	evaluateGroup.Post("/:id/stress", controllers.StressEvaluate)             // DTI/DSCR under rate, income and expense shocks
+	evaluateGroup.Delete("/:id", deleteEvaluates, controllers.DeleteEvaluate) // Delete evaluate by ID
+
+	// Revision history
+	evaluateGroup.Get("/:id/revisions", controllers.GetEvaluateRevisions)       // List revisions
+	evaluateGroup.Get("/:id/revisions/diff", controllers.DiffEvaluateRevisions) // Field-level diff (?from=&to=)
+	evaluateGroup.Get("/:id/revisions/:rev", controllers.GetEvaluateRevision)   // Get revision snapshot
+	// Export evaluate (?format=html|pdf|docx)
 	evaluateGroup.Get("/:id/export", controllers.ExportEvaluate)
 }
@@ -2,20 +2,26 @@ package routes
 
 import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/gofiber/fiber/v3"
 )
 
 func setUpMemberRoutes(protectedRoute fiber.Router) {
 	// Member management routes
 	memberGroup := protectedRoute.Group("/members")
+	canWrite := middlewares.RequirePermission(models.PermissionMemberWrite)
 
 	// Basic CRUD operations
-	memberGroup.Post("/", controllers.CreateMember)      // Create new member
-	memberGroup.Get("/", controllers.GetMembers)         // Get all members with optional filters
-	memberGroup.Get("/:id", controllers.GetMember)       // Get member by ID
-	memberGroup.Put("/:id", controllers.UpdateMember)    // Update member by ID
-	memberGroup.Delete("/:id", controllers.DeleteMember) // Delete member by ID
+	memberGroup.Post("/", canWrite, controllers.CreateMember)         // Create new member
+	memberGroup.Get("/", controllers.GetMembers)                      // Get all members with optional filters
+	memberGroup.Get("/export", controllers.ExportMembers)             // Export members as CSV/XLSX/JSON (before /:id)
+	memberGroup.Get("/:id", controllers.GetMember)                    // Get member by ID
+	memberGroup.Put("/:id", canWrite, controllers.UpdateMember)       // Update member by ID
+	memberGroup.Delete("/:id", canWrite, controllers.DeleteMember)    // Delete member by ID
+	memberGroup.Get("/:id/evaluates", controllers.GetMemberEvaluates) // Evaluations the member borrowed or co-borrowed on
 
-	// Seed operation
-	memberGroup.Post("/seed", controllers.SeedMembers) // Seed members from JSON file
+	// Seed and import operations
+	memberGroup.Post("/seed", canWrite, controllers.SeedMembers)     // Seed members from JSON file
+	memberGroup.Post("/import", canWrite, controllers.ImportMembers) // Import members from CSV/XLSX registry file
 }
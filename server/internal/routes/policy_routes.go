@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/gofiber/fiber/v3"
+)
+
+func setUpPolicyRoutes(protectedRoute fiber.Router) {
+	// Approval policy routes
+	policyGroup := protectedRoute.Group("/policies", middlewares.RequirePermission(models.PermissionPolicyWrite))
+
+	policyGroup.Post("/", controllers.CreatePolicy)      // Create new policy
+	policyGroup.Get("/", controllers.GetPolicies)        // Get all policies
+	policyGroup.Get("/:id", controllers.GetPolicy)       // Get policy by ID
+	policyGroup.Put("/:id", controllers.UpdatePolicy)    // Update policy by ID
+	policyGroup.Delete("/:id", controllers.DeletePolicy) // Delete policy by ID
+}
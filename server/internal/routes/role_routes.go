@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/controllers"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/middlewares"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/gofiber/fiber/v3"
+)
+
+func setUpRoleRoutes(protectedRoute fiber.Router) {
+	// Role and permission management
+	roleGroup := protectedRoute.Group("/roles", middlewares.RequirePermission(models.PermissionAdminManage))
+	roleGroup.Get("/permissions", controllers.GetPermissions) // Permission catalogue (before /:id)
+	roleGroup.Post("/", controllers.CreateRole)               // Create new role
+	roleGroup.Get("/", controllers.GetRoles)                  // Get all roles
+	roleGroup.Get("/:id", controllers.GetRole)                // Get role by ID
+	roleGroup.Put("/:id", controllers.UpdateRole)             // Update role by ID
+	roleGroup.Delete("/:id", controllers.DeleteRole)          // Delete role by ID
+}
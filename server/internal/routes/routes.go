@@ -38,8 +38,10 @@ func SetupRoutes(app fiber.Router) {
 	authRoute := api.Group("/auth")
 	setUpAuthRoutes(authRoute)
 
-	// protected routes
-	protectedRoute := api.Group("/protected", middlewares.AuthMiddleware())
+	// protected routes; a session without a cooperative only reaches its own account and the
+	// routes that set cooperatives, admins and roles up
+	protectedRoute := api.Group("/protected", middlewares.AuthMiddleware(
+		"/logout", "/me", "/sessions", "/cooperatives", "/admins", "/login-lockouts", "/roles"))
 	setUpAuthWithProtectedRoutes(protectedRoute)
 
 	// career routes (protected)
@@ -57,4 +59,13 @@ func SetupRoutes(app fiber.Router) {
 
 	// evaluate routes (protected)
 	setUpEvaluateRoutes(protectedRoute)
+
+	// approval policy routes (protected, policy:write)
+	setUpPolicyRoutes(protectedRoute)
+
+	// role routes (protected, admin:manage)
+	setUpRoleRoutes(protectedRoute)
+
+	// cooperative routes (protected)
+	setUpCooperativeRoutes(protectedRoute)
 }
@@ -3,16 +3,23 @@ package services
 import (
 	"errors"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrAdminNotFound = errors.New("ไม่พบผู้ใช้งาน")
+	ErrWrongPassword = errors.New("รหัสผ่านปัจจุบันไม่ถูกต้อง")
+)
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 12)
 	return string(bytes), err
@@ -23,7 +30,15 @@ func VerifyPassword(password, hash string) bool {
 	return err == nil
 }
 
-func GenerateToken(user_id string) (string, error) {
+// AccessClaims are the identity fields carried by an access token
+type AccessClaims struct {
+	UserID       string
+	SessionID    string
+	TokenVersion int
+}
+
+// GenerateToken issues a short-lived access token bound to a session and the admin's token version
+func GenerateToken(user_id string, session_id string, token_version int) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		return "", errors.New("JWT_SECRET environment variable not set")
@@ -31,13 +46,42 @@ func GenerateToken(user_id string) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user_id,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"sid":     session_id,
+		"ver":     token_version,
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(),
 		"iat":     time.Now().Unix(),
 	})
 
 	return token.SignedString([]byte(jwtSecret))
 }
 
+// ParseToken verifies an access token's signature and expiry and returns its claims
+func ParseToken(tokenString string) (*AccessClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, ErrSessionInvalid
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrSessionInvalid
+	}
+	userID, _ := claims["user_id"].(string)
+	sessionID, _ := claims["sid"].(string)
+	version, ok := claims["ver"].(float64)
+	if userID == "" || sessionID == "" || !ok {
+		return nil, ErrSessionInvalid
+	}
+
+	return &AccessClaims{
+		UserID:       userID,
+		SessionID:    sessionID,
+		TokenVersion: int(version),
+	}, nil
+}
+
 func GetAdmins(search string, page int, limit int) ([]models.Admin, int64, error) {
 	var admins []models.Admin
 	var total int64
@@ -65,11 +109,21 @@ func UpdateAdminRole(adminID uuid.UUID, role string) (*models.Admin, error) {
 	if err := database.DB.Where("id = ?", adminID).First(&admin).Error; err != nil {
 		return nil, err
 	}
+	if admin.Role == role {
+		return &admin, nil
+	}
 
-	admin.Role = role
-	admin.UpdatedAt = time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		admin.Role = role
+		admin.UpdatedAt = time.Now()
+		if err := tx.Save(&admin).Error; err != nil {
+			return err
+		}
 
-	if err := database.DB.Save(&admin).Error; err != nil {
+		// Tokens issued under the old role must not keep its permissions
+		return invalidateAdminTokens(tx, admin.Id)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -77,8 +131,94 @@ func UpdateAdminRole(adminID uuid.UUID, role string) (*models.Admin, error) {
 }
 
 func DeleteAdmin(adminID uuid.UUID) error {
-	if err := database.DB.Where("id = ?", adminID).Delete(&models.Admin{}).Error; err != nil {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := invalidateAdminTokens(tx, adminID); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", adminID).Delete(&models.Admin{}).Error
+	})
+}
+
+// RegisterAdmin files a registration without a working role until a manager assigns one. An ID card
+// or name already in use is ignored without an error, and both cases hash the password and run the
+// same single statement, so the public form cannot be used to find out who has an account.
+func RegisterAdmin(username string, password string, fullName string) error {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// The conflict clause covers a concurrent registration of the same ID card
+	return database.DB.Exec(`INSERT INTO admins (username, password, full_name, role)
+		SELECT ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM admins WHERE username = ? OR REPLACE(full_name, ' ', '') = ?)
+		ON CONFLICT (username) DO NOTHING`,
+		username, hashedPassword, fullName, models.RolePending,
+		username, strings.ReplaceAll(fullName, " ", "")).Error
+}
+
+// invalidateAdminTokens bumps the admin's token version and revokes all of their sessions, so every
+// access and refresh token issued so far stops working. Call it whenever role, password or account
+// status changes.
+func invalidateAdminTokens(tx *gorm.DB, adminID uuid.UUID) error {
+	if err := tx.Model(&models.Admin{}).Where("id = ?", adminID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Session{}).
+		Where("admin_id = ? AND revoked_at IS NULL", adminID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// UpdateAdminPassword replaces the admin's password hash and signs them out everywhere. Every
+// password write goes through here so no token issued under the old password survives it.
+func UpdateAdminPassword(adminID uuid.UUID, password string) error {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Admin{}).Where("id = ?", adminID).
+			Updates(map[string]interface{}{"password": hashedPassword, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAdminNotFound
+		}
+		return invalidateAdminTokens(tx, adminID)
+	})
+}
+
+// ChangeOwnPassword lets an admin change their password after confirming the current one
+func ChangeOwnPassword(adminID uuid.UUID, currentPassword string, newPassword string) error {
+	var admin models.Admin
+	if err := database.DB.Where("id = ?", adminID).Limit(1).Find(&admin).Error; err != nil {
 		return err
 	}
-	return nil
+	if admin.Id == uuid.Nil {
+		return ErrAdminNotFound
+	}
+	if !VerifyPassword(currentPassword, admin.Password) {
+		return ErrWrongPassword
+	}
+
+	return UpdateAdminPassword(adminID, newPassword)
+}
+
+// SignOutAdmin signs an admin out of every device
+func SignOutAdmin(adminID uuid.UUID) error {
+	var admin models.Admin
+	if err := database.DB.Where("id = ?", adminID).Limit(1).Find(&admin).Error; err != nil {
+		return err
+	}
+	if admin.Id == uuid.Nil {
+		return ErrAdminNotFound
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return invalidateAdminTokens(tx, adminID)
+	})
 }
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateAdminPasswordInvalidatesTokens(t *testing.T) {
+	db := useFakeDB(t)
+
+	if err := UpdateAdminPassword(uuid.New(), "new-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{`"password"=`, `"token_version"=token_version + 1`, `UPDATE "sessions" SET "revoked_at"=`}
+	writes := strings.Join(db.writes(), "\n")
+	for _, fragment := range want {
+		if !strings.Contains(writes, fragment) {
+			t.Errorf("writes do not contain %q:\n%s", fragment, writes)
+		}
+	}
+}
+
+// A taken ID card or name must cost the same statements as a new registration
+func TestRegisterAdminSameWorkWhenTaken(t *testing.T) {
+	run := func(taken bool) []string {
+		db := useFakeDB(t)
+		db.answer = func(q fakeQuery) (*fakeRows, bool) {
+			if !q.tables()["admins"] || !strings.HasPrefix(strings.ToLower(q.sql), "select") {
+				return nil, false
+			}
+			count := int64(0)
+			if taken {
+				count = 1
+			}
+			return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{count}}}, true
+		}
+
+		if err := RegisterAdmin(fakeIDCard, "password", "สมชาย ใจดี"); err != nil {
+			t.Fatal(err)
+		}
+
+		var statements []string
+		for _, q := range db.recorded() {
+			statements = append(statements, q.sql)
+			for _, arg := range q.args {
+				if arg == "password" {
+					t.Errorf("the password is stored in plain text: %s", q.sql)
+				}
+			}
+		}
+		return statements
+	}
+
+	created, taken := run(false), run(true)
+	if strings.Join(created, "\n") != strings.Join(taken, "\n") {
+		t.Errorf("new registration ran\n%s\na taken one ran\n%s", strings.Join(created, "\n"), strings.Join(taken, "\n"))
+	}
+	if len(created) != 1 || !strings.Contains(created[0], "WHERE NOT EXISTS") || !strings.Contains(created[0], "ON CONFLICT") {
+		t.Errorf("registration = %v, want one guarded insert", created)
+	}
+}
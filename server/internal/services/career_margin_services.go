@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrMarginVersionInUse = errors.New("อัตรากำไรที่มีผลในวันที่นี้ถูกใช้ในแบบประเมินแล้ว กรุณาระบุวันที่มีผลใหม่")
+
+// MarginVersionInput is a new net-profit margin for a sub-category; a zero ValidFrom means today
+type MarginVersionInput struct {
+	NetProfit float64
+	ValidFrom time.Time
+	SourceRef string
+}
+
+// marginDate truncates t to its calendar day, which is how margin validity is compared
+func marginDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// effectiveMarginQuery narrows sub_category_margins to the versions in effect on asOf
+func effectiveMarginQuery(query *gorm.DB, asOf time.Time) *gorm.DB {
+	day := marginDate(asOf).Format("2006-01-02")
+	return query.Where("valid_from <= ?::date AND (valid_to IS NULL OR valid_to > ?::date)", day, day)
+}
+
+// applyMarginsAsOf replaces SubNetProfit with the margin each sub-category had on asOf; sub-categories
+// without a version on that date keep their stored value
+func applyMarginsAsOf(subCategories []models.SubCategory, asOf time.Time) error {
+	if len(subCategories) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(subCategories))
+	for i, sub := range subCategories {
+		ids[i] = sub.Id
+	}
+
+	var versions []models.SubCategoryMargin
+	if err := effectiveMarginQuery(database.DB.Where("sub_category_id IN ?", ids), asOf).Find(&versions).Error; err != nil {
+		return err
+	}
+
+	bySubCategory := make(map[uuid.UUID]models.SubCategoryMargin, len(versions))
+	for _, version := range versions {
+		bySubCategory[version.SubCategoryID] = version
+	}
+
+	for i := range subCategories {
+		if version, ok := bySubCategory[subCategories[i].Id]; ok {
+			marginID := version.Id
+			subCategories[i].SubNetProfit = version.NetProfit
+			subCategories[i].MarginID = &marginID
+		}
+	}
+	return nil
+}
+
+// ensureBaselineMargin records the stored SubNetProfit as the first version of a sub-category created
+// before margins were versioned, so its history starts at the sub-category's creation date
+func ensureBaselineMargin(tx *gorm.DB, subCategory *models.SubCategory) error {
+	var count int64
+	if err := tx.Model(&models.SubCategoryMargin{}).Where("sub_category_id = ?", subCategory.Id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	baseline := models.SubCategoryMargin{
+		SubCategoryID: subCategory.Id,
+		NetProfit:     subCategory.SubNetProfit,
+		ValidFrom:     marginDate(subCategory.CreatedAt),
+	}
+	return tx.Create(&baseline).Error
+}
+
+// addSubCategoryMargin inserts a version into the sub-category's timeline: the version covering
+// ValidFrom is closed on that day and the new one runs until the next later version, if any.
+// A version starting on the same day is corrected in place unless an evaluate already used it.
+func addSubCategoryMargin(tx *gorm.DB, subCategory *models.SubCategory, input MarginVersionInput) (*models.SubCategoryMargin, error) {
+	if err := ensureBaselineMargin(tx, subCategory); err != nil {
+		return nil, err
+	}
+
+	validFrom := input.ValidFrom
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	validFrom = marginDate(validFrom)
+
+	var covering models.SubCategoryMargin
+	err := effectiveMarginQuery(tx.Where("sub_category_id = ?", subCategory.Id), validFrom).Limit(1).Find(&covering).Error
+	if err != nil {
+		return nil, err
+	}
+
+	version := models.SubCategoryMargin{
+		SubCategoryID: subCategory.Id,
+		NetProfit:     input.NetProfit,
+		ValidFrom:     validFrom,
+		SourceRef:     input.SourceRef,
+	}
+
+	switch {
+	case covering.Id != uuid.Nil && marginDate(covering.ValidFrom).Equal(validFrom):
+		var used int64
+		if err := tx.Model(&models.Applicant{}).Where("margin_id = ?", covering.Id).Count(&used).Error; err != nil {
+			return nil, err
+		}
+		if used > 0 {
+			return nil, ErrMarginVersionInUse
+		}
+		covering.NetProfit = input.NetProfit
+		covering.SourceRef = input.SourceRef
+		if err := tx.Save(&covering).Error; err != nil {
+			return nil, err
+		}
+		version = covering
+
+	default:
+		var next models.SubCategoryMargin
+		if err := tx.Where("sub_category_id = ? AND valid_from > ?::date", subCategory.Id, validFrom.Format("2006-01-02")).
+			Order("valid_from ASC").Limit(1).Find(&next).Error; err != nil {
+			return nil, err
+		}
+		if next.Id != uuid.Nil {
+			validTo := next.ValidFrom
+			version.ValidTo = &validTo
+		}
+
+		if covering.Id != uuid.Nil {
+			if err := tx.Model(&covering).Update("valid_to", validFrom).Error; err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the stored value on the margin in effect today
+	var today models.SubCategoryMargin
+	if err := effectiveMarginQuery(tx.Where("sub_category_id = ?", subCategory.Id), time.Now()).Limit(1).Find(&today).Error; err != nil {
+		return nil, err
+	}
+	if today.Id != uuid.Nil && today.NetProfit != subCategory.SubNetProfit {
+		subCategory.SubNetProfit = today.NetProfit
+		if err := tx.Model(subCategory).Update("sub_net_profit", today.NetProfit).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &version, nil
+}
+
+// AddSubCategoryMargin records a new effective-dated margin for a sub-category
+func AddSubCategoryMargin(subCategoryID uuid.UUID, input MarginVersionInput) (*models.SubCategoryMargin, error) {
+	var version *models.SubCategoryMargin
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var subCategory models.SubCategory
+		if err := tx.First(&subCategory, "id = ?", subCategoryID).Error; err != nil {
+			return errors.New("ไม่พบหมวดหมู่ย่อยอาชีพ")
+		}
+
+		var err error
+		version, err = addSubCategoryMargin(tx, &subCategory, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// GetSubCategoryMargins lists every margin version of a sub-category, latest first
+func GetSubCategoryMargins(subCategoryID uuid.UUID) ([]models.SubCategoryMargin, error) {
+	var subCategory models.SubCategory
+	if err := database.DB.First(&subCategory, "id = ?", subCategoryID).Error; err != nil {
+		return nil, errors.New("ไม่พบหมวดหมู่ย่อยอาชีพ")
+	}
+
+	if err := ensureBaselineMargin(database.DB, &subCategory); err != nil {
+		return nil, err
+	}
+
+	var versions []models.SubCategoryMargin
+	if err := database.DB.Where("sub_category_id = ?", subCategoryID).Order("valid_from DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
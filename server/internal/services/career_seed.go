@@ -289,7 +289,7 @@ func SeedCareerCategoriesData() error {
 			var existingSub models.SubCategory
 			if err := database.DB.Where("REPLACE(sub_category_name, ' ', '') = ? AND category_id = ?", cleanSubCategoryName, targetCategory.Id).First(&existingSub).Error; err != nil {
 				// SubCategory does not exist, create it
-				_, err := CreateSubCategory(targetCategory.Id, subData.SubCategoryName, subData.SubNetProfit)
+				_, err := CreateSubCategory(targetCategory.Id, subData.SubCategoryName, MarginVersionInput{NetProfit: subData.SubNetProfit})
 				if err != nil {
 					return fmt.Errorf("failed to create subcategory %s for category %s: %v", subData.SubCategoryName, targetCategory.CategoryName, err)
 				}
@@ -3,7 +3,9 @@ package services
 import (
 	"errors"
 	"strings"
+	"time"
 
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/calculator"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/google/uuid"
@@ -21,19 +23,40 @@ func CreateCareerCategory(categoryName string) (*models.CareerCategory, error) {
 		return nil, errors.New("ชื่อหมวดหมู่อาชีพนี้มีอยู่แล้ว")
 	}
 
+	return createCareerCategory(database.DB, categoryName)
+}
+
+// createCareerCategory creates a category, or restores a soft-deleted one of the same name since
+// category names are unique across deleted rows too
+func createCareerCategory(tx *gorm.DB, categoryName string) (*models.CareerCategory, error) {
+	var deleted models.CareerCategory
+	if err := tx.Unscoped().Where("REPLACE(category_name, ' ', '') = ? AND deleted_at IS NOT NULL", careerNameKey(categoryName)).
+		Limit(1).Find(&deleted).Error; err != nil {
+		return nil, err
+	}
+	if deleted.Id != uuid.Nil {
+		deleted.CategoryName = categoryName
+		deleted.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&deleted).Error; err != nil {
+			return nil, err
+		}
+		return &deleted, nil
+	}
+
 	// Create new category
 	category := models.CareerCategory{
 		CategoryName: categoryName,
 	}
 
-	if err := database.DB.Create(&category).Error; err != nil {
+	if err := tx.Create(&category).Error; err != nil {
 		return nil, err
 	}
 
 	return &category, nil
 }
 
-func GetCareerCategories(categoryNameFilter string, searchQuery string) ([]models.CareerCategory, error) {
+// GetCareerCategories lists the categories with the sub-category margins in effect on asOf
+func GetCareerCategories(categoryNameFilter string, searchQuery string, asOf time.Time) ([]models.CareerCategory, error) {
 	var categories []models.CareerCategory
 
 	query := database.DB.Model(&models.CareerCategory{})
@@ -74,6 +97,12 @@ func GetCareerCategories(categoryNameFilter string, searchQuery string) ([]model
 		return nil, err
 	}
 
+	for i := range categories {
+		if err := applyMarginsAsOf(categories[i].SubCategory, asOf); err != nil {
+			return nil, err
+		}
+	}
+
 	return categories, nil
 }
 
@@ -100,18 +129,85 @@ func UpdateCareerCategory(id uuid.UUID, categoryName string) (*models.CareerCate
 	return &category, nil
 }
 
+// DeleteCareerCategory soft-deletes a category and its sub-categories: they leave the catalogue but
+// evaluations that reference them keep resolving
 func DeleteCareerCategory(id uuid.UUID) error {
-	// Delete subcategories
-	if err := database.DB.Delete(&models.SubCategory{}, "category_id = ?", id).Error; err != nil {
-		return err
+	if !CareerCategoryExists(id) {
+		return errors.New("ไม่พบหมวดหมู่อาชีพ")
 	}
 
-	// Delete category
-	if err := database.DB.Delete(&models.CareerCategory{}, "id = ?", id).Error; err != nil {
-		return err
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// Delete subcategories
+		if err := tx.Delete(&models.SubCategory{}, "category_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		// Delete category
+		return tx.Delete(&models.CareerCategory{}, "id = ?", id).Error
+	})
+}
+
+// GetCareerCategoryUsage counts the cooperative's evaluations and applicants that reference a
+// category and each of its sub-categories, deleted sub-categories included. The catalogue is shared,
+// the counts are not.
+func GetCareerCategoryUsage(cooperativeID string, id uuid.UUID) (*models.CareerCategoryUsage, error) {
+	var category models.CareerCategory
+	if err := database.DB.Unscoped().First(&category, "id = ?", id).Error; err != nil {
+		return nil, errors.New("ไม่พบหมวดหมู่อาชีพ")
 	}
 
-	return nil
+	var subCategories []models.SubCategory
+	if err := database.DB.Unscoped().Where("category_id = ?", id).Order("sub_category_name ASC").Find(&subCategories).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		SubCategoryID *uuid.UUID
+		Evaluates     int64
+		Applicants    int64
+	}
+	if err := database.DB.Model(&models.Applicant{}).
+		Select("sub_category_id, COUNT(DISTINCT evaluate_id) AS evaluates, COUNT(*) AS applicants").
+		Where("career_category_id = ? AND evaluate_id IN (?)", id, cooperativeEvaluates(cooperativeID)).
+		Group("sub_category_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	usage := &models.CareerCategoryUsage{
+		CareerUsage: models.CareerUsage{
+			Id:      category.Id,
+			Name:    category.CategoryName,
+			Deleted: category.DeletedAt.Valid,
+		},
+		SubCategories: []models.CareerUsage{},
+	}
+
+	bySubCategory := map[uuid.UUID]models.CareerUsage{}
+	for _, count := range counts {
+		usage.Applicants += count.Applicants
+		if count.SubCategoryID != nil {
+			bySubCategory[*count.SubCategoryID] = models.CareerUsage{Evaluates: count.Evaluates, Applicants: count.Applicants}
+		}
+	}
+
+	// An evaluate with applicants in several sub-categories is counted once for the category
+	if err := database.DB.Model(&models.Applicant{}).
+		Where("career_category_id = ? AND evaluate_id IN (?)", id, cooperativeEvaluates(cooperativeID)).
+		Distinct("evaluate_id").
+		Count(&usage.Evaluates).Error; err != nil {
+		return nil, err
+	}
+
+	for _, sub := range subCategories {
+		subUsage := bySubCategory[sub.Id]
+		subUsage.Id = sub.Id
+		subUsage.Name = sub.SubCategoryName
+		subUsage.Deleted = sub.DeletedAt.Valid
+		usage.SubCategories = append(usage.SubCategories, subUsage)
+	}
+
+	return usage, nil
 }
 
 func CareerCategoryExists(id uuid.UUID) bool {
@@ -121,23 +217,58 @@ func CareerCategoryExists(id uuid.UUID) bool {
 
 // SubCategory Services
 
-func CreateSubCategory(categoryID uuid.UUID, subCategoryName string, subNetProfit float64) (*models.SubCategory, error) {
+func CreateSubCategory(categoryID uuid.UUID, subCategoryName string, margin MarginVersionInput) (*models.SubCategory, error) {
 	// Check if category exists
 	if !CareerCategoryExists(categoryID) {
 		return nil, errors.New("ไม่พบหมวดหมู่อาชีพ")
 	}
 
-	// Create new subcategory
-	subCategory := models.SubCategory{
-		CategoryID:      categoryID,
-		SubCategoryName: subCategoryName,
-		SubNetProfit:    subNetProfit,
+	// Create new subcategory with its first margin version
+	var subCategory *models.SubCategory
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		subCategory, err = createSubCategory(tx, categoryID, subCategoryName, margin)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := database.DB.Create(&subCategory).Error; err != nil {
+	return subCategory, nil
+}
+
+// createSubCategory creates a sub-category, or restores a soft-deleted one of the same name in the
+// category so its margin history and the evaluations that used it stay attached
+func createSubCategory(tx *gorm.DB, categoryID uuid.UUID, subCategoryName string, margin MarginVersionInput) (*models.SubCategory, error) {
+	var subCategory models.SubCategory
+	if err := tx.Unscoped().
+		Where("category_id = ? AND REPLACE(sub_category_name, ' ', '') = ? AND deleted_at IS NOT NULL", categoryID, careerNameKey(subCategoryName)).
+		Limit(1).Find(&subCategory).Error; err != nil {
 		return nil, err
 	}
 
+	if subCategory.Id != uuid.Nil {
+		subCategory.SubCategoryName = subCategoryName
+		subCategory.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&subCategory).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		subCategory = models.SubCategory{
+			CategoryID:      categoryID,
+			SubCategoryName: subCategoryName,
+			SubNetProfit:    margin.NetProfit,
+		}
+		if err := tx.Create(&subCategory).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := addSubCategoryMargin(tx, &subCategory, margin)
+	if err != nil {
+		return nil, err
+	}
+	subCategory.MarginID = &version.Id
 	return &subCategory, nil
 }
 
@@ -162,10 +293,16 @@ func GetSubCategoriesByCategoryID(categoryID uuid.UUID, page, limit int, search
 		return nil, 0, err
 	}
 
+	if err := applyMarginsAsOf(subCategories, time.Now()); err != nil {
+		return nil, 0, err
+	}
+
 	return subCategories, total, nil
 }
 
-func UpdateSubCategory(id uuid.UUID, categoryID uuid.UUID, subCategoryName string, subNetProfit float64) (*models.SubCategory, error) {
+// UpdateSubCategory renames or moves a sub-category; a changed margin is recorded as a new version
+// effective from margin.ValidFrom instead of overwriting the old one
+func UpdateSubCategory(id uuid.UUID, categoryID uuid.UUID, subCategoryName string, margin MarginVersionInput) (*models.SubCategory, error) {
 	var subCategory models.SubCategory
 	if err := database.DB.First(&subCategory, "id = ?", id).Error; err != nil {
 		return nil, errors.New("ไม่พบหมวดหมู่ย่อยอาชีพ")
@@ -184,17 +321,41 @@ func UpdateSubCategory(id uuid.UUID, categoryID uuid.UUID, subCategoryName strin
 		return nil, errors.New("ชื่อหมวดหมู่ย่อยอาชีพนี้มีอยู่แล้ว")
 	}
 
+	// Margin in effect on the date the new one would start
+	validFrom := margin.ValidFrom
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	current := []models.SubCategory{subCategory}
+	if err := applyMarginsAsOf(current, validFrom); err != nil {
+		return nil, err
+	}
+
 	// Update subcategory
-	subCategory.CategoryID = categoryID
-	subCategory.SubCategoryName = subCategoryName
-	subCategory.SubNetProfit = subNetProfit
-	if err := database.DB.Save(&subCategory).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		subCategory.CategoryID = categoryID
+		subCategory.SubCategoryName = subCategoryName
+		if err := tx.Save(&subCategory).Error; err != nil {
+			return err
+		}
+		if current[0].SubNetProfit == margin.NetProfit {
+			return nil
+		}
+		_, err := addSubCategoryMargin(tx, &subCategory, margin)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &subCategory, nil
+	result := []models.SubCategory{subCategory}
+	if err := applyMarginsAsOf(result, time.Now()); err != nil {
+		return nil, err
+	}
+	return &result[0], nil
 }
 
+// DeleteSubCategory soft-deletes a sub-category; evaluations that reference it keep resolving
 func DeleteSubCategory(id uuid.UUID) error {
 	// Delete subcategory
 	if err := database.DB.Delete(&models.SubCategory{}, "id = ?", id).Error; err != nil {
@@ -208,3 +369,138 @@ func SubCategoryExists(id uuid.UUID) bool {
 	var subCategory models.SubCategory
 	return database.DB.First(&subCategory, "id = ?", id).Error == nil
 }
+
+// careerMargin is the margin a career resolves to on a given date
+type careerMargin struct {
+	ID    *uuid.UUID // nil when the sub-category has no version covering the date
+	Value float64
+}
+
+func careerMarginKey(careerCategory string, career string) string {
+	return careerCategory + "\x00" + career
+}
+
+// resolveApplicantCareers links each applicant to the catalogue. IDs sent by the client are kept when
+// they exist, so renamed or soft-deleted careers still resolve; otherwise the names are matched,
+// preferring nodes that are not deleted. Unknown careers (e.g. "อื่นๆ") are left unlinked.
+func resolveApplicantCareers(db *gorm.DB, applicants []models.ApplicantRequest) error {
+	var categories []models.CareerCategory
+	if err := db.Unscoped().Order("deleted_at DESC NULLS FIRST").Find(&categories).Error; err != nil {
+		return err
+	}
+	var subCategories []models.SubCategory
+	if err := db.Unscoped().Order("deleted_at DESC NULLS FIRST").Find(&subCategories).Error; err != nil {
+		return err
+	}
+
+	categoryByID := map[uuid.UUID]models.CareerCategory{}
+	categoryByName := map[string]models.CareerCategory{}
+	for _, category := range categories {
+		categoryByID[category.Id] = category
+		if _, ok := categoryByName[careerNameKey(category.CategoryName)]; !ok {
+			categoryByName[careerNameKey(category.CategoryName)] = category
+		}
+	}
+	subCategoryByID := map[uuid.UUID]models.SubCategory{}
+	subCategoryByName := map[string]models.SubCategory{}
+	for _, sub := range subCategories {
+		subCategoryByID[sub.Id] = sub
+		key := sub.CategoryID.String() + "\x00" + careerNameKey(sub.SubCategoryName)
+		if _, ok := subCategoryByName[key]; !ok {
+			subCategoryByName[key] = sub
+		}
+	}
+
+	for i := range applicants {
+		applicant := &applicants[i]
+
+		if applicant.SubCategoryID != nil {
+			if sub, ok := subCategoryByID[*applicant.SubCategoryID]; ok {
+				categoryID := sub.CategoryID
+				applicant.CareerCategoryID = &categoryID
+				continue
+			}
+		}
+		applicant.SubCategoryID = nil
+
+		category, ok := models.CareerCategory{}, false
+		if applicant.CareerCategoryID != nil {
+			category, ok = categoryByID[*applicant.CareerCategoryID]
+		}
+		if !ok {
+			category, ok = categoryByName[careerNameKey(applicant.CareerCategory)]
+		}
+		if !ok {
+			applicant.CareerCategoryID = nil
+			continue
+		}
+		categoryID := category.Id
+		applicant.CareerCategoryID = &categoryID
+
+		if sub, ok := subCategoryByName[category.Id.String()+"\x00"+careerNameKey(applicant.Career)]; ok {
+			subCategoryID := sub.Id
+			applicant.SubCategoryID = &subCategoryID
+		}
+	}
+
+	return nil
+}
+
+// careerMarginsAsOf loads the margin of every sub-category, deleted ones included, effective on asOf
+// keyed by sub-category ID. Sub-categories without a version on that date fall back to SubNetProfit.
+func careerMarginsAsOf(asOf time.Time) (map[uuid.UUID]careerMargin, error) {
+	var rows []struct {
+		Id           uuid.UUID
+		SubNetProfit float64
+		MarginID     *uuid.UUID
+		NetProfit    *float64
+	}
+
+	day := marginDate(asOf).Format("2006-01-02")
+	if err := database.DB.Unscoped().Model(&models.SubCategory{}).
+		Select("sub_categories.id, sub_categories.sub_net_profit, sub_category_margins.id AS margin_id, sub_category_margins.net_profit").
+		Joins("LEFT JOIN sub_category_margins ON sub_category_margins.sub_category_id = sub_categories.id "+
+			"AND sub_category_margins.valid_from <= ?::date "+
+			"AND (sub_category_margins.valid_to IS NULL OR sub_category_margins.valid_to > ?::date)", day, day).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	margins := make(map[uuid.UUID]careerMargin, len(rows))
+	for _, row := range rows {
+		margin := careerMargin{Value: row.SubNetProfit}
+		if row.MarginID != nil && row.NetProfit != nil {
+			margin = careerMargin{ID: row.MarginID, Value: *row.NetProfit}
+		}
+		margins[row.Id] = margin
+	}
+	return margins, nil
+}
+
+// applicantMargins resolves the applicants' careers and returns the margins in effect on asOf keyed by
+// the category and career names each applicant carries
+func applicantMargins(applicants []models.ApplicantRequest, asOf time.Time) (map[string]careerMargin, error) {
+	if err := resolveApplicantCareers(database.DB, applicants); err != nil {
+		return nil, err
+	}
+
+	margins, err := careerMarginsAsOf(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]careerMargin{}
+	for _, applicant := range applicants {
+		if applicant.SubCategoryID != nil {
+			byName[careerMarginKey(applicant.CareerCategory, applicant.Career)] = margins[*applicant.SubCategoryID]
+		}
+	}
+	return byName, nil
+}
+
+// careerMarginLookup adapts resolved margins to the calculator
+func careerMarginLookup(margins map[string]careerMargin) calculator.MarginLookup {
+	return func(careerCategory string, career string) float64 {
+		return margins[careerMarginKey(careerCategory, career)].Value
+	}
+}
@@ -0,0 +1,630 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCareerImportUnsupportedFormat = errors.New("รองรับเฉพาะไฟล์ .csv .xlsx และ .json")
+	ErrCareerImportEmptyFile         = errors.New("ไม่พบข้อมูลอาชีพในไฟล์")
+	ErrCareerImportHasErrors         = errors.New("พบข้อมูลไม่ถูกต้อง ยังไม่ได้บันทึกตารางอาชีพ")
+	ErrCareerImportFailed            = errors.New("ไม่สามารถนำเข้าตารางอาชีพได้")
+)
+
+const (
+	CareerImportAdded     = "added"
+	CareerImportChanged   = "changed"
+	CareerImportUnchanged = "unchanged"
+	CareerImportRemoved   = "removed"
+	CareerImportError     = "error"
+)
+
+// CareerTableCategory is the category → sub-category → margin tree used by the JSON export and import
+type CareerTableCategory struct {
+	CategoryName  string                   `json:"categoryName"`
+	SubCategories []CareerTableSubCategory `json:"subCategories"`
+}
+
+type CareerTableSubCategory struct {
+	SubCategoryName string  `json:"subCategoryName"`
+	NetProfit       float64 `json:"netProfit"`
+	ValidFrom       string  `json:"validFrom,omitempty"` // YYYY-MM-DD
+	SourceRef       string  `json:"sourceRef,omitempty"`
+}
+
+// careerTableHeader is the flat layout of the CSV/XLSX export, one row per sub-category
+var careerTableHeader = []string{"categoryName", "subCategoryName", "netProfit", "validFrom", "sourceRef"}
+
+// careerImportColumns maps the Thai headers a policy sheet may use to the flat layout
+var careerImportColumns = map[string]string{
+	"หมวดหมู่อาชีพ":     "categoryName",
+	"หมวดอาชีพ":         "categoryName",
+	"อาชีพ":             "subCategoryName",
+	"หมวดหมู่ย่อยอาชีพ": "subCategoryName",
+	"อัตรากำไรสุทธิ":    "netProfit",
+	"อัตรากำไรสุทธิ(%)": "netProfit",
+	"subNetProfit":  "netProfit",
+	"วันที่มีผล":    "validFrom",
+	"แหล่งที่มา":    "sourceRef",
+	"เอกสารอ้างอิง": "sourceRef",
+}
+
+// CareerImportChange is the difference one row (or one missing sub-category) makes to the table
+type CareerImportChange struct {
+	Row             int      `json:"row,omitempty"` // row number in the file, header = 1; 0 for removals
+	CategoryName    string   `json:"categoryName"`
+	SubCategoryName string   `json:"subCategoryName"`
+	OldNetProfit    *float64 `json:"oldNetProfit,omitempty"`
+	NetProfit       *float64 `json:"netProfit,omitempty"`
+	ValidFrom       string   `json:"validFrom,omitempty"`
+	Action          string   `json:"action"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+type CareerImportReport struct {
+	DryRun        bool                 `json:"dryRun"`
+	RemoveMissing bool                 `json:"removeMissing"`
+	Total         int                  `json:"total"`
+	Added         int                  `json:"added"`
+	Changed       int                  `json:"changed"`
+	Unchanged     int                  `json:"unchanged"`
+	Removed       int                  `json:"removed"`
+	Failed        int                  `json:"failed"`
+	Changes       []CareerImportChange `json:"changes"`
+}
+
+// CareerImportOptions controls how an imported table is applied
+type CareerImportOptions struct {
+	DryRun        bool
+	RemoveMissing bool      // delete sub-categories (and categories) that are not in the file
+	EffectiveDate time.Time // validFrom of rows that do not carry one; zero = today
+}
+
+func careerNameKey(name string) string {
+	return strings.ReplaceAll(name, " ", "")
+}
+
+// careerTableRow is one sub-category of the import, or a category without sub-categories
+type careerTableRow struct {
+	row             int
+	categoryName    string
+	subCategoryName string
+	netProfit       float64
+	validFrom       time.Time
+	sourceRef       string
+	errors          []string
+}
+
+// ExportCareerTable builds the category tree with the margin in effect on asOf
+func ExportCareerTable(asOf time.Time) ([]CareerTableCategory, error) {
+	var categories []models.CareerCategory
+	if err := database.DB.Preload("SubCategory", func(db *gorm.DB) *gorm.DB { return db.Order("sub_category_name ASC") }).
+		Order("category_name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var versions []models.SubCategoryMargin
+	if err := effectiveMarginQuery(database.DB, asOf).Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	bySubCategory := make(map[uuid.UUID]models.SubCategoryMargin, len(versions))
+	for _, version := range versions {
+		bySubCategory[version.SubCategoryID] = version
+	}
+
+	table := make([]CareerTableCategory, 0, len(categories))
+	for _, category := range categories {
+		entry := CareerTableCategory{CategoryName: category.CategoryName, SubCategories: []CareerTableSubCategory{}}
+		for _, sub := range category.SubCategory {
+			row := CareerTableSubCategory{SubCategoryName: sub.SubCategoryName, NetProfit: sub.SubNetProfit}
+			if version, ok := bySubCategory[sub.Id]; ok {
+				row.NetProfit = version.NetProfit
+				row.ValidFrom = version.ValidFrom.Format("2006-01-02")
+				row.SourceRef = version.SourceRef
+			}
+			entry.SubCategories = append(entry.SubCategories, row)
+		}
+		table = append(table, entry)
+	}
+
+	return table, nil
+}
+
+// careerTableRecords flattens the tree in careerTableHeader order; a category without
+// sub-categories is kept as a row with only its name
+func careerTableRecords(table []CareerTableCategory) [][]interface{} {
+	var rows [][]interface{}
+	for _, category := range table {
+		if len(category.SubCategories) == 0 {
+			rows = append(rows, []interface{}{category.CategoryName, "", "", "", ""})
+		}
+		for _, sub := range category.SubCategories {
+			rows = append(rows, []interface{}{category.CategoryName, sub.SubCategoryName, sub.NetProfit, sub.ValidFrom, sub.SourceRef})
+		}
+	}
+	return rows
+}
+
+// WriteCareerTableJSON writes the category tree as JSON
+func WriteCareerTableJSON(w io.Writer, asOf time.Time) error {
+	table, err := ExportCareerTable(asOf)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(table)
+}
+
+// WriteCareerTableCSV writes the career table as CSV, one row per sub-category
+func WriteCareerTableCSV(w io.Writer, asOf time.Time) error {
+	table, err := ExportCareerTable(asOf)
+	if err != nil {
+		return err
+	}
+
+	// UTF-8 BOM so Excel opens Thai text correctly
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(careerTableHeader); err != nil {
+		return err
+	}
+	for _, row := range careerTableRecords(table) {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = fmt.Sprint(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteCareerTableXLSX writes the career table into an Excel sheet, one row per sub-category
+func WriteCareerTableXLSX(w io.Writer, asOf time.Time) error {
+	table, err := ExportCareerTable(asOf)
+	if err != nil {
+		return err
+	}
+
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Careers"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	headerRow := make([]interface{}, len(careerTableHeader))
+	for i, title := range careerTableHeader {
+		headerRow[i] = title
+	}
+	if err := file.SetSheetRow(sheet, "A1", &headerRow); err != nil {
+		return err
+	}
+
+	for i, row := range careerTableRecords(table) {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := file.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
+
+// ImportCareerTable diffs a CSV/XLSX/JSON career table against the database and, unless
+// DryRun, applies every change in a single transaction. Nothing is written when any row is invalid.
+func ImportCareerTable(filename string, file io.Reader, options CareerImportOptions) (*CareerImportReport, error) {
+	effectiveDate := options.EffectiveDate
+	if effectiveDate.IsZero() {
+		effectiveDate = time.Now()
+	}
+	effectiveDate = marginDate(effectiveDate)
+
+	rows, err := readCareerTableRows(filename, file, effectiveDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrCareerImportEmptyFile
+	}
+
+	var categories []models.CareerCategory
+	if err := database.DB.Preload("SubCategory").Order("category_name ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCareerImportFailed, err)
+	}
+	var versions []models.SubCategoryMargin
+	if err := database.DB.Order("valid_from ASC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCareerImportFailed, err)
+	}
+	var usedMarginIDs []uuid.UUID
+	if err := database.DB.Model(&models.Applicant{}).Distinct("margin_id").Where("margin_id IS NOT NULL").
+		Pluck("margin_id", &usedMarginIDs).Error; err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCareerImportFailed, err)
+	}
+
+	report, plan := diffCareerTable(rows, categories, versions, usedMarginIDs, options.RemoveMissing)
+	report.DryRun = options.DryRun
+
+	if report.Failed > 0 || options.DryRun {
+		if report.Failed > 0 && !options.DryRun {
+			return report, ErrCareerImportHasErrors
+		}
+		return report, nil
+	}
+
+	if err := database.DB.Transaction(plan.apply); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCareerImportFailed, err)
+	}
+
+	return report, nil
+}
+
+// readCareerTableRows reads the file into rows; rows without a validFrom take effectiveDate
+func readCareerTableRows(filename string, file io.Reader, effectiveDate time.Time) ([]careerTableRow, error) {
+	var records [][]string
+
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		var table []CareerTableCategory
+		if err := json.NewDecoder(file).Decode(&table); err != nil {
+			return nil, fmt.Errorf("อ่านไฟล์ JSON ไม่สำเร็จ: %v", err)
+		}
+		records = [][]string{careerTableHeader}
+		for _, row := range careerTableRecords(table) {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = fmt.Sprint(value)
+			}
+			records = append(records, record)
+		}
+	} else {
+		var err error
+		records, err = readImportRecords(filename, file)
+		if errors.Is(err, ErrImportUnsupportedFormat) {
+			return nil, ErrCareerImportUnsupportedFormat
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(records) < 2 {
+		return nil, ErrCareerImportEmptyFile
+	}
+
+	columns, err := careerTableColumns(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []careerTableRow
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+
+		cell := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		row := careerTableRow{
+			row:             i + 2,
+			categoryName:    cell("categoryName"),
+			subCategoryName: cell("subCategoryName"),
+			sourceRef:       cell("sourceRef"),
+			validFrom:       effectiveDate,
+		}
+
+		if row.categoryName == "" {
+			row.errors = append(row.errors, "กรุณาระบุหมวดหมู่อาชีพ")
+		}
+
+		if row.subCategoryName != "" {
+			value := strings.TrimSuffix(strings.ReplaceAll(cell("netProfit"), ",", ""), "%")
+			netProfit, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			switch {
+			case err != nil:
+				row.errors = append(row.errors, "อัตรากำไรสุทธิไม่ถูกต้อง")
+			case netProfit < 0 || netProfit > 100:
+				row.errors = append(row.errors, "อัตรากำไรสุทธิต้องอยู่ระหว่าง 0-100%")
+			default:
+				row.netProfit = netProfit
+			}
+
+			if validFrom := cell("validFrom"); validFrom != "" {
+				normalized, err := normalizeImportDate(validFrom)
+				parsed, parseErr := time.Parse("2006-01-02", normalized)
+				if err != nil || parseErr != nil {
+					row.errors = append(row.errors, "รูปแบบวันที่มีผลไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)")
+				} else {
+					row.validFrom = parsed
+				}
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// careerTableColumns maps each column index to a field of the flat layout
+func careerTableColumns(header []string) (map[string]int, error) {
+	fields := map[string]string{}
+	for _, field := range careerTableHeader {
+		fields[strings.ToLower(field)] = field
+	}
+	for title, field := range careerImportColumns {
+		fields[strings.ToLower(careerNameKey(title))] = field
+	}
+
+	columns := map[string]int{}
+	for i, title := range header {
+		key := strings.ToLower(careerNameKey(strings.TrimSpace(title)))
+		if field, ok := fields[key]; ok {
+			if _, exists := columns[field]; !exists {
+				columns[field] = i
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range []string{"categoryName", "subCategoryName", "netProfit"} {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("ไม่พบคอลัมน์ที่จำเป็นในไฟล์: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// careerMarginChange is a new margin version for an existing sub-category
+type careerMarginChange struct {
+	subCategory *models.SubCategory
+	row         careerTableRow
+}
+
+// careerImportPlan is the set of writes an import makes, applied in one transaction
+type careerImportPlan struct {
+	newCategories    []string
+	newSubCategories []careerTableRow
+	newMargins       []careerMarginChange
+	removeSubIDs     []uuid.UUID
+	removeCatIDs     []uuid.UUID
+}
+
+func (plan *careerImportPlan) apply(tx *gorm.DB) error {
+	categoryIDs := map[string]uuid.UUID{}
+	var existing []models.CareerCategory
+	if err := tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	for _, category := range existing {
+		categoryIDs[careerNameKey(category.CategoryName)] = category.Id
+	}
+
+	for _, name := range plan.newCategories {
+		category, err := createCareerCategory(tx, name)
+		if err != nil {
+			return fmt.Errorf("failed to create category %s: %v", name, err)
+		}
+		categoryIDs[careerNameKey(name)] = category.Id
+	}
+
+	for _, row := range plan.newSubCategories {
+		input := MarginVersionInput{NetProfit: row.netProfit, ValidFrom: row.validFrom, SourceRef: row.sourceRef}
+		if _, err := createSubCategory(tx, categoryIDs[careerNameKey(row.categoryName)], row.subCategoryName, input); err != nil {
+			return fmt.Errorf("failed to create subcategory %s: %v", row.subCategoryName, err)
+		}
+	}
+
+	for _, change := range plan.newMargins {
+		row := change.row
+		input := MarginVersionInput{NetProfit: row.netProfit, ValidFrom: row.validFrom, SourceRef: row.sourceRef}
+		if _, err := addSubCategoryMargin(tx, change.subCategory, input); err != nil {
+			return fmt.Errorf("failed to record margin of %s: %v", row.subCategoryName, err)
+		}
+	}
+
+	if len(plan.removeSubIDs) > 0 {
+		if err := tx.Delete(&models.SubCategory{}, "id IN ?", plan.removeSubIDs).Error; err != nil {
+			return err
+		}
+	}
+	if len(plan.removeCatIDs) > 0 {
+		if err := tx.Delete(&models.CareerCategory{}, "id IN ?", plan.removeCatIDs).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marginOn returns the version of a sub-category in effect on day, if any; versions are sorted by validFrom
+func marginOn(versions []models.SubCategoryMargin, day time.Time) *models.SubCategoryMargin {
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := versions[i]
+		if !marginDate(version.ValidFrom).After(day) && (version.ValidTo == nil || marginDate(*version.ValidTo).After(day)) {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+// diffCareerTable compares the imported rows with the stored tree and tallies the report
+func diffCareerTable(rows []careerTableRow, categories []models.CareerCategory, versions []models.SubCategoryMargin,
+	usedMarginIDs []uuid.UUID, removeMissing bool) (*CareerImportReport, *careerImportPlan) {
+	report := &CareerImportReport{RemoveMissing: removeMissing, Changes: []CareerImportChange{}}
+	plan := &careerImportPlan{}
+
+	used := map[uuid.UUID]bool{}
+	for _, id := range usedMarginIDs {
+		used[id] = true
+	}
+	versionsBySub := map[uuid.UUID][]models.SubCategoryMargin{}
+	for _, version := range versions {
+		versionsBySub[version.SubCategoryID] = append(versionsBySub[version.SubCategoryID], version)
+	}
+
+	categoryByName := map[string]*models.CareerCategory{}
+	subByName := map[string]*models.SubCategory{}
+	for i := range categories {
+		category := &categories[i]
+		categoryByName[careerNameKey(category.CategoryName)] = category
+		for j := range category.SubCategory {
+			sub := &category.SubCategory[j]
+			subByName[careerNameKey(category.CategoryName)+"\x00"+careerNameKey(sub.SubCategoryName)] = sub
+		}
+	}
+
+	seenCategories := map[string]bool{}
+	seenSubs := map[string]int{}
+	newCategories := map[string]bool{}
+
+	for _, row := range rows {
+		change := CareerImportChange{
+			Row:             row.row,
+			CategoryName:    row.categoryName,
+			SubCategoryName: row.subCategoryName,
+			Errors:          row.errors,
+		}
+		categoryKey := careerNameKey(row.categoryName)
+		subKey := categoryKey + "\x00" + careerNameKey(row.subCategoryName)
+
+		if row.subCategoryName != "" {
+			if first, ok := seenSubs[subKey]; ok {
+				change.Errors = append(change.Errors, fmt.Sprintf("อาชีพซ้ำกับแถวที่ %d", first))
+			} else {
+				seenSubs[subKey] = row.row
+			}
+		}
+
+		if len(change.Errors) > 0 {
+			change.Action = CareerImportError
+			report.Failed++
+			report.Changes = append(report.Changes, change)
+			continue
+		}
+
+		seenCategories[categoryKey] = true
+		if _, exists := categoryByName[categoryKey]; !exists && !newCategories[categoryKey] {
+			newCategories[categoryKey] = true
+			plan.newCategories = append(plan.newCategories, row.categoryName)
+			if row.subCategoryName == "" {
+				change.Action = CareerImportAdded
+				report.Added++
+				report.Changes = append(report.Changes, change)
+				continue
+			}
+		}
+
+		if row.subCategoryName == "" {
+			change.Action = CareerImportUnchanged
+			report.Unchanged++
+			report.Changes = append(report.Changes, change)
+			continue
+		}
+
+		netProfit := row.netProfit
+		change.NetProfit = &netProfit
+		change.ValidFrom = row.validFrom.Format("2006-01-02")
+
+		sub, exists := subByName[subKey]
+		if !exists {
+			change.Action = CareerImportAdded
+			report.Added++
+			plan.newSubCategories = append(plan.newSubCategories, row)
+			report.Changes = append(report.Changes, change)
+			continue
+		}
+
+		current := marginOn(versionsBySub[sub.Id], row.validFrom)
+		oldNetProfit := sub.SubNetProfit
+		if current != nil {
+			oldNetProfit = current.NetProfit
+		}
+		change.OldNetProfit = &oldNetProfit
+
+		switch {
+		case oldNetProfit == row.netProfit:
+			change.Action = CareerImportUnchanged
+			report.Unchanged++
+		case current != nil && marginDate(current.ValidFrom).Equal(row.validFrom) && used[current.Id]:
+			change.Action = CareerImportError
+			change.Errors = append(change.Errors, ErrMarginVersionInUse.Error())
+			report.Failed++
+		default:
+			change.Action = CareerImportChanged
+			report.Changed++
+			plan.newMargins = append(plan.newMargins, careerMarginChange{subCategory: sub, row: row})
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	report.Total = len(rows)
+
+	// Sub-categories and categories the file no longer has
+	for i := range categories {
+		category := &categories[i]
+		categoryKey := careerNameKey(category.CategoryName)
+		keepsAny := false
+		for j := range category.SubCategory {
+			sub := &category.SubCategory[j]
+			if _, ok := seenSubs[categoryKey+"\x00"+careerNameKey(sub.SubCategoryName)]; ok {
+				keepsAny = true
+				continue
+			}
+			netProfit := sub.SubNetProfit
+			report.Changes = append(report.Changes, CareerImportChange{
+				CategoryName:    category.CategoryName,
+				SubCategoryName: sub.SubCategoryName,
+				OldNetProfit:    &netProfit,
+				Action:          CareerImportRemoved,
+			})
+			report.Removed++
+			plan.removeSubIDs = append(plan.removeSubIDs, sub.Id)
+		}
+		if !keepsAny && !seenCategories[categoryKey] {
+			report.Changes = append(report.Changes, CareerImportChange{CategoryName: category.CategoryName, Action: CareerImportRemoved})
+			report.Removed++
+			plan.removeCatIDs = append(plan.removeCatIDs, category.Id)
+		}
+	}
+
+	// Removals are only reported unless the caller asked for them
+	if !removeMissing {
+		plan.removeSubIDs, plan.removeCatIDs = nil, nil
+	}
+
+	return report, plan
+}
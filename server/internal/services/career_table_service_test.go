@@ -0,0 +1,301 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+var careerEffectiveDate = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestCareerTableColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		want    map[string]int
+		missing string
+	}{
+		{
+			"export layout",
+			careerTableHeader,
+			map[string]int{"categoryName": 0, "subCategoryName": 1, "netProfit": 2, "validFrom": 3, "sourceRef": 4},
+			"",
+		},
+		{
+			"thai policy sheet",
+			[]string{"หมวดหมู่อาชีพ", "อาชีพ", "อัตรากำไรสุทธิ (%)", "วันที่มีผล", "เอกสารอ้างอิง"},
+			map[string]int{"categoryName": 0, "subCategoryName": 1, "netProfit": 2, "validFrom": 3, "sourceRef": 4},
+			"",
+		},
+		{
+			// The first of two aliases wins
+			"aliases",
+			[]string{"หมวดอาชีพ", "หมวดหมู่ย่อยอาชีพ", "SubNetProfit", "อาชีพ"},
+			map[string]int{"categoryName": 0, "subCategoryName": 1, "netProfit": 2},
+			"",
+		},
+		{"missing columns", []string{"หมวดหมู่อาชีพ", "วันที่มีผล"}, nil, "subCategoryName, netProfit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := careerTableColumns(tt.header)
+			if tt.missing != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.missing) {
+					t.Fatalf("careerTableColumns() error = %v, want missing %s", err, tt.missing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for field, index := range tt.want {
+				if got, ok := columns[field]; !ok || got != index {
+					t.Errorf("column of %s = %v (%v), want %v", field, got, ok, index)
+				}
+			}
+		})
+	}
+}
+
+func TestReadCareerTableRows(t *testing.T) {
+	file := strings.Join([]string{
+		"หมวดหมู่อาชีพ,อาชีพ,อัตรากำไรสุทธิ (%),วันที่มีผล,แหล่งที่มา",
+		"เกษตรกรรม,ทำนา,20,,ประกาศ 1/2568",
+		"เกษตรกรรม,ทำสวน,35.5%,1/7/2568,",
+		"เกษตรกรรม,เลี้ยงสัตว์,ไม่ทราบ,,",
+		"เกษตรกรรม,ประมง,120,,",
+		"เกษตรกรรม,ทำไร่,10,ไม่ระบุ,",
+		",,,,",
+		"บริการ,,,,",
+		",ตัดผม,40,,",
+	}, "\n")
+
+	rows, err := readCareerTableRows("careers.csv", strings.NewReader(file), careerEffectiveDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		row       int
+		sub       string
+		netProfit float64
+		validFrom string
+		errText   string
+	}{
+		// No validFrom takes the effective date
+		{2, "ทำนา", 20, "2025-01-01", ""},
+		// Buddhist-era dates and percent signs are accepted
+		{3, "ทำสวน", 35.5, "2025-07-01", ""},
+		{4, "เลี้ยงสัตว์", 0, "2025-01-01", "อัตรากำไรสุทธิไม่ถูกต้อง"},
+		{5, "ประมง", 0, "2025-01-01", "อัตรากำไรสุทธิต้องอยู่ระหว่าง 0-100%"},
+		{6, "ทำไร่", 10, "2025-01-01", "รูปแบบวันที่มีผลไม่ถูกต้อง"},
+		// The blank row is skipped; a category alone needs no margin
+		{8, "", 0, "2025-01-01", ""},
+		{9, "ตัดผม", 40, "2025-01-01", "กรุณาระบุหมวดหมู่อาชีพ"},
+	}
+
+	if len(rows) != len(tests) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(tests))
+	}
+	for i, tt := range tests {
+		row := rows[i]
+		if row.row != tt.row || row.subCategoryName != tt.sub || row.netProfit != tt.netProfit || row.validFrom.Format("2006-01-02") != tt.validFrom {
+			t.Errorf("row %d = %+v, want %+v", i, row, tt)
+		}
+		if got := strings.Join(row.errors, ","); (tt.errText == "") != (got == "") || !strings.Contains(got, tt.errText) {
+			t.Errorf("row %d errors = %q, want %q", tt.row, got, tt.errText)
+		}
+	}
+	if rows[0].sourceRef != "ประกาศ 1/2568" {
+		t.Errorf("source = %q", rows[0].sourceRef)
+	}
+}
+
+func TestReadCareerTableRowsJSON(t *testing.T) {
+	file := `[{"categoryName":"เกษตรกรรม","subCategories":[{"subCategoryName":"ทำนา","netProfit":20,"validFrom":"2024-06-01"},{"subCategoryName":"ทำสวน","netProfit":35}]},{"categoryName":"บริการ","subCategories":[]}]`
+
+	rows, err := readCareerTableRows("careers.json", strings.NewReader(file), careerEffectiveDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"เกษตรกรรม/ทำนา/20/2024-06-01", "เกษตรกรรม/ทำสวน/35/2025-01-01", "บริการ//0/2025-01-01"}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		got := strings.Join([]string{row.categoryName, row.subCategoryName, strconv.FormatFloat(row.netProfit, 'f', -1, 64), row.validFrom.Format("2006-01-02")}, "/")
+		if got != want[i] || len(row.errors) > 0 {
+			t.Errorf("row %d = %s %v, want %s", i, got, row.errors, want[i])
+		}
+	}
+}
+
+// storedCareerTable is the catalogue the imports below are compared with:
+//
+//	เกษตรกรรม: ทำนา 20% (since 2024-01-01), ทำสวน 30%, เลี้ยงสัตว์ 15%
+//	ค้าขาย: ขายของชำ 10%
+type storedCareerTable struct {
+	categories []models.CareerCategory
+	versions   []models.SubCategoryMargin
+	rice       models.SubCategoryMargin
+}
+
+func newStoredCareerTable() storedCareerTable {
+	farming, trade := uuid.New(), uuid.New()
+	rice, orchard, livestock, grocery := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	table := storedCareerTable{
+		categories: []models.CareerCategory{
+			{Id: farming, CategoryName: "เกษตรกรรม", SubCategory: []models.SubCategory{
+				{Id: rice, CategoryID: farming, SubCategoryName: "ทำนา", SubNetProfit: 20},
+				{Id: orchard, CategoryID: farming, SubCategoryName: "ทำสวน", SubNetProfit: 30},
+				{Id: livestock, CategoryID: farming, SubCategoryName: "เลี้ยงสัตว์", SubNetProfit: 15},
+			}},
+			{Id: trade, CategoryName: "ค้าขาย", SubCategory: []models.SubCategory{
+				{Id: grocery, CategoryID: trade, SubCategoryName: "ขายของชำ", SubNetProfit: 10},
+			}},
+		},
+		rice: models.SubCategoryMargin{Id: uuid.New(), SubCategoryID: rice, NetProfit: 20, ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	table.versions = []models.SubCategoryMargin{table.rice}
+	return table
+}
+
+func careerRow(row int, category string, sub string, netProfit float64) careerTableRow {
+	return careerTableRow{row: row, categoryName: category, subCategoryName: sub, netProfit: netProfit, validFrom: careerEffectiveDate}
+}
+
+func TestDiffCareerTable(t *testing.T) {
+	stored := newStoredCareerTable()
+	rows := []careerTableRow{
+		careerRow(2, "เกษตรกรรม", "ทำนา", 20),
+		careerRow(3, "เกษตรกรรม", "ทำสวน", 35),
+		// Names match whatever their spacing
+		careerRow(4, "เกษตร กรรม", "ประมง", 25),
+		careerRow(5, "บริการ", "ตัดผม", 40),
+		careerRow(6, "บริการ", "ซ่อมรถ", 30),
+		careerRow(7, "เกษตรกรรม", "ทำ นา", 22),
+		careerRow(8, "ขนส่ง", "", 0),
+	}
+
+	type change struct {
+		category string
+		sub      string
+		action   string
+		old      float64
+	}
+	want := []change{
+		{"เกษตรกรรม", "ทำนา", CareerImportUnchanged, 20},
+		{"เกษตรกรรม", "ทำสวน", CareerImportChanged, 30},
+		{"เกษตร กรรม", "ประมง", CareerImportAdded, 0},
+		{"บริการ", "ตัดผม", CareerImportAdded, 0},
+		{"บริการ", "ซ่อมรถ", CareerImportAdded, 0},
+		{"เกษตรกรรม", "ทำ นา", CareerImportError, 0},
+		{"ขนส่ง", "", CareerImportAdded, 0},
+		// Missing from the file
+		{"เกษตรกรรม", "เลี้ยงสัตว์", CareerImportRemoved, 15},
+		{"ค้าขาย", "ขายของชำ", CareerImportRemoved, 10},
+		{"ค้าขาย", "", CareerImportRemoved, 0},
+	}
+
+	report, plan := diffCareerTable(rows, stored.categories, stored.versions, nil, true)
+
+	if len(report.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(report.Changes), len(want), report.Changes)
+	}
+	for i, w := range want {
+		got := report.Changes[i]
+		old := 0.0
+		if got.OldNetProfit != nil {
+			old = *got.OldNetProfit
+		}
+		if got.CategoryName != w.category || got.SubCategoryName != w.sub || got.Action != w.action || old != w.old {
+			t.Errorf("change %d = %s/%s %s (old %v), want %s/%s %s (old %v)", i, got.CategoryName, got.SubCategoryName, got.Action, old, w.category, w.sub, w.action, w.old)
+		}
+	}
+	if !strings.Contains(strings.Join(report.Changes[5].Errors, ","), "อาชีพซ้ำกับแถวที่ 2") {
+		t.Errorf("duplicate errors = %v", report.Changes[5].Errors)
+	}
+
+	if report.Total != 7 || report.Added != 4 || report.Changed != 1 || report.Unchanged != 1 || report.Removed != 3 || report.Failed != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	// A new category is created once, before its sub-categories
+	if strings.Join(plan.newCategories, ",") != "บริการ,ขนส่ง" {
+		t.Errorf("new categories = %v", plan.newCategories)
+	}
+	var newSubs []string
+	for _, row := range plan.newSubCategories {
+		newSubs = append(newSubs, row.subCategoryName)
+	}
+	if strings.Join(newSubs, ",") != "ประมง,ตัดผม,ซ่อมรถ" {
+		t.Errorf("new sub-categories = %v", newSubs)
+	}
+	if len(plan.newMargins) != 1 || plan.newMargins[0].subCategory.SubCategoryName != "ทำสวน" || plan.newMargins[0].row.netProfit != 35 {
+		t.Errorf("new margins = %+v", plan.newMargins)
+	}
+	if len(plan.removeSubIDs) != 2 || len(plan.removeCatIDs) != 1 || plan.removeCatIDs[0] != stored.categories[1].Id {
+		t.Errorf("removals = %v, %v", plan.removeSubIDs, plan.removeCatIDs)
+	}
+}
+
+func TestDiffCareerTableKeepsMissingUnlessAsked(t *testing.T) {
+	stored := newStoredCareerTable()
+	rows := []careerTableRow{careerRow(2, "เกษตรกรรม", "ทำนา", 20)}
+
+	report, plan := diffCareerTable(rows, stored.categories, stored.versions, nil, false)
+
+	// Still reported, so the dry run shows what removeMissing would do
+	if report.Removed != 4 {
+		t.Errorf("removed = %d, want 4", report.Removed)
+	}
+	if len(plan.removeSubIDs) != 0 || len(plan.removeCatIDs) != 0 {
+		t.Errorf("plan removes %v, %v without removeMissing", plan.removeSubIDs, plan.removeCatIDs)
+	}
+}
+
+func TestDiffCareerTableMarginVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		validFrom time.Time
+		used      bool
+		action    string
+		old       float64
+	}{
+		{"new version", careerEffectiveDate, true, CareerImportChanged, 20},
+		// Replacing the version evaluations were saved with would change them
+		{"replaces a used version", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), true, CareerImportError, 20},
+		{"replaces an unused version", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false, CareerImportChanged, 20},
+		// Before the first version the stored margin is the baseline
+		{"before the first version", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), true, CareerImportChanged, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := newStoredCareerTable()
+			var used []uuid.UUID
+			if tt.used {
+				used = append(used, stored.rice.Id)
+			}
+			row := careerRow(2, "เกษตรกรรม", "ทำนา", 25)
+			row.validFrom = tt.validFrom
+
+			report, plan := diffCareerTable([]careerTableRow{row}, stored.categories, stored.versions, used, false)
+
+			got := report.Changes[0]
+			if got.Action != tt.action || got.OldNetProfit == nil || *got.OldNetProfit != tt.old {
+				t.Errorf("change = %+v", got)
+			}
+			if tt.action == CareerImportError && (len(plan.newMargins) != 0 || !strings.Contains(strings.Join(got.Errors, ","), ErrMarginVersionInUse.Error())) {
+				t.Errorf("used version: errors %v, plan %+v", got.Errors, plan.newMargins)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCooperativeNotFound  = errors.New("ไม่พบสหกรณ์")
+	ErrCooperativeExists    = errors.New("เลขทะเบียนสหกรณ์นี้มีอยู่แล้ว")
+	ErrCooperativeForbidden = errors.New("ไม่มีสิทธิ์เข้าถึงข้อมูลของสหกรณ์นี้")
+	ErrCooperativeMismatch  = errors.New("ข้อมูลต้องเป็นของสหกรณ์ที่กำลังทำงานอยู่")
+	ErrNoCooperative        = errors.New("บัญชีนี้ยังไม่ได้เลือกสหกรณ์ กรุณาเลือกสหกรณ์ก่อนใช้งาน")
+)
+
+// cooperativeScope limits a query on a tenant-owned table to one cooperative. AuthMiddleware turns
+// away sessions without a cooperative before they reach tenant data.
+func cooperativeScope(cooperativeID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("cooperative_id = ?", cooperativeID)
+	}
+}
+
+// cooperativeEvaluates selects the IDs of the cooperative's evaluates, for scoping tables that hang
+// off an evaluate
+func cooperativeEvaluates(cooperativeID string) *gorm.DB {
+	return database.DB.Model(&models.Evaluate{}).Select("id").Scopes(cooperativeScope(cooperativeID))
+}
+
+func GetCooperatives() ([]models.Cooperative, error) {
+	var cooperatives []models.Cooperative
+	if err := database.DB.Order("name ASC").Find(&cooperatives).Error; err != nil {
+		return nil, err
+	}
+	return cooperatives, nil
+}
+
+func GetCooperativeByID(id string) (*models.Cooperative, error) {
+	var cooperative models.Cooperative
+	if err := database.DB.Where("id = ?", id).Limit(1).Find(&cooperative).Error; err != nil {
+		return nil, err
+	}
+	if cooperative.Id == "" {
+		return nil, ErrCooperativeNotFound
+	}
+	return &cooperative, nil
+}
+
+func CreateCooperative(request *models.CooperativeRequest) (*models.Cooperative, error) {
+	if _, err := GetCooperativeByID(request.Id); err == nil {
+		return nil, ErrCooperativeExists
+	} else if !errors.Is(err, ErrCooperativeNotFound) {
+		return nil, err
+	}
+
+	cooperative := models.Cooperative{
+		Id:   request.Id,
+		Name: strings.TrimSpace(request.Name),
+	}
+	if err := database.DB.Create(&cooperative).Error; err != nil {
+		return nil, err
+	}
+
+	return &cooperative, nil
+}
+
+func UpdateCooperative(id string, request *models.CooperativeRequest) (*models.Cooperative, error) {
+	cooperative, err := GetCooperativeByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cooperative.Name = strings.TrimSpace(request.Name)
+	cooperative.UpdatedAt = time.Now()
+	if err := database.DB.Save(cooperative).Error; err != nil {
+		return nil, err
+	}
+
+	return cooperative, nil
+}
+
+// GetAdminCooperatives lists the cooperatives an admin may work in: every cooperative with
+// tenant:switch, otherwise the assigned ones
+func GetAdminCooperatives(adminID uuid.UUID) ([]models.Cooperative, error) {
+	var admin models.Admin
+	if err := database.DB.Preload("Cooperatives", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).
+		Where("id = ?", adminID).First(&admin).Error; err != nil {
+		return nil, err
+	}
+
+	switchAny, err := AdminCan(&admin, models.PermissionTenantSwitch)
+	if err != nil {
+		return nil, err
+	}
+	if switchAny {
+		return GetCooperatives()
+	}
+	return admin.Cooperatives, nil
+}
+
+// canAccessCooperative reports whether the admin may work in the cooperative
+func canAccessCooperative(admin *models.Admin, cooperativeID string) (bool, error) {
+	switchAny, err := AdminCan(admin, models.PermissionTenantSwitch)
+	if err != nil || switchAny {
+		return switchAny, err
+	}
+
+	var count int64
+	if err := database.DB.Table("admin_cooperatives").
+		Where("admin_id = ? AND cooperative_id = ?", admin.Id, cooperativeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// defaultCooperative is the cooperative a new session starts in: the first assigned one by
+// registration number, for tenant:switch admins without an assignment the first cooperative, or none
+func defaultCooperative(admin *models.Admin) (string, error) {
+	var cooperativeIDs []string
+	if err := database.DB.Table("admin_cooperatives").
+		Where("admin_id = ?", admin.Id).
+		Order("cooperative_id ASC").Limit(1).
+		Pluck("cooperative_id", &cooperativeIDs).Error; err != nil {
+		return "", err
+	}
+	if len(cooperativeIDs) > 0 {
+		return cooperativeIDs[0], nil
+	}
+
+	switchAny, err := AdminCan(admin, models.PermissionTenantSwitch)
+	if err != nil || !switchAny {
+		return "", err
+	}
+	if err := database.DB.Model(&models.Cooperative{}).
+		Order("id ASC").Limit(1).
+		Pluck("id", &cooperativeIDs).Error; err != nil {
+		return "", err
+	}
+	if len(cooperativeIDs) == 0 {
+		return "", nil
+	}
+	return cooperativeIDs[0], nil
+}
+
+// SetAdminCooperatives replaces the cooperatives an admin is assigned to. A changed assignment signs
+// the admin out everywhere so no session keeps working in a cooperative that was taken away.
+func SetAdminCooperatives(adminID uuid.UUID, cooperativeIDs []string) (*models.Admin, error) {
+	var admin models.Admin
+	if err := database.DB.Preload("Cooperatives").Where("id = ?", adminID).Limit(1).Find(&admin).Error; err != nil {
+		return nil, err
+	}
+	if admin.Id == uuid.Nil {
+		return nil, ErrAdminNotFound
+	}
+
+	requested := map[string]bool{}
+	for _, id := range cooperativeIDs {
+		requested[strings.TrimSpace(id)] = true
+	}
+	ids := make([]string, 0, len(requested))
+	for id := range requested {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var cooperatives []models.Cooperative
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ?", ids).Order("id ASC").Find(&cooperatives).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(cooperatives) != len(ids) {
+		return nil, ErrCooperativeNotFound
+	}
+
+	current := make([]string, 0, len(admin.Cooperatives))
+	for _, cooperative := range admin.Cooperatives {
+		current = append(current, cooperative.Id)
+	}
+	sort.Strings(current)
+	if strings.Join(current, ",") == strings.Join(ids, ",") {
+		return &admin, nil
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		association := tx.Model(&admin).Association("Cooperatives")
+		if len(cooperatives) == 0 {
+			if err := association.Clear(); err != nil {
+				return err
+			}
+		} else if err := association.Replace(cooperatives); err != nil {
+			return err
+		}
+		return invalidateAdminTokens(tx, admin.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	admin.Cooperatives = cooperatives
+	return &admin, nil
+}
+
+// SwitchCooperative moves the caller's session to another cooperative
+func SwitchCooperative(adminID uuid.UUID, sessionID uuid.UUID, cooperativeID string) (*models.Cooperative, error) {
+	cooperative, err := GetCooperativeByID(cooperativeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var admin models.Admin
+	if err := database.DB.Where("id = ?", adminID).First(&admin).Error; err != nil {
+		return nil, err
+	}
+
+	allowed, err := canAccessCooperative(&admin, cooperative.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCooperativeForbidden
+	}
+
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND admin_id = ? AND revoked_at IS NULL", sessionID, adminID).
+		Update("cooperative_id", cooperative.Id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSessionInvalid
+	}
+
+	return cooperative, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// assertScoped fails the test for every statement on the tables that is not limited to the cooperative
+func assertScoped(t *testing.T, db *fakeDB, cooperativeID string, tables ...string) {
+	t.Helper()
+	if unscoped := db.unscoped(t, cooperativeID, tables...); len(unscoped) != 0 {
+		t.Errorf("statements not limited to cooperative %s:\n%s", cooperativeID, strings.Join(unscoped, "\n"))
+	}
+}
+
+func TestMemberReadsScopedToCooperative(t *testing.T) {
+	db := useFakeDB(t)
+	db.permissions = []string{models.PermissionEvaluateReadAll}
+	id := uuid.New()
+
+	if _, err := GetMemberByID(ownerCooperative, id); err != nil {
+		t.Errorf("GetMemberByID(): %v", err)
+	}
+	if _, err := GetMemberByIDCard(ownerCooperative, fakeIDCard); err != nil {
+		t.Errorf("GetMemberByIDCard(): %v", err)
+	}
+	if _, _, err := GetMembersWithFiltersAndPagination(ownerCooperative, "สมาชิก", "", "", "", 1, 10); err != nil {
+		t.Errorf("GetMembersWithFiltersAndPagination(): %v", err)
+	}
+	if _, err := SearchMembersByName(ownerCooperative, "สมาชิก"); err != nil {
+		t.Errorf("SearchMembersByName(): %v", err)
+	}
+	if _, err := GetMemberEvaluates(ownerCooperative, id, uuid.New()); err != nil {
+		t.Errorf("GetMemberEvaluates(): %v", err)
+	}
+	if err := StreamMembers(MemberFilter{CooperativeID: ownerCooperative}, func([]models.Member) error { return nil }); err != nil {
+		t.Errorf("StreamMembers(): %v", err)
+	}
+
+	assertScoped(t, db, ownerCooperative, "members", "evaluates")
+}
+
+func TestMemberWritesStayInCooperative(t *testing.T) {
+	db := useFakeDB(t)
+	id := uuid.New()
+
+	if _, err := UpdateMember(id, otherCooperative, fakeIDCard, "2567", "M0001", "ชื่อใหม่", "ไทย",
+		1, 100, time.Now(), 1, time.Time{}, "", 1, "", "", ""); err == nil {
+		t.Error("UpdateMember() updated another cooperative's member")
+	}
+	if err := DeleteMember(otherCooperative, id); err == nil {
+		t.Error("DeleteMember() deleted another cooperative's member")
+	}
+
+	if writes := db.writes(); len(writes) != 0 {
+		t.Errorf("statements written across cooperatives: %v", writes)
+	}
+}
+
+func TestEvaluateReadsScopedToCooperative(t *testing.T) {
+	db := useFakeDB(t)
+	db.permissions = []string{models.PermissionEvaluateReadAll}
+	id, readerID := uuid.New(), uuid.New()
+
+	if _, err := GetEvaluateByID(ownerCooperative, id, readerID); err != nil {
+		t.Errorf("GetEvaluateByID(): %v", err)
+	}
+	if _, _, err := GetEvaluates(ownerCooperative, "ผู้กู้", uuid.Nil, 1, 10); err != nil {
+		t.Errorf("GetEvaluates(): %v", err)
+	}
+	// Found, but the stored evaluate has no loan terms
+	if _, err := GetEvaluateSchedule(ownerCooperative, id, readerID); !errors.Is(err, ErrNoLoanTerms) {
+		t.Errorf("GetEvaluateSchedule(): %v", err)
+	}
+	if err := StreamEvaluates(EvaluateExportFilter{CooperativeID: ownerCooperative}, func([]models.Evaluate) error { return nil }); err != nil {
+		t.Errorf("StreamEvaluates(): %v", err)
+	}
+	if _, err := GetEvaluateRevisions(ownerCooperative, id, readerID); err != nil {
+		t.Errorf("GetEvaluateRevisions(): %v", err)
+	}
+	if _, err := GetEvaluateRevision(ownerCooperative, id, 1, readerID); err != nil {
+		t.Errorf("GetEvaluateRevision(): %v", err)
+	}
+	if _, err := GetEvaluateStatusHistory(ownerCooperative, id, readerID); err != nil {
+		t.Errorf("GetEvaluateStatusHistory(): %v", err)
+	}
+
+	assertScoped(t, db, ownerCooperative, "evaluates", "evaluate_revisions", "evaluate_status_histories")
+}
+
+func TestEvaluateWritesStayInCooperative(t *testing.T) {
+	db := useFakeDB(t)
+	id, userID := uuid.New(), uuid.New()
+
+	if _, err := UpdateEvaluate(otherCooperative, id, userID, &models.EvaluateRequest{}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateEvaluate() of another cooperative's evaluate = %v, want not found", err)
+	}
+	if err := DeleteEvaluate(otherCooperative, id, userID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteEvaluate() of another cooperative's evaluate = %v, want not found", err)
+	}
+	if _, err := TransitionEvaluateStatus(otherCooperative, id, userID, models.EvaluateStatusSubmitted, ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("TransitionEvaluateStatus() of another cooperative's evaluate = %v, want not found", err)
+	}
+
+	if writes := db.writes(); len(writes) != 0 {
+		t.Errorf("statements written across cooperatives: %v", writes)
+	}
+}
+
+func TestDashboardScopedToCooperative(t *testing.T) {
+	db := useFakeDB(t)
+
+	if _, err := GetKPIDashboard(ownerCooperative, "2567", "ในเมือง"); err != nil {
+		t.Errorf("GetKPIDashboard(): %v", err)
+	}
+	if _, err := GetMembershipGrowth(ownerCooperative); err != nil {
+		t.Errorf("GetMembershipGrowth(): %v", err)
+	}
+	if _, err := GetMembershipCountBySubdistrict(ownerCooperative, "2567"); err != nil {
+		t.Errorf("GetMembershipCountBySubdistrict(): %v", err)
+	}
+	if _, err := GetSharesDistribution(ownerCooperative, "2567", "ในเมือง"); err != nil {
+		t.Errorf("GetSharesDistribution(): %v", err)
+	}
+	if _, err := GetDashboardSummary(ownerCooperative); err != nil {
+		t.Errorf("GetDashboardSummary(): %v", err)
+	}
+
+	assertScoped(t, db, ownerCooperative, "members", "evaluates")
+}
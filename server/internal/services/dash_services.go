@@ -10,19 +10,45 @@ import (
 	"gorm.io/gorm"
 )
 
+// GetDashboardSummary returns the headline figures of one cooperative for its signed-in admins
+func GetDashboardSummary(cooperativeID string) (*models.DashboardSummaryResponse, error) {
+	var totalMembers int64
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Count(&totalMembers).Error; err != nil {
+		return nil, err
+	}
+
+	var totalEvaluations int64
+	if err := database.DB.Model(&models.Evaluate{}).Scopes(cooperativeScope(cooperativeID)).Count(&totalEvaluations).Error; err != nil {
+		return nil, err
+	}
+
+	var totalSharesRaw float64
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).
+		Select("COALESCE(SUM(shares_value), 0)").
+		Scan(&totalSharesRaw).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.DashboardSummaryResponse{
+		TotalMembers:     totalMembers,
+		TotalEvaluations: totalEvaluations,
+		TotalShares:      int64(totalSharesRaw),
+	}, nil
+}
+
 // KPI Dashboard Services
 
-func GetKPIDashboard(accountYear, subdistrict string) (*models.KPIDashboardResponse, error) {
+func GetKPIDashboard(cooperativeID, accountYear, subdistrict string) (*models.KPIDashboardResponse, error) {
 
 	// TODO: Implement KPI dashboard logic
 	// 1. Get total members
-	totalMembers, err := GetTotalMembers(accountYear, subdistrict)
+	totalMembers, err := GetTotalMembers(cooperativeID, accountYear, subdistrict)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total members: %w", err)
 	}
 
 	// 2. Get total shares amount
-	totalShares, err := GetTotalShares(accountYear, subdistrict)
+	totalShares, err := GetTotalShares(cooperativeID, accountYear, subdistrict)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total shares: %w", err)
 	}
@@ -31,7 +57,7 @@ func GetKPIDashboard(accountYear, subdistrict string) (*models.KPIDashboardRespo
 	averageShares := GetAverageSharesPerPerson(totalShares, totalMembers)
 
 	// 4. Members of this year
-	membersOfThisYear, err := GetMembersOfThisYear()
+	membersOfThisYear, err := GetMembersOfThisYear(cooperativeID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get members of this year: %w", err)
 	}
@@ -44,9 +70,9 @@ func GetKPIDashboard(accountYear, subdistrict string) (*models.KPIDashboardRespo
 	}, nil
 }
 
-func GetTotalMembers(accountYear, subdistrict string) (int64, error) {
+func GetTotalMembers(cooperativeID, accountYear, subdistrict string) (int64, error) {
 	// TODO: Implement logic to get total members
-	query := database.DB.Model(&models.Member{})
+	query := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID))
 
 	// Apply filters
 	if accountYear != "" {
@@ -64,9 +90,9 @@ func GetTotalMembers(accountYear, subdistrict string) (int64, error) {
 	return count, nil
 }
 
-func GetTotalShares(accountYear, subdistrict string) (float64, error) {
+func GetTotalShares(cooperativeID, accountYear, subdistrict string) (float64, error) {
 	// TODO: Implement logic to get total shares
-	query := database.DB.Model(&models.Member{})
+	query := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID))
 
 	// Apply filters
 	if accountYear != "" {
@@ -92,7 +118,7 @@ func GetAverageSharesPerPerson(totalShares float64, totalMembers int64) float64
 	return math.Round(avg*100) / 100
 }
 
-func GetMembersOfThisYear() (models.MembersThisYearStats, error) {
+func GetMembersOfThisYear(cooperativeID string) (models.MembersThisYearStats, error) {
 	// TODO: Implement logic to get members of this year
 	now := time.Now()
 	currentYear := now.Format("2006")
@@ -101,14 +127,14 @@ func GetMembersOfThisYear() (models.MembersThisYearStats, error) {
 	var currentCount, lastCount int64
 
 	// 1. Get Current Year Count
-	if err := database.DB.Model(&models.Member{}).
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).
 		Where("EXTRACT(YEAR FROM joining_date) = ?", currentYear).
 		Count(&currentCount).Error; err != nil {
 		return models.MembersThisYearStats{}, err
 	}
 
 	// 2. Get Last Year Count
-	if err := database.DB.Model(&models.Member{}).
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).
 		Where("EXTRACT(YEAR FROM joining_date) = ?", lastYear).
 		Count(&lastCount).Error; err != nil {
 		return models.MembersThisYearStats{}, err
@@ -123,9 +149,9 @@ func GetMembersOfThisYear() (models.MembersThisYearStats, error) {
 
 // Chart Dashboard Services
 
-func GetMembershipGrowthChart() (*models.MembershipGrowthDataResponse, error) {
+func GetMembershipGrowthChart(cooperativeID string) (*models.MembershipGrowthDataResponse, error) {
 	// TODO: Implement logic to get membership growth chart
-	data, err := GetMembershipGrowth()
+	data, err := GetMembershipGrowth(cooperativeID)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +160,7 @@ func GetMembershipGrowthChart() (*models.MembershipGrowthDataResponse, error) {
 	}, nil
 }
 
-func GetMembershipGrowth() ([]models.MembershipGrowthData, error) {
+func GetMembershipGrowth(cooperativeID string) ([]models.MembershipGrowthData, error) {
 	// TODO: Implement logic to get membership growth
 	var growthData []models.MembershipGrowthData
 
@@ -143,21 +169,21 @@ func GetMembershipGrowth() ([]models.MembershipGrowthData, error) {
 			EXTRACT(YEAR FROM m.joining_date)::int + 543 as year, 
 			COUNT(*) as count
 		FROM members m 
-		WHERE m.joining_date IS NOT NULL
+		WHERE m.joining_date IS NOT NULL AND m.cooperative_id = ?
 		GROUP BY EXTRACT(YEAR FROM m.joining_date)
 		ORDER BY year ASC
 	`
 
-	if err := database.DB.Raw(query).Scan(&growthData).Error; err != nil {
+	if err := database.DB.Raw(query, cooperativeID).Scan(&growthData).Error; err != nil {
 		return nil, err
 	}
 
 	return growthData, nil
 }
 
-func GetMembershipCountBySubdistrictChart(accountYear string) (*models.MembershipCountBySubdistrictDataResponse, error) {
+func GetMembershipCountBySubdistrictChart(cooperativeID, accountYear string) (*models.MembershipCountBySubdistrictDataResponse, error) {
 	// TODO: Implement logic to get membership count by subdistrict chart
-	data, err := GetMembershipCountBySubdistrict(accountYear)
+	data, err := GetMembershipCountBySubdistrict(cooperativeID, accountYear)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +192,9 @@ func GetMembershipCountBySubdistrictChart(accountYear string) (*models.Membershi
 	}, nil
 }
 
-func GetMembershipCountBySubdistrict(accountYear string) ([]models.MembershipCountBySubdistrictData, error) {
+func GetMembershipCountBySubdistrict(cooperativeID, accountYear string) ([]models.MembershipCountBySubdistrictData, error) {
 	// TODO: Implement logic to get membership count by subdistrict
-	query := database.DB.Model(&models.Member{})
+	query := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID))
 
 	// Apply filters
 	if accountYear != "" {
@@ -190,9 +216,9 @@ func GetMembershipCountBySubdistrict(accountYear string) ([]models.MembershipCou
 	// Calculate total for percentage
 	var total int64
 	if accountYear != "" {
-		total, _ = GetTotalMembers(accountYear, "")
+		total, _ = GetTotalMembers(cooperativeID, accountYear, "")
 	} else {
-		total, _ = GetTotalMembers("", "")
+		total, _ = GetTotalMembers(cooperativeID, "", "")
 	}
 	// Convert to response format with percentage
 	var subdistrictData []models.MembershipCountBySubdistrictData
@@ -215,9 +241,9 @@ func GetMembershipCountBySubdistrict(accountYear string) ([]models.MembershipCou
 
 // Shares Distribution Services
 
-func GetSharesDistributionChart(accountYear, subdistrict string) (*models.SharesDistributionResponse, error) {
+func GetSharesDistributionChart(cooperativeID, accountYear, subdistrict string) (*models.SharesDistributionResponse, error) {
 	// TODO: Implement logic to get shares distribution chart
-	data, err := GetSharesDistribution(accountYear, subdistrict)
+	data, err := GetSharesDistribution(cooperativeID, accountYear, subdistrict)
 	if err != nil {
 		return nil, err
 	}
@@ -226,9 +252,9 @@ func GetSharesDistributionChart(accountYear, subdistrict string) (*models.Shares
 	}, nil
 }
 
-func GetSharesDistribution(accountYear, subdistrict string) ([]models.SharesDistributionData, error) {
+func GetSharesDistribution(cooperativeID, accountYear, subdistrict string) ([]models.SharesDistributionData, error) {
 	// TODO: Implement logic to get shares distribution
-	query := database.DB.Model(&models.Member{})
+	query := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID))
 
 	// Apply filters
 	if accountYear != "" {
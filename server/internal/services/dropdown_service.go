@@ -6,17 +6,17 @@ import (
 )
 
 // Dropdown Services
-func GetFullDropdown() (*models.FullDropdown, error) {
+func GetFullDropdown(cooperativeID string) (*models.FullDropdown, error) {
 	var subdistricts []string
-	if err := database.DB.Model(&models.Member{}).Distinct("subdistrict").Pluck("subdistrict", &subdistricts).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Distinct("subdistrict").Pluck("subdistrict", &subdistricts).Error; err != nil {
 		return nil, err
 	}
 	var districts []string
-	if err := database.DB.Model(&models.Member{}).Distinct("district").Pluck("district", &districts).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Distinct("district").Pluck("district", &districts).Error; err != nil {
 		return nil, err
 	}
 	var provinces []string
-	if err := database.DB.Model(&models.Member{}).Distinct("province").Pluck("province", &provinces).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Distinct("province").Pluck("province", &provinces).Error; err != nil {
 		return nil, err
 	}
 	return &models.FullDropdown{
@@ -26,25 +26,25 @@ func GetFullDropdown() (*models.FullDropdown, error) {
 	}, nil
 }
 
-func GetSubDistricts() ([]string, error) {
+func GetSubDistricts(cooperativeID string) ([]string, error) {
 	var subdistricts []string
-	if err := database.DB.Model(&models.Member{}).Distinct("subdistrict").Pluck("subdistrict", &subdistricts).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Distinct("subdistrict").Pluck("subdistrict", &subdistricts).Error; err != nil {
 		return nil, err
 	}
 	return subdistricts, nil
 }
 
-func GetDistricts() ([]string, error) {
+func GetDistricts(cooperativeID string) ([]string, error) {
 	var districts []string
-	if err := database.DB.Model(&models.Member{}).Distinct("district").Pluck("district", &districts).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Distinct("district").Pluck("district", &districts).Error; err != nil {
 		return nil, err
 	}
 	return districts, nil
 }
 
-func GetProvinces() ([]string, error) {
+func GetProvinces(cooperativeID string) ([]string, error) {
 	var provinces []string
-	if err := database.DB.Model(&models.Member{}).Distinct("province").Pluck("province", &provinces).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Distinct("province").Pluck("province", &provinces).Error; err != nil {
 		return nil, err
 	}
 	return provinces, nil
@@ -0,0 +1,22 @@
+package services
+
+import "testing"
+
+func TestDropdownScopedToCooperative(t *testing.T) {
+	db := useFakeDB(t)
+
+	if _, err := GetFullDropdown(ownerCooperative); err != nil {
+		t.Errorf("GetFullDropdown(): %v", err)
+	}
+	if _, err := GetSubDistricts(ownerCooperative); err != nil {
+		t.Errorf("GetSubDistricts(): %v", err)
+	}
+	if _, err := GetDistricts(ownerCooperative); err != nil {
+		t.Errorf("GetDistricts(): %v", err)
+	}
+	if _, err := GetProvinces(ownerCooperative); err != nil {
+		t.Errorf("GetProvinces(): %v", err)
+	}
+
+	assertScoped(t, db, ownerCooperative, "members")
+}
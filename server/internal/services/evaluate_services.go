@@ -1,16 +1,90 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/calculator"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.Evaluate, error) {
+var ErrEvaluateNotFound = errors.New("ไม่พบข้อมูลการประเมิน")
+
+// applicantOrder keeps applicants and their results in the order they were entered, the main
+// borrower first
+func applicantOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, created_at ASC")
+}
+
+// CalculateEvaluateRequest recomputes every derived total, DTI and DSCR of the
+// request from its raw inputs so client-side values are never trusted, then
+// checks the result against the matching approval policy. Applicants are matched with the members of
+// the cooperative.
+func CalculateEvaluateRequest(cooperativeID string, request *models.EvaluateRequest) error {
+	margins, err := applicantMargins(request.Applicants, time.Now())
+	if err != nil {
+		return err
+	}
+
+	policy, err := GetPolicyFor(cooperativeID, request.EvaluateType, request.MarginType)
+	if err != nil {
+		return err
+	}
+
+	clientDti, clientDscr := request.Result.Dti, request.Result.Dscr
+
+	// With loan terms the installment of this loan is derived, not typed by hand
+	if request.LoanTerms.Principal > 0 {
+		request.Result.DebtDetail.DebtAmount = calculator.Installment(request.LoanTerms)
+	}
+
+	calculator.CalculateEvaluate(request, careerMarginLookup(margins))
+
+	// Record the margin version each applicant was evaluated with so the result can be reproduced
+	for i := range request.Applicants {
+		applicant := &request.Applicants[i]
+		margin := margins[careerMarginKey(applicant.CareerCategory, applicant.Career)]
+		value := margin.Value
+		applicant.MarginID, applicant.MarginValue = margin.ID, &value
+	}
+
+	// Share-backed credit limit of the main borrower
+	var borrower *models.Member
+	if len(request.Applicants) > 0 {
+		borrower = findMemberByIDCard(database.DB, cooperativeID, request.Applicants[0].IDCard)
+	}
+	request.Result.ShareCredit = calculator.ShareCreditLimit(borrower, request.LoanTerms.Principal, policy)
+
+	request.Result.Recommendation = calculator.Recommend(request.Result, policy)
+
+	if math.Abs(clientDti-request.Result.Dti) > 0.01 || math.Abs(clientDscr-request.Result.Dscr) > 0.01 {
+		log.Printf("Evaluate totals recalculated: client DTI=%.2f DSCR=%.2f, server DTI=%.2f DSCR=%.2f",
+			clientDti, clientDscr, request.Result.Dti, request.Result.Dscr)
+	}
+
+	return nil
+}
+
+// PreviewEvaluate computes the evaluate result for the request without persisting anything
+func PreviewEvaluate(cooperativeID string, request *models.EvaluateRequest) (*models.EvaluateResultRequest, error) {
+	if err := CalculateEvaluateRequest(cooperativeID, request); err != nil {
+		return nil, err
+	}
+	return &request.Result, nil
+}
+
+func CreateEvaluate(cooperativeID string, userID uuid.UUID, request *models.EvaluateRequest) (*models.Evaluate, error) {
+	// Recalculate derived values before persisting
+	if err := CalculateEvaluateRequest(cooperativeID, request); err != nil {
+		return nil, err
+	}
+
 	// Start transaction
 	tx := database.DB.Begin()
 	defer func() {
@@ -32,11 +106,12 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 	}
 
 	evaluateLog := models.EvaluateLog{
-		Action:    fmt.Sprintf("สร้างแบบประเมินของ %s", mainBorrowerName),
-		Username:  admin.Username,
-		FullName:  admin.FullName,
-		Role:      admin.Role,
-		Timestamp: time.Now(),
+		CooperativeID: cooperativeID,
+		Action:        fmt.Sprintf("สร้างแบบประเมินของ %s", mainBorrowerName),
+		Username:      admin.Username,
+		FullName:      admin.FullName,
+		Role:          admin.Role,
+		Timestamp:     time.Now(),
 	}
 	if err := tx.Create(&evaluateLog).Error; err != nil {
 		tx.Rollback()
@@ -45,11 +120,14 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 
 	// Create new evaluate first (without associations for now)
 	evaluate := models.Evaluate{
-		UserID:       userID,
-		EvaluateType: request.EvaluateType,
-		MarginType:   request.MarginType,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		CooperativeID: cooperativeID,
+		UserID:        userID,
+		EvaluateType:  request.EvaluateType,
+		MarginType:    request.MarginType,
+		LoanTerms:     request.LoanTerms,
+		Status:        models.EvaluateStatusDraft,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Create the main evaluate record first to get the ID
@@ -60,17 +138,23 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 	}
 
 	// Now create applicants with the evaluate ID
-	for _, applicantReq := range request.Applicants {
+	for i, applicantReq := range request.Applicants {
 		applicant := models.Applicant{
 			EvaluateID:  evaluate.Id,
 			Id:          uuid.New(), // Generate new ID for the applicant
 			ApplicantID: uuid.New(), // Generate new ApplicantID
+			Position:    i,
 			// Copy all borrower data fields
 			CareerCategory:       applicantReq.CareerCategory,
 			Career:               applicantReq.Career,
 			OtherCareer:          applicantReq.OtherCareer,
+			CareerCategoryID:     applicantReq.CareerCategoryID,
+			SubCategoryID:        applicantReq.SubCategoryID,
 			Name:                 applicantReq.Name,
 			IDCard:               applicantReq.IDCard,
+			MemberID:             resolveApplicantMember(tx, cooperativeID, applicantReq.IDCard),
+			MarginID:             applicantReq.MarginID,
+			MarginValue:          applicantReq.MarginValue,
 			BusinessActivity:     applicantReq.BusinessActivity,
 			ExpenseItem:          applicantReq.ExpenseItem,
 			ProfileLost:          applicantReq.ProfileLost,
@@ -93,13 +177,15 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 
 	// Create result with evaluate ID
 	result := models.EvaluateResult{
-		EvaluateID:   evaluate.Id,
-		EvaluateType: request.EvaluateType,
-		DebtDetail:   request.Result.DebtDetail,
-		Dti:          request.Result.Dti,
-		Dscr:         request.Result.Dscr,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		EvaluateID:     evaluate.Id,
+		EvaluateType:   request.EvaluateType,
+		DebtDetail:     request.Result.DebtDetail,
+		Dti:            request.Result.Dti,
+		Dscr:           request.Result.Dscr,
+		ShareCredit:    request.Result.ShareCredit,
+		Recommendation: request.Result.Recommendation,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
 	if err := tx.Create(&result).Error; err != nil {
@@ -108,10 +194,11 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 	}
 
 	// Create result applicants
-	for _, resultApplicantReq := range request.Result.Applicants {
+	for i, resultApplicantReq := range request.Result.Applicants {
 		resultApplicant := models.ResultApplicant{
 			EvaluateID:             result.EvaluateID,
 			ResultID:               result.Id,
+			Position:               i,
 			Name:                   resultApplicantReq.Name,
 			IDCard:                 resultApplicantReq.IDCard,
 			Salary:                 resultApplicantReq.Salary,
@@ -137,13 +224,19 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 		}
 	}
 
+	// Snapshot the first revision
+	if err := createEvaluateRevision(tx, evaluate.Id, userID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
 	// Reload the evaluate with all associations
-	if err := database.DB.Preload("Applicants").Preload("Result").Preload("Result.Applicants").
+	if err := database.DB.Preload("Applicants", applicantOrder).Preload("Result").Preload("Result.Applicants", applicantOrder).
 		Where("id = ?", evaluate.Id).First(&evaluate).Error; err != nil {
 		return nil, err
 	}
@@ -151,14 +244,13 @@ func CreateEvaluate(userID uuid.UUID, request *models.EvaluateRequest) (*models.
 	return &evaluate, nil
 }
 
-func GetEvaluates(search string, userID uuid.UUID, page int, limit int) ([]models.Evaluate, int64, error) {
-	var evaluates []models.Evaluate
-	var total int64
-	query := database.DB.Model(&models.Evaluate{}).Preload("Applicants").Preload("Result").Preload("Result.Applicants").Preload("User")
+// evaluateFilterQuery applies the cooperative, user and free-text filters shared by the evaluate list and export
+func evaluateFilterQuery(query *gorm.DB, cooperativeID string, search string, userID uuid.UUID) *gorm.DB {
+	query = query.Where("evaluates.cooperative_id = ?", cooperativeID)
 
 	// Apply user filter if provided
 	if userID != uuid.Nil {
-		query = query.Where("user_id = ?", userID)
+		query = query.Where("evaluates.user_id = ?", userID)
 	}
 
 	// Apply search filter if provided
@@ -180,6 +272,15 @@ func GetEvaluates(search string, userID uuid.UUID, page int, limit int) ([]model
 			Group("evaluates.id")
 	}
 
+	return query
+}
+
+func GetEvaluates(cooperativeID string, search string, userID uuid.UUID, page int, limit int) ([]models.Evaluate, int64, error) {
+	var evaluates []models.Evaluate
+	var total int64
+	query := database.DB.Model(&models.Evaluate{}).Preload("Applicants", applicantOrder).Preload("Result").Preload("Result.Applicants", applicantOrder).Preload("User")
+	query = evaluateFilterQuery(query, cooperativeID, search, userID)
+
 	// Get total count with filters
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -187,41 +288,105 @@ func GetEvaluates(search string, userID uuid.UUID, page int, limit int) ([]model
 
 	// Get paginated data with filters
 	offset := (page - 1) * limit
-	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&evaluates).Error; err != nil {
+	if err := query.Order("evaluates.created_at DESC").Offset(offset).Limit(limit).Find(&evaluates).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return evaluates, total, nil
 }
 
-func GetEvaluateByEvaluateID(evaluateID uuid.UUID, userID uuid.UUID) (*models.Evaluate, error) {
+func GetEvaluateByEvaluateID(cooperativeID string, evaluateID uuid.UUID, userID uuid.UUID) (*models.Evaluate, error) {
 	var evaluate models.Evaluate
-	if err := database.DB.Preload("Applicants").Preload("Result").Preload("Result.Applicants").
+	if err := database.DB.Preload("Applicants", applicantOrder).Preload("Result").Preload("Result.Applicants", applicantOrder).Scopes(cooperativeScope(cooperativeID)).
 		Where("id = ? AND user_id = ?", evaluateID, userID).First(&evaluate).Error; err != nil {
 		return nil, err
 	}
 	return &evaluate, nil
 }
 
-func UpdateEvaluateStatus(evaluateID uuid.UUID, status string, feedback string) (*models.Evaluate, error) {
-	if err := database.DB.Model(&models.Evaluate{}).
-		Where("id = ?", evaluateID).
-		Updates(map[string]interface{}{"status": status, "feedback": feedback, "updated_at": time.Now()}).Error; err != nil {
+// findMemberByIDCard returns the member of the cooperative with the ID card, or nil
+func findMemberByIDCard(tx *gorm.DB, cooperativeID string, idCard string) *models.Member {
+	var member models.Member
+	// Find instead of First: an unregistered applicant is normal, not an error worth logging
+	if err := tx.Scopes(cooperativeScope(cooperativeID)).Where("id_card = ?", idCard).Limit(1).Find(&member).Error; err != nil || member.Id == uuid.Nil {
+		return nil
+	}
+	return &member
+}
+
+// resolveApplicantMember links an applicant to the registered member with the same ID card
+func resolveApplicantMember(tx *gorm.DB, cooperativeID string, idCard string) *uuid.UUID {
+	member := findMemberByIDCard(tx, cooperativeID, idCard)
+	if member == nil {
+		return nil
+	}
+	return &member.Id
+}
+
+// readableEvaluates limits a query on evaluates to those the reader may see: their own, or every
+// evaluate of the cooperative when their role holds evaluate:read:all. Every read of a single
+// evaluate and of what hangs off it goes through this scope rather than a route guard.
+func readableEvaluates(cooperativeID string, readerID uuid.UUID) (func(*gorm.DB) *gorm.DB, error) {
+	var reader models.Admin
+	if err := database.DB.Where("id = ?", readerID).First(&reader).Error; err != nil {
+		return nil, err
+	}
+	readAll, err := AdminCan(&reader, models.PermissionEvaluateReadAll)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Scopes(cooperativeScope(cooperativeID))
+		if readAll {
+			return db
+		}
+		return db.Where("user_id = ?", readerID)
+	}, nil
+}
+
+// readableAuditEvaluates selects the IDs of the evaluates the reader may see, including deleted
+// evaluates, whose revisions and workflow history stay readable
+func readableAuditEvaluates(cooperativeID string, readerID uuid.UUID) (*gorm.DB, error) {
+	readable, err := readableEvaluates(cooperativeID, readerID)
+	if err != nil {
 		return nil, err
 	}
-	return GetEvaluateByID(evaluateID)
+	return database.DB.Unscoped().Model(&models.Evaluate{}).Select("id").Scopes(readable), nil
 }
 
-func GetEvaluateByID(evaluateID uuid.UUID) (*models.Evaluate, error) {
+// GetEvaluateByID returns the evaluate if the reader may see it, ErrEvaluateNotFound otherwise
+func GetEvaluateByID(cooperativeID string, evaluateID uuid.UUID, readerID uuid.UUID) (*models.Evaluate, error) {
+	readable, err := readableEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+	evaluate, err := findEvaluate(readable, evaluateID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEvaluateNotFound
+	}
+	return evaluate, err
+}
+
+// findEvaluate loads an evaluate with everything its detail view and exports show
+func findEvaluate(scope func(*gorm.DB) *gorm.DB, evaluateID uuid.UUID) (*models.Evaluate, error) {
 	var evaluate models.Evaluate
-	if err := database.DB.Preload("Applicants").Preload("Result").Preload("Result.Applicants").
-		Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
+	// Catalogue references are loaded even when soft-deleted so renamed careers show their current name
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	if err := database.DB.Preload("Applicants", applicantOrder).Preload("Applicants.CareerCategoryRef", unscoped).
+		Preload("Applicants.SubCategoryRef", unscoped).Preload("Result").Preload("Result.Applicants", applicantOrder).Preload("User").
+		Scopes(scope).Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
 		return nil, err
 	}
 	return &evaluate, nil
 }
 
-func UpdateEvaluate(evaluateID uuid.UUID, userID uuid.UUID, request *models.EvaluateRequest) (*models.Evaluate, error) {
+func UpdateEvaluate(cooperativeID string, evaluateID uuid.UUID, userID uuid.UUID, request *models.EvaluateRequest) (*models.Evaluate, error) {
+	// Recalculate derived values before persisting
+	if err := CalculateEvaluateRequest(cooperativeID, request); err != nil {
+		return nil, err
+	}
+
 	// Start transaction
 	tx := database.DB.Begin()
 	defer func() {
@@ -232,13 +397,27 @@ func UpdateEvaluate(evaluateID uuid.UUID, userID uuid.UUID, request *models.Eval
 
 	// Check if evaluate exists
 	var evaluate models.Evaluate
-	if err := tx.Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
+	if err := lockEvaluate(tx, cooperativeID, evaluateID, &evaluate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Only drafts can change; the checker sees exactly what was submitted
+	if err := ensureEvaluateEditable(&evaluate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Keep the pre-edit figures of evaluates that have no revision yet
+	if err := ensureBaselineRevision(tx, &evaluate); err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
 	// Update evaluate fields
 	evaluate.EvaluateType = request.EvaluateType
 	evaluate.MarginType = request.MarginType
+	evaluate.LoanTerms = request.LoanTerms
 	evaluate.UpdatedAt = time.Now()
 
 	// Query Admin for logging
@@ -254,11 +433,12 @@ func UpdateEvaluate(evaluateID uuid.UUID, userID uuid.UUID, request *models.Eval
 	}
 
 	evaluateLog := models.EvaluateLog{
-		Action:    fmt.Sprintf("แก้ไขแบบประเมินของ %s", mainBorrowerName),
-		Username:  admin.Username,
-		FullName:  admin.FullName,
-		Role:      admin.Role,
-		Timestamp: time.Now(),
+		CooperativeID: cooperativeID,
+		Action:        fmt.Sprintf("แก้ไขแบบประเมินของ %s", mainBorrowerName),
+		Username:      admin.Username,
+		FullName:      admin.FullName,
+		Role:          admin.Role,
+		Timestamp:     time.Now(),
 	}
 	if err := tx.Create(&evaluateLog).Error; err != nil {
 		tx.Rollback()
@@ -271,108 +451,30 @@ func UpdateEvaluate(evaluateID uuid.UUID, userID uuid.UUID, request *models.Eval
 		return nil, err
 	}
 
-	// Delete existing applicants
-	if err := tx.Where("evaluate_id = ?", evaluateID).Delete(&models.Applicant{}).Error; err != nil {
+	// Update applicants and results in place so their IDs survive the edit
+	if err := saveEvaluateApplicants(tx, cooperativeID, evaluate.Id, request.Applicants); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	// Create new applicants
-	for _, applicantReq := range request.Applicants {
-		applicant := models.Applicant{
-			EvaluateID:  evaluate.Id,
-			Id:          uuid.New(), // Generate new ID for the applicant
-			ApplicantID: uuid.New(), // Generate new ApplicantID
-			// Copy all borrower data fields
-			CareerCategory:       applicantReq.CareerCategory,
-			Career:               applicantReq.Career,
-			OtherCareer:          applicantReq.OtherCareer,
-			Name:                 applicantReq.Name,
-			IDCard:               applicantReq.IDCard,
-			BusinessActivity:     applicantReq.BusinessActivity,
-			ExpenseItem:          applicantReq.ExpenseItem,
-			ProfileLost:          applicantReq.ProfileLost,
-			ShareHolder:          applicantReq.ShareHolder,
-			OptionalOtherExpense: applicantReq.OptionalOtherExpense,
-			Salary:               applicantReq.Salary,
-			OtherSalary:          applicantReq.OtherSalary,
-			OptionsSalary:        applicantReq.OptionsSalary,
-			CreatedAt:            time.Now(),
-			UpdatedAt:            time.Now(),
-		}
-
-		if err := tx.Create(&applicant).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-	}
-
-	// Delete existing result applicants first (must be done before evaluate_results due to foreign key constraint)
-	errDeleteApplicants := tx.Where("result_id IN (SELECT id FROM evaluate_results WHERE evaluate_id = ?)", evaluateID).
-		Delete(&models.ResultApplicant{}).Error
-	if errDeleteApplicants != nil {
-		tx.Rollback()
-		return nil, errDeleteApplicants
-	}
-
-	// Delete existing result (after result_applicants are deleted)
-	if err := tx.Where("evaluate_id = ?", evaluateID).Delete(&models.EvaluateResult{}).Error; err != nil {
+	if err := saveEvaluateResult(tx, evaluate.Id, request); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	// Create new result
-	result := models.EvaluateResult{
-		EvaluateID:   evaluate.Id,
-		EvaluateType: request.EvaluateType,
-		DebtDetail:   request.Result.DebtDetail,
-		Dti:          request.Result.Dti,
-		Dscr:         request.Result.Dscr,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	if err := tx.Create(&result).Error; err != nil {
+	// Snapshot the edited state as a new revision
+	if err := createEvaluateRevision(tx, evaluate.Id, userID); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	// Create result applicants
-	for _, resultApplicantReq := range request.Result.Applicants {
-		resultApplicant := models.ResultApplicant{
-			EvaluateID:             result.EvaluateID,
-			ResultID:               result.Id,
-			Name:                   resultApplicantReq.Name,
-			IDCard:                 resultApplicantReq.IDCard,
-			Salary:                 resultApplicantReq.Salary,
-			Expenses:               resultApplicantReq.Expenses,
-			OtherSalary:            resultApplicantReq.OtherSalary,
-			OptionsSalary:          resultApplicantReq.OptionsSalary,
-			ResultShareValue:       resultApplicantReq.ResultShareValue,
-			TotalSalary:            resultApplicantReq.TotalSalary,
-			ResultIncome:           resultApplicantReq.ResultIncome,
-			CustomerExpenses:       resultApplicantReq.CustomerExpenses,
-			ResultCustomerExpenses: resultApplicantReq.ResultCustomerExpenses,
-			LivingExpenses:         resultApplicantReq.LivingExpenses,
-			OtherExpenses:          resultApplicantReq.OtherExpenses,
-			TotalExpenses:          resultApplicantReq.TotalExpenses,
-			CreatedAt:              time.Now(),
-			UpdatedAt:              time.Now(),
-		}
-
-		if err := tx.Create(&resultApplicant).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-	}
-
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
 	// Reload the evaluate with all associations
-	if err := database.DB.Preload("Applicants").Preload("Result").Preload("Result.Applicants").
+	if err := database.DB.Preload("Applicants", applicantOrder).Preload("Result").Preload("Result.Applicants", applicantOrder).
 		Where("id = ?", evaluate.Id).First(&evaluate).Error; err != nil {
 		return nil, err
 	}
@@ -380,13 +482,7 @@ func UpdateEvaluate(evaluateID uuid.UUID, userID uuid.UUID, request *models.Eval
 	return &evaluate, nil
 }
 
-func DeleteEvaluate(evaluateID uuid.UUID, userID uuid.UUID) error {
-	// Check if evaluate exists
-	var evaluate models.Evaluate
-	if err := database.DB.Preload("Applicants").Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
-		return err
-	}
-
+func DeleteEvaluate(cooperativeID string, evaluateID uuid.UUID, userID uuid.UUID) error {
 	// Start transaction
 	tx := database.DB.Begin()
 	defer func() {
@@ -395,6 +491,23 @@ func DeleteEvaluate(evaluateID uuid.UUID, userID uuid.UUID) error {
 		}
 	}()
 
+	// Check if evaluate exists
+	var evaluate models.Evaluate
+	if err := lockEvaluate(tx, cooperativeID, evaluateID, &evaluate); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("evaluate_id = ?", evaluate.Id).Order("created_at ASC").Find(&evaluate.Applicants).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Only drafts can change; the checker sees exactly what was submitted
+	if err := ensureEvaluateEditable(&evaluate); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Query Admin for logging
 	var admin models.Admin
 	if err := tx.Where("id = ?", userID).First(&admin).Error; err != nil {
@@ -408,47 +521,180 @@ func DeleteEvaluate(evaluateID uuid.UUID, userID uuid.UUID) error {
 	}
 
 	evaluateLog := models.EvaluateLog{
-		Action:    fmt.Sprintf("ลบแบบประเมินของ %s", mainBorrowerName),
-		Username:  admin.Username,
-		FullName:  admin.FullName,
-		Role:      admin.Role,
-		Timestamp: time.Now(),
+		CooperativeID: cooperativeID,
+		Action:        fmt.Sprintf("ลบแบบประเมินของ %s", mainBorrowerName),
+		Username:      admin.Username,
+		FullName:      admin.FullName,
+		Role:          admin.Role,
+		Timestamp:     time.Now(),
 	}
 	if err := tx.Create(&evaluateLog).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// Delete associated applicants first
-	if err := tx.Where("evaluate_id = ?", evaluateID).Delete(&models.Applicant{}).Error; err != nil {
+	// Soft delete: applicants, results, workflow history and revisions stay for audit
+	if err := tx.Delete(&evaluate).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// Delete associated result applicants first (before evaluate_results)
-	errDeleteApplicants := tx.Where("result_id IN (SELECT id FROM evaluate_results WHERE evaluate_id = ?)", evaluateID).
-		Delete(&models.ResultApplicant{}).Error
-	if errDeleteApplicants != nil {
-		tx.Rollback()
-		return errDeleteApplicants
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return err
 	}
 
-	// Delete associated result
-	if err := tx.Where("evaluate_id = ?", evaluateID).Delete(&models.EvaluateResult{}).Error; err != nil {
-		tx.Rollback()
+	return nil
+}
+
+// saveEvaluateApplicants brings the evaluate's applicants in line with the request. Applicants are
+// matched by ID, then by ID card for ones sent without an ID, and updated in place; unmatched
+// requests are created and applicants no longer on the evaluate are deleted.
+func saveEvaluateApplicants(tx *gorm.DB, cooperativeID string, evaluateID uuid.UUID, requests []models.ApplicantRequest) error {
+	var existing []models.Applicant
+	if err := tx.Scopes(applicantOrder).Where("evaluate_id = ?", evaluateID).Find(&existing).Error; err != nil {
 		return err
 	}
 
-	// Delete the main evaluate record
-	if err := tx.Delete(&evaluate).Error; err != nil {
-		tx.Rollback()
+	matches := make([]int, len(requests))
+	for i := range matches {
+		matches[i] = -1
+	}
+	taken := make([]bool, len(existing))
+	match := func(same func(applicant models.Applicant, request models.ApplicantRequest) bool) {
+		for i, request := range requests {
+			if matches[i] >= 0 {
+				continue
+			}
+			for j, applicant := range existing {
+				if !taken[j] && same(applicant, request) {
+					matches[i], taken[j] = j, true
+					break
+				}
+			}
+		}
+	}
+	match(func(a models.Applicant, r models.ApplicantRequest) bool { return r.ID != nil && a.Id == *r.ID })
+	match(func(a models.Applicant, r models.ApplicantRequest) bool { return a.IDCard == r.IDCard })
+
+	for j, applicant := range existing {
+		if !taken[j] {
+			if err := tx.Delete(&applicant).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	for i, request := range requests {
+		applicant := models.Applicant{
+			Id:          uuid.New(),
+			EvaluateID:  evaluateID,
+			ApplicantID: uuid.New(),
+			CreatedAt:   now,
+		}
+		if matches[i] >= 0 {
+			applicant = existing[matches[i]]
+		}
+
+		applicant.Position = i
+		applicant.CareerCategory = request.CareerCategory
+		applicant.Career = request.Career
+		applicant.OtherCareer = request.OtherCareer
+		applicant.CareerCategoryID = request.CareerCategoryID
+		applicant.SubCategoryID = request.SubCategoryID
+		applicant.Name = request.Name
+		applicant.IDCard = request.IDCard
+		applicant.MemberID = resolveApplicantMember(tx, cooperativeID, request.IDCard)
+		applicant.MarginID = request.MarginID
+		applicant.MarginValue = request.MarginValue
+		applicant.BusinessActivity = request.BusinessActivity
+		applicant.ExpenseItem = request.ExpenseItem
+		applicant.ProfileLost = request.ProfileLost
+		applicant.ShareHolder = request.ShareHolder
+		applicant.OptionalOtherExpense = request.OptionalOtherExpense
+		applicant.Salary = request.Salary
+		applicant.OtherSalary = request.OtherSalary
+		applicant.OptionsSalary = request.OptionsSalary
+		applicant.UpdatedAt = now
+
+		if err := tx.Save(&applicant).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveEvaluateResult updates the evaluate's result in place, matching result applicants by ID card
+func saveEvaluateResult(tx *gorm.DB, evaluateID uuid.UUID, request *models.EvaluateRequest) error {
+	now := time.Now()
+
+	var result models.EvaluateResult
+	if err := tx.Preload("Applicants", applicantOrder).Where("evaluate_id = ?", evaluateID).Limit(1).Find(&result).Error; err != nil {
 		return err
 	}
+	if result.Id == uuid.Nil {
+		result = models.EvaluateResult{Id: uuid.New(), EvaluateID: evaluateID, CreatedAt: now}
+	}
 
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
+	existing := result.Applicants
+	result.Applicants = nil
+	result.EvaluateType = request.EvaluateType
+	result.DebtDetail = request.Result.DebtDetail
+	result.Dti = request.Result.Dti
+	result.Dscr = request.Result.Dscr
+	result.ShareCredit = request.Result.ShareCredit
+	result.Recommendation = request.Result.Recommendation
+	result.UpdatedAt = now
+	if err := tx.Save(&result).Error; err != nil {
 		return err
 	}
 
+	taken := make([]bool, len(existing))
+	for i, resultRequest := range request.Result.Applicants {
+		resultApplicant := models.ResultApplicant{
+			Id:         uuid.New(),
+			EvaluateID: evaluateID,
+			ResultID:   result.Id,
+			CreatedAt:  now,
+		}
+		for j, candidate := range existing {
+			if !taken[j] && candidate.IDCard == resultRequest.IDCard {
+				resultApplicant, taken[j] = candidate, true
+				break
+			}
+		}
+
+		resultApplicant.Position = i
+		resultApplicant.Name = resultRequest.Name
+		resultApplicant.IDCard = resultRequest.IDCard
+		resultApplicant.Salary = resultRequest.Salary
+		resultApplicant.Expenses = resultRequest.Expenses
+		resultApplicant.OtherSalary = resultRequest.OtherSalary
+		resultApplicant.OptionsSalary = resultRequest.OptionsSalary
+		resultApplicant.ResultShareValue = resultRequest.ResultShareValue
+		resultApplicant.TotalSalary = resultRequest.TotalSalary
+		resultApplicant.ResultIncome = resultRequest.ResultIncome
+		resultApplicant.CustomerExpenses = resultRequest.CustomerExpenses
+		resultApplicant.ResultCustomerExpenses = resultRequest.ResultCustomerExpenses
+		resultApplicant.LivingExpenses = resultRequest.LivingExpenses
+		resultApplicant.OtherExpenses = resultRequest.OtherExpenses
+		resultApplicant.TotalExpenses = resultRequest.TotalExpenses
+		resultApplicant.UpdatedAt = now
+
+		if err := tx.Save(&resultApplicant).Error; err != nil {
+			return err
+		}
+	}
+
+	for j, resultApplicant := range existing {
+		if !taken[j] {
+			if err := tx.Delete(&resultApplicant).Error; err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+// evaluateReads calls every read of a single evaluate, or of what hangs off one, for the reader
+var evaluateReads = map[string]func(evaluateID, readerID uuid.UUID) error{
+	"GetEvaluateByID": func(id, reader uuid.UUID) error {
+		_, err := GetEvaluateByID(ownerCooperative, id, reader)
+		return err
+	},
+	"GetEvaluateStatusHistory": func(id, reader uuid.UUID) error {
+		_, err := GetEvaluateStatusHistory(ownerCooperative, id, reader)
+		return err
+	},
+	"GetEvaluateSchedule": func(id, reader uuid.UUID) error {
+		_, err := GetEvaluateSchedule(ownerCooperative, id, reader)
+		return err
+	},
+	"StressEvaluate": func(id, reader uuid.UUID) error {
+		_, err := StressEvaluate(ownerCooperative, id, reader, &models.StressRequest{})
+		return err
+	},
+	"GetEvaluateRevisions": func(id, reader uuid.UUID) error {
+		_, err := GetEvaluateRevisions(ownerCooperative, id, reader)
+		return err
+	},
+	"GetEvaluateRevision": func(id, reader uuid.UUID) error {
+		_, err := GetEvaluateRevision(ownerCooperative, id, 1, reader)
+		return err
+	},
+	"GetMemberEvaluates": func(id, reader uuid.UUID) error {
+		_, err := GetMemberEvaluates(ownerCooperative, id, reader)
+		return err
+	},
+}
+
+// readsOwnEvaluates reports whether evaluates were queried and every such query was limited to the
+// reader's own evaluates
+func (db *fakeDB) readsOwnEvaluates(readerID uuid.UUID) (queried bool, own bool) {
+	own = true
+	for _, q := range db.recorded() {
+		if !strings.Contains(q.sql, `"evaluates"`) {
+			continue
+		}
+		queried = true
+		limited := false
+		for _, arg := range q.args {
+			limited = limited || arg == readerID.String()
+		}
+		own = own && strings.Contains(q.sql, "user_id = ") && limited
+	}
+	return queried, own
+}
+
+func TestEvaluateReadsFollowOneRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		own         bool
+	}{
+		{"without evaluate:read:all", []string{models.PermissionEvaluateWrite}, true},
+		{"with evaluate:read:all", []string{models.PermissionEvaluateReadAll}, false},
+	}
+
+	for _, tt := range tests {
+		for name, read := range evaluateReads {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				db := useFakeDB(t)
+				db.permissions = tt.permissions
+				readerID := uuid.New()
+
+				// Errors past the lookup, like missing loan terms, do not matter here
+				_ = read(uuid.New(), readerID)
+
+				queried, own := db.readsOwnEvaluates(readerID)
+				if !queried {
+					t.Fatal("evaluates were not queried")
+				}
+				if own != tt.own {
+					t.Errorf("limited to the reader's evaluates = %v, want %v", own, tt.own)
+				}
+			})
+		}
+	}
+}
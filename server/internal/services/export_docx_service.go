@@ -6,7 +6,12 @@ import (
 	"strings"
 	"html/template"
 
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/calculator"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/gomutex/godocx"
+	"github.com/gomutex/godocx/docx"
+	"github.com/gomutex/godocx/wml/ctypes"
+	"github.com/gomutex/godocx/wml/stypes"
 )
 
 // ---- helpers ----------------------------------------------------------------
@@ -85,6 +90,7 @@ const htmlBaseTpl = `
         
         /* ตั้งค่าหน้ากระดาษ PDF */
         @page { size: A4; margin: 1.25cm; }
+        .worksheet { page-break-before: always; }
         @media print { body { padding: 0; } }
     </style>
 </head>
@@ -135,7 +141,7 @@ const htmlBaseTpl = `
     </tr>
     <tr>
         <td></td>
-        <td>(______________________________)</td>
+        <td>({{if .PreparedBy}}{{.PreparedBy}}{{else}}______________________________{{end}})</td>
         <td></td>
         <td>(______________________________)</td>
     </tr>
@@ -157,6 +163,22 @@ const htmlBaseTpl = `
         <td></td>
         <td class="font-bold">ผู้ตรวจสอบ</td>
     </tr>
+    {{if .Status}}
+    <tr>
+        <td></td>
+        <td></td>
+        <td></td>
+        <td>สถานะ : {{.Status}}</td>
+    </tr>
+    {{end}}
+    {{if .Feedback}}
+    <tr>
+        <td></td>
+        <td></td>
+        <td></td>
+        <td>ความเห็น : {{.Feedback}}</td>
+    </tr>
+    {{end}}
 </table>
 
 <div style="margin-top: 20px;">
@@ -164,6 +186,10 @@ const htmlBaseTpl = `
     <div>การคำนวณความสามารถในการชำระหนี้เป็นไปตามหลักเกณฑ์ เรื่อง การพิจารณาความสามารถในการชำระหนี้การให้สินเชื่อรายย่อยทุกประเภท</div>
 </div>
 
+{{.Schedule}}
+
+{{.Worksheets}}
+
 </body>
 </html>
 `
@@ -182,6 +208,248 @@ func highlightRowHTML(label, value, unit string) string {
 	return fmt.Sprintf(`<tr class="bg-highlight"><td class="text-center font-bold">%s</td><td class="text-right font-bold">%s</td><td class="text-center">%s</td></tr>`, label, value, unit)
 }
 
+// exportRow is one label/value line of the evaluate export, shared by every output format
+type exportRow struct {
+	Label     string
+	Value     string
+	Unit      string
+	Highlight bool
+}
+
+func applicantExportRows(a models.ResultApplicant) []exportRow {
+	return []exportRow{
+		{Label: "อัตราเงินเดือน", Value: fmtNum(a.Salary), Unit: "บาท/เดือน"},
+		{Label: "รายการหักของหน่วยงานที่ไม่ใช่ภาระหนี้", Value: fmtNum(a.Expenses), Unit: "บาท/เดือน"},
+		{Label: "เงินได้ประจำอื่นๆ", Value: fmtNum(a.OtherSalary), Unit: "บาท/เดือน"},
+		{Label: "เงินได้อื่นๆ ที่มีหลักฐาน", Value: fmtNum(a.OptionsSalary), Unit: "บาท/เดือน"},
+		{Label: "กำไรสุทธิจากการประกอบอาชีพตามสัดส่วนการถือหุ้นในธุรกิจ", Value: fmtNum(a.ResultShareValue), Unit: "บาท/เดือน"},
+		{Label: "รายได้รวม", Value: fmtNum(a.TotalSalary), Unit: "บาท/เดือน", Highlight: true},
+		{Label: "รายได้สุทธิรวม", Value: fmtNum(a.ResultIncome), Unit: "บาท/เดือน", Highlight: true},
+		{Label: "ค่าใช้จ่ายในการอุปโภคบริโภค", Value: fmtNum(a.ResultCustomerExpenses), Unit: "บาท/เดือน"},
+		{Label: "ค่าใช้จ่ายที่พักอาศัย", Value: fmtNum(a.LivingExpenses), Unit: "บาท/เดือน"},
+		{Label: "ค่าใช้จ่ายอื่นๆ", Value: fmtNum(a.OtherExpenses), Unit: "บาท/เดือน"},
+		{Label: "ค่าใช้จ่ายรวม", Value: fmtNum(a.TotalExpenses), Unit: "บาท/เดือน", Highlight: true},
+	}
+}
+
+func debtExportRows(d models.DebtDetail) []exportRow {
+	return []exportRow{
+		{Label: "หนี้ครั้งนี้", Value: fmtNum(d.DebtAmount), Unit: "บาท/เดือน"},
+		{Label: "หนี้สิน GSB (จาก CBS)", Value: fmtNum(d.LastDebt), Unit: "บาท/เดือน"},
+		{Label: "หนี้สินที่รายงานต่อ NCB (ไม่รวมหนี้สิน GSB)", Value: fmtNum(d.DebtReported), Unit: "บาท/เดือน"},
+		{Label: "หนี้สินที่ไม่ได้รายงานต่อ NCB", Value: fmtNum(d.DebtNotReported), Unit: "บาท/เดือน"},
+		{Label: "หัก เงินงวดเดิม กรณีคำขอนี้เป็นการ Refinance", Value: fmtNum(d.LastDeduction), Unit: "บาท/เดือน"},
+		{Label: "ภาระผ่อนชำระหนี้รวม", Value: fmtNum(d.TotalDebt), Unit: "บาท/เดือน", Highlight: true},
+	}
+}
+
+// loanMethodLabel names a repayment method the way the loan contract does
+func loanMethodLabel(method string) string {
+	switch method {
+	case models.LoanMethodFlat:
+		return "ดอกเบี้ยคงที่ (Flat Rate)"
+	case models.LoanMethodEqualPrincipal:
+		return "เงินต้นเท่ากันทุกงวด"
+	default:
+		return "ลดต้นลดดอก (Effective Rate)"
+	}
+}
+
+func loanTermsExportRows(s models.LoanSchedule) []exportRow {
+	return []exportRow{
+		{Label: "วงเงินกู้", Value: fmtNum(s.Terms.Principal), Unit: "บาท"},
+		{Label: "อัตราดอกเบี้ย", Value: fmtPct(s.Terms.AnnualRate), Unit: "% ต่อปี"},
+		{Label: "ระยะเวลากู้", Value: fmt.Sprintf("%d", s.Terms.TermMonths), Unit: "เดือน"},
+		{Label: "วิธีการผ่อนชำระ", Value: loanMethodLabel(s.Terms.Method)},
+		{Label: "เงินงวดต่อเดือน", Value: fmtNum(s.MonthlyInstallment), Unit: "บาท/เดือน", Highlight: true},
+		{Label: "ดอกเบี้ยรวม", Value: fmtNum(s.TotalInterest), Unit: "บาท"},
+		{Label: "ยอดชำระรวม", Value: fmtNum(s.TotalPayment), Unit: "บาท"},
+	}
+}
+
+// exportTable is a multi-column table, used for the amortization schedule
+type exportTable struct {
+	Header []string
+	Rows   [][]string
+}
+
+func scheduleExportTable(s models.LoanSchedule) exportTable {
+	table := exportTable{Header: []string{"งวดที่", "เงินงวด", "เงินต้น", "ดอกเบี้ย", "เงินต้นคงเหลือ"}}
+	for _, r := range s.Rows {
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%d", r.Period), fmtNum(r.Payment), fmtNum(r.Principal), fmtNum(r.Interest), fmtNum(r.Balance),
+		})
+	}
+	return table
+}
+
+// exportSection is a titled table of the detailed applicant worksheet
+type exportSection struct {
+	Title string
+	Rows  []exportRow
+}
+
+// applicantWorksheetSections lists the inputs behind an applicant's result, in the order of the evaluate form
+func applicantWorksheetSections(a models.Applicant) []exportSection {
+	career := []exportRow{
+		{Label: "หมวดหมู่อาชีพ", Value: a.CareerCategory},
+		{Label: "อาชีพ", Value: a.Career},
+	}
+	if a.OtherCareer != "" {
+		career = append(career, exportRow{Label: "อื่นๆ โปรดระบุ", Value: a.OtherCareer})
+	}
+
+	return []exportSection{
+		{Title: "อาชีพ", Rows: career},
+		{Title: "รายได้ของกิจการ/อาชีพอิสระ", Rows: []exportRow{
+			{Label: "รายได้", Value: fmtNum(a.BusinessActivity.Salary), Unit: "บาท/เดือน"},
+			{Label: "รายได้อื่นๆ จากธุรกิจ", Value: fmtNum(a.BusinessActivity.OtherSalary), Unit: "บาท/เดือน"},
+			{Label: "รวมรายได้ของกิจการ/อาชีพอิสระ", Value: fmtNum(a.BusinessActivity.TotalIncome), Unit: "บาท/เดือน", Highlight: true},
+		}},
+		{Title: "ค่าใช้จ่ายของกิจการ", Rows: []exportRow{
+			{Label: "ค่าใช้จ่ายซื้อสินค้าและบริการ", Value: fmtPct(a.ExpenseItem.CostPercentage), Unit: "%"},
+			{Label: "ค่าใช้จ่ายซื้อสินค้าและบริการ", Value: fmtNum(a.ExpenseItem.CostAndService), Unit: "บาท/เดือน"},
+			{Label: "เงินเดือนคนงาน", Value: fmtNum(a.ExpenseItem.EmpSalary), Unit: "บาท/เดือน"},
+			{Label: "ค่าเช่า", Value: fmtNum(a.ExpenseItem.RentExpenses), Unit: "บาท/เดือน"},
+			{Label: "ค่าน้ำ ค่าไฟ ค่าโทรศัพท์", Value: fmtNum(a.ExpenseItem.UtilityExpenses), Unit: "บาท/เดือน"},
+			{Label: "ค่าใช้จ่ายอื่นๆ", Value: fmtNum(a.ExpenseItem.OtherExpenses), Unit: "บาท/เดือน"},
+			{Label: "รวมค่าใช้จ่าย", Value: fmtNum(a.ExpenseItem.TotalExpense), Unit: "บาท/เดือน", Highlight: true},
+		}},
+		{Title: "กำไรขาดทุน", Rows: []exportRow{
+			{Label: "กำไรขั้นต้น", Value: fmtNum(a.ProfileLost.GrossProfit), Unit: "บาท/เดือน"},
+			{Label: "ดอกเบี้ยจ่าย", Value: fmtNum(a.ProfileLost.InterestExpense), Unit: "บาท/เดือน"},
+			{Label: "กำไรก่อนหักภาษี", Value: fmtNum(a.ProfileLost.ProfitBeforeTax), Unit: "บาท/เดือน"},
+			{Label: "ภาษี", Value: fmtNum(a.ProfileLost.TaxExpense), Unit: "บาท/เดือน"},
+			{Label: "กำไรสุทธิ/ขาดทุนสุทธิ", Value: fmtNum(a.ProfileLost.NetProfit), Unit: "บาท/เดือน", Highlight: true},
+		}},
+		{Title: "สัดส่วนการถือหุ้น", Rows: []exportRow{
+			{Label: "สัดส่วนผู้ถือหุ้น (ผู้กู้)", Value: fmtPct(a.ShareHolder.ShareOfNetProfit), Unit: "%"},
+			{Label: "สัดส่วนผู้ถือหุ้น (ผู้กู้)", Value: fmtNum(a.ShareHolder.BankNetProfit), Unit: "บาท/เดือน", Highlight: true},
+			{Label: "ค่าใช้จ่ายอื่นๆ (เพิ่มเติม)", Value: fmtNum(a.OptionalOtherExpense), Unit: "บาท/เดือน"},
+		}},
+		{Title: "เงินเดือน", Rows: []exportRow{
+			{Label: "อัตราเงินเดือน", Value: fmtNum(a.Salary.Base), Unit: "บาท/เดือน"},
+			{Label: "รายได้เสริม (ฟรีแลนซ์)", Value: fmtNum(a.Salary.FreelanceIncome), Unit: "บาท/เดือน"},
+			{Label: "ภาษี (หัก)", Value: fmtNum(a.Salary.Tax), Unit: "บาท/เดือน"},
+			{Label: "ค่าประกันสังคม (หัก)", Value: fmtNum(a.Salary.SocialSecurityFund), Unit: "บาท/เดือน"},
+			{Label: "กองทุนสำรองเลี้ยงชีพ (หัก)", Value: fmtNum(a.Salary.ProvidentFund), Unit: "บาท/เดือน"},
+			{Label: "ค่าหุ้นสหกรณ์ (หัก)", Value: fmtNum(a.Salary.ShareFund), Unit: "บาท/เดือน"},
+			{Label: "ฌอส. (หัก)", Value: fmtNum(a.Salary.AssociationFund), Unit: "บาท/เดือน"},
+			{Label: "อื่นๆ (หัก)", Value: fmtNum(a.Salary.OtherFund), Unit: "บาท/เดือน"},
+			{Label: "รวมรายได้สุทธิจากเงินเดือน", Value: fmtNum(a.Salary.Total), Unit: "บาท/เดือน", Highlight: true},
+		}},
+		{Title: "เงินได้ประจำอื่นๆ", Rows: []exportRow{
+			{Label: "เงินประจำตำแหน่ง", Value: fmtNum(a.OtherSalary.EntertainmentSalary), Unit: "บาท/เดือน"},
+			{Label: "เงินเบิกค่าเช่าบ้าน", Value: fmtNum(a.OtherSalary.LivingSalary), Unit: "บาท/เดือน"},
+			{Label: "ค่ารับรอง", Value: fmtNum(a.OtherSalary.CertificationSalary), Unit: "บาท/เดือน"},
+			{Label: "ค่าครองชีพ/ค่าอาหาร/ค่าช่วยเหลือบุตร", Value: fmtNum(a.OtherSalary.ProfessionalAllowance), Unit: "บาท/เดือน"},
+			{Label: "ค่าพาหนะ/ค่าน้ำมัน", Value: fmtNum(a.OtherSalary.TransportationSalary), Unit: "บาท/เดือน"},
+			{Label: "ค่าวิชาชีพ/วิทยฐานะ", Value: fmtNum(a.OtherSalary.AcademicSalary), Unit: "บาท/เดือน"},
+			{Label: "อื่นๆ", Value: fmtNum(a.OtherSalary.OtherRegularSalary), Unit: "บาท/เดือน"},
+			{Label: "รวมเงินได้ประจำอื่นๆ", Value: fmtNum(a.OtherSalary.Total), Unit: "บาท/เดือน", Highlight: true},
+		}},
+		{Title: "เงินได้อื่นๆ ที่มีหลักฐาน", Rows: []exportRow{
+			{Label: "ค่า Commission", Value: fmtNum(a.OptionsSalary.Commission), Unit: "บาท/เดือน"},
+			{Label: "ค่า OT/ค่าเบี้ยขยัน", Value: fmtNum(a.OptionsSalary.Overtime), Unit: "บาท/เดือน"},
+			{Label: "โบนัส", Value: fmtNum(a.OptionsSalary.Bonus), Unit: "บาท/เดือน"},
+			{Label: "เงินปันผล/ดอกเบี้ยรับ", Value: fmtNum(a.OptionsSalary.DividendsInterest), Unit: "บาท/เดือน"},
+			{Label: "รายได้เสริมสุทธิ (ไม่มีต้นทุน)", Value: fmtNum(a.OptionsSalary.NetSupplementaryIncome), Unit: "บาท/เดือน"},
+			{Label: "อื่นๆ", Value: fmtNum(a.OptionsSalary.Other), Unit: "บาท/เดือน"},
+			{Label: "รวมเงินได้อื่นๆ ที่มีหลักฐาน", Value: fmtNum(a.OptionsSalary.OtherDocumentedIncome), Unit: "บาท/เดือน", Highlight: true},
+			{Label: "รายได้จากเงินเดือน", Value: fmtNum(a.OptionsSalary.Total), Unit: "บาท/เดือน", Highlight: true},
+		}},
+	}
+}
+
+// worksheetTitle heads the worksheet page of an applicant
+func worksheetTitle(index int, a models.Applicant) string {
+	return fmt.Sprintf("รายละเอียดข้อมูล%s : %s", applicantLabel(index), a.Name)
+}
+
+// signatureRows is the signature block shared by the PDF and DOCX exports, the HTML
+// template keeps its own copy of the same layout
+func signatureRows(data HtmlData) [][4]string {
+	line := "______________________________"
+	date := "........../........../.........."
+	preparedBy := line
+	if data.PreparedBy != "" {
+		preparedBy = data.PreparedBy
+	}
+
+	rows := [][4]string{
+		{"ลงนาม", line, "", line},
+		{"", "(" + preparedBy + ")", "", "(" + line + ")"},
+		{"ตำแหน่ง", line, "", ""},
+		{"วันที่", date, "", date},
+		{"", "ผู้จัดทำ", "", "ผู้ตรวจสอบ"},
+	}
+	if data.Status != "" {
+		rows = append(rows, [4]string{"", "", "", "สถานะ : " + data.Status})
+	}
+	if data.Feedback != "" {
+		rows = append(rows, [4]string{"", "", "", "ความเห็น : " + data.Feedback})
+	}
+	return rows
+}
+
+func buildWorksheetsHTML(applicants []models.Applicant) string {
+	var sb strings.Builder
+	for i, a := range applicants {
+		sb.WriteString(`<div class="worksheet">`)
+		sb.WriteString(fmt.Sprintf(`<div class="text-left font-bold" style="margin: 10px 0;">%s</div>`, template.HTMLEscapeString(worksheetTitle(i, a))))
+		for _, section := range applicantWorksheetSections(a) {
+			sb.WriteString(`<table>`)
+			sb.WriteString(fmt.Sprintf(`<tr><td colspan="3" class="bg-header font-bold">%s</td></tr>`, section.Title))
+			for _, row := range section.Rows {
+				row.Value = template.HTMLEscapeString(row.Value)
+				sb.WriteString(exportRowHTML(row))
+			}
+			sb.WriteString(`</table>`)
+		}
+		sb.WriteString(`</div>`)
+	}
+	return sb.String()
+}
+
+// buildScheduleHTML renders the loan terms and amortization schedule on a page of their own
+func buildScheduleHTML(s models.LoanSchedule) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="worksheet">`)
+	sb.WriteString(`<div class="text-left font-bold" style="margin: 10px 0;">ตารางผ่อนชำระ</div>`)
+	sb.WriteString(`<table>`)
+	for _, row := range loanTermsExportRows(s) {
+		sb.WriteString(exportRowHTML(row))
+	}
+	sb.WriteString(`</table>`)
+
+	table := scheduleExportTable(s)
+	sb.WriteString(`<table><tr class="bg-header">`)
+	for _, title := range table.Header {
+		sb.WriteString(fmt.Sprintf(`<td class="text-center font-bold">%s</td>`, title))
+	}
+	sb.WriteString(`</tr>`)
+	for _, cells := range table.Rows {
+		sb.WriteString(`<tr>`)
+		for i, cell := range cells {
+			align := "text-right"
+			if i == 0 {
+				align = "text-center"
+			}
+			sb.WriteString(fmt.Sprintf(`<td class="%s">%s</td>`, align, cell))
+		}
+		sb.WriteString(`</tr>`)
+	}
+	sb.WriteString(`</table></div>`)
+	return sb.String()
+}
+
+func exportRowHTML(row exportRow) string {
+	if row.Highlight {
+		return highlightRowHTML(row.Label, row.Value, row.Unit)
+	}
+	return dataRowHTML(row.Label, row.Value, row.Unit, false)
+}
+
 func buildApplicantRowsHTML(label string, a models.ResultApplicant) string {
 	var sb strings.Builder
 	sb.WriteString(`<table>`)
@@ -189,32 +457,18 @@ func buildApplicantRowsHTML(label string, a models.ResultApplicant) string {
 	sb.WriteString(fmt.Sprintf(`<tr><td colspan="3" class="bg-header font-bold">%s</td></tr>`, label))
 
 	// Data
-	sb.WriteString(dataRowHTML("อัตราเงินเดือน", fmtNum(a.Salary), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("รายการหักของหน่วยงานที่ไม่ใช่ภาระหนี้", fmtNum(a.Expenses), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("เงินได้ประจำอื่นๆ", fmtNum(a.OtherSalary), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("เงินได้อื่นๆ ที่มีหลักฐาน", fmtNum(a.OptionsSalary), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("กำไรสุทธิจากการประกอบอาชีพตามสัดส่วนการถือหุ้นในธุรกิจ", fmtNum(a.ResultShareValue), "บาท/เดือน", false))
-
-	sb.WriteString(highlightRowHTML("รายได้รวม", fmtNum(a.TotalSalary), "บาท/เดือน"))
-	sb.WriteString(highlightRowHTML("รายได้สุทธิรวม", fmtNum(a.ResultIncome), "บาท/เดือน"))
-
-	sb.WriteString(dataRowHTML("ค่าใช้จ่ายในการอุปโภคบริโภค", fmtNum(a.ResultCustomerExpenses), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("ค่าใช้จ่ายที่พักอาศัย", fmtNum(a.LivingExpenses), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("ค่าใช้จ่ายอื่นๆ", fmtNum(a.OtherExpenses), "บาท/เดือน", false))
-
-	sb.WriteString(highlightRowHTML("ค่าใช้จ่ายรวม", fmtNum(a.TotalExpenses), "บาท/เดือน"))
+	for _, row := range applicantExportRows(a) {
+		sb.WriteString(exportRowHTML(row))
+	}
 	sb.WriteString(`</table>`)
 	return sb.String()
 }
 
 func buildDebtRowsHTML(d models.DebtDetail) string {
 	var sb strings.Builder
-	sb.WriteString(dataRowHTML("หนี้ครั้งนี้", fmtNum(d.DebtAmount), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("หนี้สิน GSB (จาก CBS)", fmtNum(d.LastDebt), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("หนี้สินที่รายงานต่อ NCB (ไม่รวมหนี้สิน GSB)", fmtNum(d.DebtReported), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("หนี้สินที่ไม่ได้รายงานต่อ NCB", fmtNum(d.DebtNotReported), "บาท/เดือน", false))
-	sb.WriteString(dataRowHTML("หัก เงินงวดเดิม กรณีคำขอนี้เป็นการ Refinance", fmtNum(d.LastDeduction), "บาท/เดือน", false))
-	sb.WriteString(highlightRowHTML("ภาระผ่อนชำระหนี้รวม", fmtNum(d.TotalDebt), "บาท/เดือน"))
+	for _, row := range debtExportRows(d) {
+		sb.WriteString(exportRowHTML(row))
+	}
 	return sb.String()
 }
 
@@ -234,12 +488,25 @@ type HtmlData struct {
 	DebtRows        template.HTML
 	DTI             string
 	DSCR            string
+	PreparedBy      string
+	Status          string
+	Feedback        string
+	Schedule        template.HTML
+	Worksheets      template.HTML
 }
 
 // ---- Main Function ----------------------------------------------------------
 
-// GenerateEvaluateHTML สร้างโค้ด HTML แทนที่ DOCX
-func GenerateEvaluateHTML(eval *models.Evaluate) ([]byte, error) {
+// applicantLabel names an applicant by position, the first one being the main borrower
+func applicantLabel(index int) string {
+	if index == 0 {
+		return "ผู้กู้"
+	}
+	return fmt.Sprintf("ผู้ร่วม (คนที่ %d)", index)
+}
+
+// buildHtmlData เตรียมข้อมูลส่วนหัวและผลลัพธ์ที่ใช้ร่วมกันทุกรูปแบบไฟล์
+func buildHtmlData(eval *models.Evaluate) HtmlData {
 	result := eval.Result
 	applicants := result.Applicants
 
@@ -255,32 +522,50 @@ func GenerateEvaluateHTML(eval *models.Evaluate) ([]byte, error) {
 	var coBorrowers []CoBorrowerData
 	for i := 1; i < len(applicants); i++ {
 		coBorrowers = append(coBorrowers, CoBorrowerData{
-			Label:  fmt.Sprintf("ผู้ร่วม (คนที่ %d)", i),
+			Label:  applicantLabel(i),
 			Name:   applicants[i].Name,
 			IDCard: applicants[i].IDCard,
 		})
 	}
 
+	// ผู้จัดทำ
+	preparedBy := ""
+	if eval.User != nil {
+		preparedBy = eval.User.FullName
+	}
+
+	return HtmlData{
+		EvaluateType:   eval.EvaluateType,
+		BorrowerName:   borrowerName,
+		BorrowerIDCard: borrowerIDCard,
+		CoBorrowers:    coBorrowers,
+		DTI:            fmtPct(result.Dti),
+		DSCR:           fmtPct(result.Dscr),
+		PreparedBy:     preparedBy,
+		Status:         eval.Status,
+		Feedback:       eval.Feedback,
+	}
+}
+
+// GenerateEvaluateHTML สร้างโค้ด HTML แทนที่ DOCX (detailed = แนบรายละเอียดข้อมูลผู้กู้ทุกคน)
+func GenerateEvaluateHTML(eval *models.Evaluate, detailed bool) ([]byte, error) {
+	result := eval.Result
+
 	// สร้างตารางข้อมูลรายได้/รายจ่าย
 	var applicantBlocks strings.Builder
-	applicantLabels := []string{"ผู้กู้"}
-	for i := 1; i < len(applicants); i++ {
-		applicantLabels = append(applicantLabels, fmt.Sprintf("ผู้ร่วม (คนที่ %d)", i))
-	}
-	for i, a := range applicants {
-		applicantBlocks.WriteString(buildApplicantRowsHTML(applicantLabels[i], a))
+	for i, a := range result.Applicants {
+		applicantBlocks.WriteString(buildApplicantRowsHTML(applicantLabel(i), a))
 	}
 
 	// ยัดข้อมูลใส่ Struct เตรียม Render
-	data := HtmlData{
-		EvaluateType:    eval.EvaluateType,
-		BorrowerName:    borrowerName,
-		BorrowerIDCard:  borrowerIDCard,
-		CoBorrowers:     coBorrowers,
-		ApplicantBlocks: template.HTML(applicantBlocks.String()),
-		DebtRows:        template.HTML(buildDebtRowsHTML(result.DebtDetail)),
-		DTI:             fmtPct(result.Dti),
-		DSCR:            fmtPct(result.Dscr),
+	data := buildHtmlData(eval)
+	data.ApplicantBlocks = template.HTML(applicantBlocks.String())
+	data.DebtRows = template.HTML(buildDebtRowsHTML(result.DebtDetail))
+	if eval.LoanTerms.Principal > 0 {
+		data.Schedule = template.HTML(buildScheduleHTML(calculator.AmortizationSchedule(eval.LoanTerms)))
+	}
+	if detailed {
+		data.Worksheets = template.HTML(buildWorksheetsHTML(eval.Applicants))
 	}
 
 	// รัน Template
@@ -291,4 +576,214 @@ func GenerateEvaluateHTML(eval *models.Evaluate) ([]byte, error) {
 	}
 
 	return buf.Bytes(), nil
-}
\ No newline at end of file
+}
+
+// ---- DOCX -------------------------------------------------------------------
+
+const (
+	docxFontFamily     = "Sarabun"
+	docxHighlightColor = "FCE4D6" // .bg-highlight
+	docxHeaderColor    = "F2F2F2" // .bg-header
+)
+
+// Page setup in twips: A4 with the 1.25cm margin of htmlBaseTpl
+var (
+	docxPageWidth  uint64 = 11906
+	docxPageHeight uint64 = 16838
+	docxPageMargin        = 709
+)
+
+// docxText appends a run to the paragraph. Word treats Thai as a complex script, so bold
+// has to be set on bCs as well as b.
+func docxText(p *docx.Paragraph, text string, bold bool) *ctypes.Run {
+	p.AddText(text)
+	ct := p.GetCT()
+	run := ct.Children[len(ct.Children)-1].Run
+	if bold {
+		run.Property = &ctypes.RunProperty{Bold: ctypes.OnOffFromBool(true), BoldCS: ctypes.OnOffFromBool(true)}
+	}
+	return run
+}
+
+// docxCompact removes the template's spacing after the paragraph and applies alignment/fill
+func docxCompact(p *docx.Paragraph, align stypes.Justification, fill string) {
+	ct := p.GetCT()
+	if ct.Property == nil {
+		ct.Property = &ctypes.ParagraphProp{}
+	}
+	after := uint64(0)
+	ct.Property.Spacing = &ctypes.Spacing{After: &after}
+	if fill != "" {
+		ct.Property.Shading = ctypes.NewShading().SetShadingType(stypes.ShdClear).SetColor("auto").SetFill(fill)
+	}
+	p.Justification(align)
+}
+
+func docxCell(row *docx.Row, text string, align stypes.Justification, bold bool, fill string) {
+	p := row.AddCell().AddEmptyPara()
+	docxText(p, text, bold)
+	docxCompact(p, align, fill)
+}
+
+func docxLabelValue(row *docx.Row, label string, value string) {
+	p := row.AddCell().AddEmptyPara()
+	docxText(p, label+" : ", true)
+	docxText(p, value, false)
+	docxCompact(p, stypes.JustificationLeft, "")
+}
+
+// docxRows mirrors buildApplicantRowsHTML and buildDebtRowsHTML, with an optional header row
+func docxRows(doc *docx.RootDoc, title string, rows []exportRow) {
+	table := doc.AddTable()
+	table.Style("TableGrid")
+	if title != "" {
+		header := table.AddRow()
+		docxCell(header, title, stypes.JustificationLeft, true, docxHeaderColor)
+		docxCell(header, "", stypes.JustificationLeft, false, docxHeaderColor)
+		docxCell(header, "", stypes.JustificationLeft, false, docxHeaderColor)
+	}
+	for _, r := range rows {
+		row := table.AddRow()
+		if r.Highlight {
+			docxCell(row, r.Label, stypes.JustificationCenter, true, docxHighlightColor)
+			docxCell(row, r.Value, stypes.JustificationRight, true, docxHighlightColor)
+			docxCell(row, r.Unit, stypes.JustificationCenter, false, docxHighlightColor)
+		} else {
+			docxCell(row, r.Label, stypes.JustificationLeft, false, "")
+			docxCell(row, r.Value, stypes.JustificationRight, false, "")
+			docxCell(row, r.Unit, stypes.JustificationCenter, false, "")
+		}
+	}
+	// Word merges tables that touch, keep a paragraph between them
+	doc.AddEmptyParagraph()
+}
+
+// docxTable writes a multi-column table with a shaded header row
+func docxTable(doc *docx.RootDoc, t exportTable) {
+	table := doc.AddTable()
+	table.Style("TableGrid")
+	header := table.AddRow()
+	for _, title := range t.Header {
+		docxCell(header, title, stypes.JustificationCenter, true, docxHeaderColor)
+	}
+	for _, cells := range t.Rows {
+		row := table.AddRow()
+		for i, cell := range cells {
+			align := stypes.JustificationRight
+			if i == 0 {
+				align = stypes.JustificationCenter
+			}
+			docxCell(row, cell, align, false, "")
+		}
+	}
+	doc.AddEmptyParagraph()
+}
+
+// setUpDocxPage switches the template to A4 and to the Sarabun font of the HTML export
+func setUpDocxPage(doc *docx.RootDoc) {
+	if body := doc.Document.Body; body.SectPr != nil {
+		body.SectPr.PageSize = &ctypes.PageSize{Width: &docxPageWidth, Height: &docxPageHeight}
+		body.SectPr.PageMargin = &ctypes.PageMargin{
+			Top: &docxPageMargin, Right: &docxPageMargin, Bottom: &docxPageMargin, Left: &docxPageMargin,
+		}
+	}
+
+	if styles := doc.DocStyles; styles != nil && styles.DocDefaults != nil &&
+		styles.DocDefaults.RunProp != nil && styles.DocDefaults.RunProp.RunProp != nil {
+		styles.DocDefaults.RunProp.RunProp.Fonts = &ctypes.RunFonts{
+			Ascii: docxFontFamily, HAnsi: docxFontFamily, EastAsia: docxFontFamily, CS: docxFontFamily,
+		}
+	}
+}
+
+// GenerateEvaluateDOCX สร้างไฟล์ Word ที่มีหัวข้อเดียวกับ GenerateEvaluateHTML เพื่อนำไปแก้ไขต่อ
+func GenerateEvaluateDOCX(eval *models.Evaluate, detailed bool) ([]byte, error) {
+	doc, err := godocx.NewDocument()
+	if err != nil {
+		return nil, fmt.Errorf("create document failed: %w", err)
+	}
+	setUpDocxPage(doc)
+
+	data := buildHtmlData(eval)
+
+	// ผู้กู้และผู้กู้ร่วม
+	header := doc.AddTable()
+	row := header.AddRow()
+	docxLabelValue(row, "ประเภทสินเชื่อ", data.EvaluateType)
+	docxCell(row, "", stypes.JustificationLeft, false, "")
+	row = header.AddRow()
+	docxLabelValue(row, "ผู้กู้", data.BorrowerName)
+	docxLabelValue(row, "เลขบัตรประชาชน", data.BorrowerIDCard)
+	for _, co := range data.CoBorrowers {
+		row = header.AddRow()
+		docxLabelValue(row, co.Label, co.Name)
+		docxLabelValue(row, "เลขบัตรประชาชน", co.IDCard)
+	}
+
+	title := doc.AddEmptyParagraph()
+	docxText(title, "สัดส่วนภาระผ่อนชำระหนี้รวมต่อรายได้สุทธิรวม (DTI) และ สัดส่วนความสามารถในการชำระหนี้ (DSCR)", true)
+
+	// ตารางรายได้/รายจ่ายของแต่ละผู้กู้ และภาระหนี้
+	for i, a := range eval.Result.Applicants {
+		docxRows(doc, applicantLabel(i), applicantExportRows(a))
+	}
+	docxRows(doc, "", debtExportRows(eval.Result.DebtDetail))
+
+	// DTI / DSCR
+	ratios := doc.AddTable()
+	ratios.Style("TableGrid")
+	row = ratios.AddRow()
+	docxCell(row, "DTI (Debt to Income Ratio)", stypes.JustificationCenter, true, "")
+	docxCell(row, data.DTI+" %", stypes.JustificationCenter, true, docxHighlightColor)
+	row = ratios.AddRow()
+	docxCell(row, "DSCR (Debt Service Coverage Ratio)", stypes.JustificationCenter, true, "")
+	docxCell(row, data.DSCR+" เท่า", stypes.JustificationCenter, true, docxHighlightColor)
+	doc.AddEmptyParagraph()
+
+	// ลงนาม
+	signature := doc.AddTable()
+	for _, cells := range signatureRows(data) {
+		row = signature.AddRow()
+		for col, text := range cells {
+			align := stypes.JustificationCenter
+			if col == 0 {
+				align = stypes.JustificationLeft
+			}
+			bold := col > 0 && (text == "ผู้จัดทำ" || text == "ผู้ตรวจสอบ")
+			docxCell(row, text, align, bold, "")
+		}
+	}
+
+	// หมายเหตุ
+	doc.AddEmptyParagraph()
+	remark := doc.AddEmptyParagraph()
+	docxText(remark, "หมายเหตุ", true).Property.Underline = ctypes.NewGenSingleStrVal(stypes.UnderlineSingle)
+	doc.AddParagraph("การคำนวณความสามารถในการชำระหนี้เป็นไปตามหลักเกณฑ์ เรื่อง การพิจารณาความสามารถในการชำระหนี้การให้สินเชื่อรายย่อยทุกประเภท")
+
+	// ตารางผ่อนชำระ
+	if eval.LoanTerms.Principal > 0 {
+		schedule := calculator.AmortizationSchedule(eval.LoanTerms)
+		doc.AddPageBreak()
+		docxText(doc.AddEmptyParagraph(), "ตารางผ่อนชำระ", true)
+		docxRows(doc, "", loanTermsExportRows(schedule))
+		docxTable(doc, scheduleExportTable(schedule))
+	}
+
+	// รายละเอียดข้อมูลผู้กู้ หน้าละคน
+	if detailed {
+		for i, a := range eval.Applicants {
+			doc.AddPageBreak()
+			docxText(doc.AddEmptyParagraph(), worksheetTitle(i, a), true)
+			for _, section := range applicantWorksheetSections(a) {
+				docxRows(doc, section.Title, section.Rows)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write document failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/calculator"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Sarabun TTFs are compiled into the binary so the PDF export works without internet access
+//
+//go:embed fonts
+var exportFontFS embed.FS
+
+const (
+	pdfFontFamily  = "Sarabun"
+	pdfFontRegular = "fonts/Sarabun-Regular.ttf"
+	pdfFontBold    = "fonts/Sarabun-Bold.ttf"
+
+	pdfMargin     = 12.5 // mm, same as @page in htmlBaseTpl
+	pdfFontSize   = 10
+	pdfLineHeight = 6.5
+	pdfBlockGap   = 4
+)
+
+var ErrExportFontMissing = errors.New("ไม่พบไฟล์ฟอนต์ภาษาไทยสำหรับสร้างไฟล์ PDF")
+
+var (
+	pdfHighlightColor = [3]int{252, 228, 214} // .bg-highlight
+	pdfHeaderColor    = [3]int{242, 242, 242} // .bg-header
+)
+
+type exportFont struct {
+	regular []byte
+	bold    []byte
+	metrics *thaiMetrics
+}
+
+var (
+	exportFontOnce   sync.Once
+	exportFontLoaded *exportFont
+	exportFontErr    error
+)
+
+func loadExportFont() (*exportFont, error) {
+	exportFontOnce.Do(func() {
+		regular, err := exportFontFS.ReadFile(pdfFontRegular)
+		if err != nil {
+			exportFontErr = ErrExportFontMissing
+			return
+		}
+
+		bold, err := exportFontFS.ReadFile(pdfFontBold)
+		if err != nil {
+			exportFontErr = ErrExportFontMissing
+			return
+		}
+
+		metrics, err := measureThaiGlyphs(regular)
+		if err != nil {
+			exportFontErr = fmt.Errorf("parse font failed: %w", err)
+			return
+		}
+
+		exportFontLoaded = &exportFont{regular: regular, bold: bold, metrics: metrics}
+	})
+	return exportFontLoaded, exportFontErr
+}
+
+// ---- Thai shaping -----------------------------------------------------------
+
+// fpdf places glyphs by cmap only and ignores the font's mark positioning, so Thai marks would
+// collide with tall consonants and with each other. shapeThai keeps the glyphs the font maps and
+// works out from their ink boxes how far each mark has to move; the PDF writer then draws the
+// moved marks on their own.
+
+// thaiMarkGap is the space left between a moved mark and the glyph it clears, in em
+const thaiMarkGap = 0.04
+
+// glyphBox is a glyph's ink box and advance in em, y pointing up from the baseline
+type glyphBox struct {
+	minX, maxX, minY, maxY, advance float64
+}
+
+// thaiMetrics are the measurements shapeThai positions marks from
+type thaiMetrics struct {
+	glyphs   map[rune]glyphBox
+	stemLeft map[rune]float64 // left edge of an ascender's stem above the consonant height
+}
+
+// thaiRun is a piece of text drawn dx, dy em away from where the font puts it
+type thaiRun struct {
+	text   string
+	dx, dy float64
+}
+
+func isThaiConsonant(r rune) bool { return r >= 0x0E01 && r <= 0x0E2E }
+
+func isThaiAscender(r rune) bool { return r == 0x0E1B || r == 0x0E1D || r == 0x0E1F || r == 0x0E2C }
+
+// isThaiDescender is true for ญ ฐ ฎ ฏ, whose tails take the place of a lower vowel
+func isThaiDescender(r rune) bool { return r == 0x0E0D || r == 0x0E10 || r == 0x0E0E || r == 0x0E0F }
+
+func isThaiUpperVowel(r rune) bool {
+	return r == 0x0E31 || (r >= 0x0E34 && r <= 0x0E37) || r == 0x0E47 || r == 0x0E4D
+}
+
+func isThaiTone(r rune) bool { return r >= 0x0E48 && r <= 0x0E4C }
+
+func isThaiLowerVowel(r rune) bool { return r >= 0x0E38 && r <= 0x0E3A }
+
+// measureThaiGlyphs reads the ink boxes of the Thai block and the stems of the ascender consonants
+func measureThaiGlyphs(data []byte) (*thaiMetrics, error) {
+	parsed, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	const ppem = 1000
+	toEm := func(v fixed.Int26_6) float64 { return float64(v) / 64 / ppem }
+
+	var buf sfnt.Buffer
+	metrics := &thaiMetrics{glyphs: map[rune]glyphBox{}, stemLeft: map[rune]float64{}}
+	for r := rune(0x0E01); r <= 0x0E4D; r++ {
+		index, err := parsed.GlyphIndex(&buf, r)
+		if err != nil || index == 0 {
+			continue
+		}
+		bounds, advance, err := parsed.GlyphBounds(&buf, index, fixed.I(ppem), font.HintingNone)
+		if err != nil {
+			return nil, err
+		}
+		metrics.glyphs[r] = glyphBox{
+			minX:    toEm(bounds.Min.X),
+			maxX:    toEm(bounds.Max.X),
+			minY:    -toEm(bounds.Max.Y),
+			maxY:    -toEm(bounds.Min.Y),
+			advance: toEm(advance),
+		}
+	}
+
+	// The stem is whatever an ascender draws above the top of ก
+	top := metrics.glyphs[0x0E01].maxY + thaiMarkGap
+	for r := range metrics.glyphs {
+		if !isThaiAscender(r) {
+			continue
+		}
+		index, _ := parsed.GlyphIndex(&buf, r)
+		segments, err := parsed.LoadGlyph(&buf, index, fixed.I(ppem), nil)
+		if err != nil {
+			return nil, err
+		}
+		left, found := 0.0, false
+		for _, segment := range segments {
+			for _, point := range segment.Args {
+				if x, y := toEm(point.X), -toEm(point.Y); y > top && (!found || x < left) {
+					left, found = x, true
+				}
+			}
+		}
+		if found {
+			metrics.stemLeft[r] = left
+		}
+	}
+
+	return metrics, nil
+}
+
+// ascenderShift moves a mark over ป ฝ ฟ ฬ left until it clears the stem. Marks have no advance and
+// are drawn at the pen position after their base.
+func (m *thaiMetrics) ascenderShift(base rune, mark rune) float64 {
+	stem, ok := m.stemLeft[base]
+	if !ok {
+		return 0
+	}
+	overlap := m.glyphs[mark].maxX - (stem - m.glyphs[base].advance) + thaiMarkGap
+	return -math.Max(0, overlap)
+}
+
+// raiseAbove lifts a mark until it clears the mark below it
+func (m *thaiMetrics) raiseAbove(below rune, mark rune) float64 {
+	box, ok := m.glyphs[below]
+	if !ok {
+		return 0
+	}
+	return math.Max(0, box.maxY+thaiMarkGap-m.glyphs[mark].minY)
+}
+
+// dropBelow lowers a lower vowel until it clears the tail of ญ ฐ ฎ ฏ
+func (m *thaiMetrics) dropBelow(base rune, mark rune) float64 {
+	if !isThaiDescender(base) {
+		return 0
+	}
+	return math.Max(0, m.glyphs[mark].maxY+thaiMarkGap-m.glyphs[base].minY)
+}
+
+// shapeThai splits s into runs, giving every mark that has to move a run of its own
+func shapeThai(s string, m *thaiMetrics) []thaiRun {
+	if m == nil {
+		return []thaiRun{{text: s}}
+	}
+
+	runes := []rune(s)
+	runs := make([]thaiRun, 0, 1)
+	start := 0
+	var base, upper rune
+
+	for i, r := range runes {
+		var dx, dy float64
+		switch {
+		case isThaiConsonant(r):
+			base, upper = r, 0
+			continue
+		case isThaiUpperVowel(r):
+			dx = m.ascenderShift(base, r)
+			upper = r
+		case isThaiTone(r):
+			// A tone goes above the upper vowel, or above the nikhahit of a sara am that follows it
+			below := upper
+			if below == 0 && i+1 < len(runes) && runes[i+1] == 0x0E33 {
+				below = 0x0E4D
+			}
+			dx = m.ascenderShift(base, r)
+			dy = m.raiseAbove(below, r)
+			upper = r
+		case isThaiLowerVowel(r):
+			dy = -m.dropBelow(base, r)
+		default:
+			base, upper = 0, 0
+			continue
+		}
+
+		if dx == 0 && dy == 0 {
+			continue
+		}
+		if start < i {
+			runs = append(runs, thaiRun{text: string(runes[start:i])})
+		}
+		runs = append(runs, thaiRun{text: string(r), dx: dx, dy: dy})
+		start = i + 1
+	}
+
+	if start < len(runes) {
+		runs = append(runs, thaiRun{text: string(runes[start:])})
+	}
+	return runs
+}
+
+// ---- PDF Layout -------------------------------------------------------------
+
+type pdfDocument struct {
+	pdf   *fpdf.Fpdf
+	font  *exportFont
+	width float64 // printable width
+}
+
+func (d *pdfDocument) setFont(bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	d.pdf.SetFont(pdfFontFamily, style, pdfFontSize)
+}
+
+func (d *pdfDocument) cell(w float64, text string, border string, align string, bold bool, fill *[3]int) {
+	d.setFont(bold)
+	if fill != nil {
+		d.pdf.SetFillColor(fill[0], fill[1], fill[2])
+	}
+	d.text(w, text, border, align, fill != nil)
+}
+
+// text is CellFormat with the text vertically centred, drawn run by run so the marks shapeThai
+// moved land where it put them
+func (d *pdfDocument) text(w float64, text string, border string, align string, fill bool) {
+	d.pdf.CellFormat(w, pdfLineHeight, "", border, 0, "", fill, 0, "")
+	// Read the position back since the cell may have started a new page
+	x, y := d.pdf.GetX()-w, d.pdf.GetY()
+
+	switch width := d.pdf.GetStringWidth(text); align {
+	case "R":
+		x += w - d.pdf.GetCellMargin() - width
+	case "C":
+		x += (w - width) / 2
+	default:
+		x += d.pdf.GetCellMargin()
+	}
+	_, size := d.pdf.GetFontSize()
+	baseline := y + pdfLineHeight/2 + 0.3*size // same baseline as CellFormat's "M" alignment
+
+	for _, run := range shapeThai(text, d.font.metrics) {
+		d.pdf.Text(x+run.dx*size, baseline-run.dy*size, run.text)
+		x += d.pdf.GetStringWidth(run.text)
+	}
+}
+
+// labelValue writes "<b>label :</b> value" inside a borderless cell of width w
+func (d *pdfDocument) labelValue(w float64, label string, value string) {
+	label += " : "
+	d.setFont(true)
+	labelWidth := d.pdf.GetStringWidth(label) + 2*d.pdf.GetCellMargin()
+	d.text(labelWidth, label, "", "L", false)
+	d.cell(w-labelWidth, value, "", "L", false, nil)
+}
+
+// header mirrors the borderless borrower table of htmlBaseTpl
+func (d *pdfDocument) header(data HtmlData) {
+	d.labelValue(d.width*0.6, "ประเภทสินเชื่อ", data.EvaluateType)
+	d.pdf.Ln(pdfLineHeight)
+	d.labelValue(d.width*0.6, "ผู้กู้", data.BorrowerName)
+	d.labelValue(d.width*0.4, "เลขบัตรประชาชน", data.BorrowerIDCard)
+	d.pdf.Ln(pdfLineHeight)
+	for _, co := range data.CoBorrowers {
+		d.labelValue(d.width*0.6, co.Label, co.Name)
+		d.labelValue(d.width*0.4, "เลขบัตรประชาชน", co.IDCard)
+		d.pdf.Ln(pdfLineHeight)
+	}
+
+	d.pdf.Ln(pdfBlockGap)
+	d.cell(d.width, "สัดส่วนภาระผ่อนชำระหนี้รวมต่อรายได้สุทธิรวม (DTI) และ สัดส่วนความสามารถในการชำระหนี้ (DSCR)", "", "L", true, nil)
+	d.pdf.Ln(pdfLineHeight + pdfBlockGap)
+}
+
+// rows mirrors buildApplicantRowsHTML and buildDebtRowsHTML, with an optional header row
+func (d *pdfDocument) rows(title string, rows []exportRow) {
+	if title != "" {
+		d.cell(d.width, title, "1", "L", true, &pdfHeaderColor)
+		d.pdf.Ln(pdfLineHeight)
+	}
+	for _, row := range rows {
+		if row.Highlight {
+			d.cell(d.width*0.6, row.Label, "1", "C", true, &pdfHighlightColor)
+			d.cell(d.width*0.2, row.Value, "1", "R", true, &pdfHighlightColor)
+			d.cell(d.width*0.2, row.Unit, "1", "C", false, &pdfHighlightColor)
+		} else {
+			d.cell(d.width*0.6, row.Label, "1", "L", false, nil)
+			d.cell(d.width*0.2, row.Value, "1", "R", false, nil)
+			d.cell(d.width*0.2, row.Unit, "1", "C", false, nil)
+		}
+		d.pdf.Ln(pdfLineHeight)
+	}
+	d.pdf.Ln(pdfBlockGap)
+}
+
+// table writes a multi-column table with equal column widths, repeating the header on each page
+func (d *pdfDocument) table(t exportTable) {
+	width := d.width / float64(len(t.Header))
+	_, pageHeight := d.pdf.GetPageSize()
+
+	header := func() {
+		for _, title := range t.Header {
+			d.cell(width, title, "1", "C", true, &pdfHeaderColor)
+		}
+		d.pdf.Ln(pdfLineHeight)
+	}
+
+	header()
+	for _, cells := range t.Rows {
+		if d.pdf.GetY()+pdfLineHeight > pageHeight-pdfMargin {
+			d.pdf.AddPage()
+			header()
+		}
+		for i, cell := range cells {
+			align := "R"
+			if i == 0 {
+				align = "C"
+			}
+			d.cell(width, cell, "1", align, false, nil)
+		}
+		d.pdf.Ln(pdfLineHeight)
+	}
+	d.pdf.Ln(pdfBlockGap)
+}
+
+func (d *pdfDocument) ratios(data HtmlData) {
+	d.cell(d.width*0.8, "DTI (Debt to Income Ratio)", "1", "C", true, nil)
+	d.cell(d.width*0.2, data.DTI+" %", "1", "C", true, &pdfHighlightColor)
+	d.pdf.Ln(pdfLineHeight)
+	d.cell(d.width*0.8, "DSCR (Debt Service Coverage Ratio)", "1", "C", true, nil)
+	d.cell(d.width*0.2, data.DSCR+" เท่า", "1", "C", true, &pdfHighlightColor)
+	d.pdf.Ln(pdfLineHeight + pdfBlockGap)
+}
+
+// signature mirrors the .signature-box table of htmlBaseTpl
+func (d *pdfDocument) signature(data HtmlData) {
+	widths := [4]float64{d.width * 0.1, d.width * 0.4, d.width * 0.1, d.width * 0.4}
+
+	d.pdf.Ln(pdfBlockGap * 2)
+	for _, row := range signatureRows(data) {
+		for col, text := range row {
+			align := "C"
+			if col == 0 {
+				align = "L"
+			}
+			bold := col > 0 && (text == "ผู้จัดทำ" || text == "ผู้ตรวจสอบ")
+			d.cell(widths[col], text, "", align, bold, nil)
+		}
+		d.pdf.Ln(pdfLineHeight + 2)
+	}
+}
+
+func (d *pdfDocument) remark() {
+	d.pdf.Ln(pdfBlockGap)
+	d.pdf.SetFont(pdfFontFamily, "BU", pdfFontSize)
+	d.text(d.width, "หมายเหตุ", "", "L", false)
+	d.pdf.Ln(pdfLineHeight)
+	d.setFont(false)
+	for _, line := range d.pdf.SplitText("การคำนวณความสามารถในการชำระหนี้เป็นไปตามหลักเกณฑ์ เรื่อง การพิจารณาความสามารถในการชำระหนี้การให้สินเชื่อรายย่อยทุกประเภท", d.width) {
+		d.text(d.width, line, "", "L", false)
+		d.pdf.Ln(pdfLineHeight)
+	}
+}
+
+// ---- Main Function ----------------------------------------------------------
+
+// GenerateEvaluatePDF สร้างไฟล์ PDF ขนาด A4 ตามรูปแบบเดียวกับ htmlBaseTpl
+func GenerateEvaluatePDF(eval *models.Evaluate, detailed bool) ([]byte, error) {
+	font, err := loadExportFont()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Evaluate Result PDF", true)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", font.regular)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "B", font.bold)
+
+	pageWidth, _ := pdf.GetPageSize()
+	doc := &pdfDocument{pdf: pdf, font: font, width: pageWidth - 2*pdfMargin}
+	data := buildHtmlData(eval)
+
+	pdf.AddPage()
+	doc.header(data)
+
+	// แต่ละผู้กู้ขึ้นหน้าใหม่
+	for i, a := range eval.Result.Applicants {
+		if i > 0 {
+			pdf.AddPage()
+		}
+		doc.rows(applicantLabel(i), applicantExportRows(a))
+	}
+
+	doc.rows("", debtExportRows(eval.Result.DebtDetail))
+	doc.ratios(data)
+	doc.signature(data)
+	doc.remark()
+
+	// ตารางผ่อนชำระ
+	if eval.LoanTerms.Principal > 0 {
+		schedule := calculator.AmortizationSchedule(eval.LoanTerms)
+		pdf.AddPage()
+		doc.cell(doc.width, "ตารางผ่อนชำระ", "", "L", true, nil)
+		pdf.Ln(pdfLineHeight + pdfBlockGap)
+		doc.rows("", loanTermsExportRows(schedule))
+		doc.table(scheduleExportTable(schedule))
+	}
+
+	// รายละเอียดข้อมูลผู้กู้ หน้าละคน
+	if detailed {
+		for i, a := range eval.Applicants {
+			pdf.AddPage()
+			doc.cell(doc.width, worksheetTitle(i, a), "", "L", true, nil)
+			pdf.Ln(pdfLineHeight + pdfBlockGap)
+			for _, section := range applicantWorksheetSections(a) {
+				doc.rows(section.Title, section.Rows)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("pdf output failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
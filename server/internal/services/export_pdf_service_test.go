@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+// testThaiMetrics mimics a font that draws every mark for a short consonant without a stem
+var testThaiMetrics = &thaiMetrics{
+	glyphs: map[rune]glyphBox{
+		'ก': {minX: 0.05, maxX: 0.5, minY: 0, maxY: 0.5, advance: 0.55},
+		'ท': {minX: 0.05, maxX: 0.5, minY: 0, maxY: 0.5, advance: 0.55},
+		'น': {minX: 0.05, maxX: 0.5, minY: 0, maxY: 0.5, advance: 0.55},
+		'ป': {minX: 0.05, maxX: 0.5, minY: 0, maxY: 0.78, advance: 0.55},
+		'ฎ': {minX: 0.05, maxX: 0.5, minY: -0.25, maxY: 0.5, advance: 0.55},
+		'ี': {minX: -0.45, maxX: -0.05, minY: 0.55, maxY: 0.75},
+		'ํ': {minX: -0.3, maxX: -0.15, minY: 0.55, maxY: 0.7},
+		'่': {minX: -0.25, maxX: -0.05, minY: 0.55, maxY: 0.8},
+		'้': {minX: -0.3, maxX: -0.05, minY: 0.55, maxY: 0.85},
+		'ุ': {minX: -0.3, maxX: -0.15, minY: -0.25, maxY: -0.05},
+	},
+	stemLeft: map[rune]float64{'ป': 0.45},
+}
+
+func TestShapeThai(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		metrics *thaiMetrics
+		want    []thaiRun
+	}{
+		{"no marks", "กา 123", testThaiMetrics, []thaiRun{{text: "กา 123"}}},
+		{"mark in place", "กี", testThaiMetrics, []thaiRun{{text: "กี"}}},
+		// 0.75 + 0.04 - 0.55
+		{"tone stacked on upper vowel", "ที่", testThaiMetrics, []thaiRun{{text: "ที"}, {text: "่", dy: 0.24}}},
+		// 0.7 + 0.04 - 0.55
+		{"tone above sara am", "น้ำ", testThaiMetrics, []thaiRun{{text: "น"}, {text: "้", dy: 0.19}, {text: "ำ"}}},
+		// -0.05 - (0.45 - 0.55) + 0.04
+		{"tone beside ascender stem", "ป่า", testThaiMetrics, []thaiRun{{text: "ป"}, {text: "่", dx: -0.09}, {text: "า"}}},
+		{
+			"stacked marks on ascender", "ปี่",
+			testThaiMetrics,
+			[]thaiRun{{text: "ป"}, {text: "ี", dx: -0.09}, {text: "่", dx: -0.09, dy: 0.24}},
+		},
+		// -0.05 + 0.04 + 0.25
+		{"lower vowel under descender", "ฎุ", testThaiMetrics, []thaiRun{{text: "ฎ"}, {text: "ุ", dy: -0.24}}},
+		{"lower vowel under short consonant", "กุ", testThaiMetrics, []thaiRun{{text: "กุ"}}},
+		{"without metrics", "ที่", nil, []thaiRun{{text: "ที่"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shapeThai(tt.text, tt.metrics)
+			if len(got) != len(tt.want) {
+				t.Fatalf("shapeThai(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i].text != tt.want[i].text ||
+					math.Abs(got[i].dx-tt.want[i].dx) > 1e-9 || math.Abs(got[i].dy-tt.want[i].dy) > 1e-9 {
+					t.Fatalf("shapeThai(%q) = %+v, want %+v", tt.text, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateEvaluatePDFThaiMarks(t *testing.T) {
+	// Both weights must be committed; the PDF export fails on every deployment without them
+	for _, name := range []string{pdfFontRegular, pdfFontBold} {
+		if _, err := exportFontFS.ReadFile(name); err != nil {
+			t.Fatalf("%s is not embedded: commit the OFL Sarabun TTFs to internal/services/fonts", name)
+		}
+	}
+	font, err := loadExportFont()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stacked tone marks, an ascender and sara am in one name
+	name := "นายปี่ ที่น้ำใจ ฎีกาปุ้"
+	stacked := shapeThai(name, font.metrics)
+	moved := 0
+	for _, run := range stacked {
+		if run.dx != 0 || run.dy != 0 {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Errorf("shapeThai(%q) moved no marks: %+v", name, stacked)
+	}
+
+	evaluate := &models.Evaluate{
+		EvaluateType: "สินเชื่อทั่วไป",
+		Applicants:   []models.Applicant{{Name: name, IDCard: "1101700203450"}},
+		Result: models.EvaluateResult{
+			Applicants: []models.ResultApplicant{{Name: name, IDCard: "1101700203450", TotalSalary: 25000}},
+		},
+	}
+	data, err := GenerateEvaluatePDF(evaluate, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Errorf("output does not start with a PDF header")
+	}
+}
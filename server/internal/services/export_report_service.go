@@ -0,0 +1,216 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// evaluateExportBatchSize bounds how many evaluates (with their relations) are held in memory at once
+const evaluateExportBatchSize = 500
+
+// EvaluateExportFilter narrows the evaluates included in a batch export
+type EvaluateExportFilter struct {
+	CooperativeID string
+	Search        string
+	UserID        uuid.UUID // uuid.Nil = every officer
+	From          time.Time // zero = unbounded
+	To            time.Time // exclusive, zero = unbounded
+	Status        string
+	PerApplicant  bool // one row per applicant instead of one per evaluate
+}
+
+// StreamEvaluates walks every evaluate matching the filter, oldest first, in fixed-size batches
+// using keyset pagination so memory stays bounded however large the result is
+func StreamEvaluates(filter EvaluateExportFilter, handle func([]models.Evaluate) error) error {
+	var lastCreatedAt time.Time
+	lastID := uuid.Nil
+
+	for {
+		query := database.DB.Model(&models.Evaluate{}).
+			Preload("Applicants", applicantOrder).
+			Preload("Result").
+			Preload("Result.Applicants", applicantOrder).
+			Preload("User")
+		query = evaluateFilterQuery(query, filter.CooperativeID, filter.Search, filter.UserID)
+
+		if !filter.From.IsZero() {
+			query = query.Where("evaluates.created_at >= ?", filter.From)
+		}
+		if !filter.To.IsZero() {
+			query = query.Where("evaluates.created_at < ?", filter.To)
+		}
+		if filter.Status != "" {
+			query = query.Where("evaluates.status = ?", filter.Status)
+		}
+		if lastID != uuid.Nil {
+			query = query.Where("(evaluates.created_at, evaluates.id) > (?, ?)", lastCreatedAt, lastID)
+		}
+
+		var batch []models.Evaluate
+		if err := query.Order("evaluates.created_at ASC, evaluates.id ASC").
+			Limit(evaluateExportBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := handle(batch); err != nil {
+			return err
+		}
+
+		if len(batch) < evaluateExportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		lastCreatedAt, lastID = last.CreatedAt, last.Id
+	}
+}
+
+func evaluateReportHeader(perApplicant bool) []string {
+	header := []string{"เลขที่แบบประเมิน"}
+	if perApplicant {
+		header = append(header, "ผู้กู้/ผู้ร่วม")
+	}
+	return append(header,
+		"ชื่อ-นามสกุล", "เลขบัตรประชาชน", "ประเภทสินเชื่อ", "ประเภทอัตรากำไร",
+		"ภาระผ่อนชำระหนี้รวม", "DTI (%)", "DSCR", "สถานะ", "เจ้าหน้าที่", "วันที่สร้าง", "วันที่แก้ไขล่าสุด",
+	)
+}
+
+// evaluateReportRows flattens an evaluate into report rows; numbers stay numeric so spreadsheets can sum them
+func evaluateReportRows(e models.Evaluate, perApplicant bool) [][]interface{} {
+	officer := ""
+	if e.User != nil {
+		officer = e.User.FullName
+	}
+
+	type person struct{ name, idCard string }
+	var people []person
+	for _, a := range e.Applicants {
+		people = append(people, person{a.Name, a.IDCard})
+	}
+	if len(people) == 0 {
+		for _, a := range e.Result.Applicants {
+			people = append(people, person{a.Name, a.IDCard})
+		}
+	}
+	if len(people) == 0 {
+		people = []person{{}}
+	}
+	if !perApplicant {
+		people = people[:1]
+	}
+
+	rows := make([][]interface{}, 0, len(people))
+	for i, p := range people {
+		row := []interface{}{e.Id.String()}
+		if perApplicant {
+			row = append(row, applicantLabel(i))
+		}
+		row = append(row,
+			p.name, p.idCard, e.EvaluateType, e.MarginType,
+			e.Result.DebtDetail.TotalDebt, e.Result.Dti, e.Result.Dscr, e.Status, officer,
+			e.CreatedAt.Format("2006-01-02 15:04:05"), e.UpdatedAt.Format("2006-01-02 15:04:05"),
+		)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// WriteEvaluatesCSV streams the evaluate report as CSV, flushing after every batch
+func WriteEvaluatesCSV(w io.Writer, filter EvaluateExportFilter) error {
+	// UTF-8 BOM so Excel opens Thai text correctly
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(evaluateReportHeader(filter.PerApplicant)); err != nil {
+		return err
+	}
+
+	err := StreamEvaluates(filter, func(batch []models.Evaluate) error {
+		for _, e := range batch {
+			for _, row := range evaluateReportRows(e, filter.PerApplicant) {
+				record := make([]string, len(row))
+				for i, value := range row {
+					if number, ok := value.(float64); ok {
+						record[i] = fmt.Sprintf("%.2f", number)
+					} else {
+						record[i] = fmt.Sprint(value)
+					}
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteEvaluatesXLSX streams the evaluate report into an Excel sheet; excelize's stream writer
+// spills rows to a temporary file instead of keeping the sheet in memory
+func WriteEvaluatesXLSX(w io.Writer, filter EvaluateExportFilter) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Evaluates"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := evaluateReportHeader(filter.PerApplicant)
+	headerRow := make([]interface{}, len(header))
+	for i, title := range header {
+		headerRow[i] = title
+	}
+	if err := stream.SetRow("A1", headerRow); err != nil {
+		return err
+	}
+
+	rowIndex := 2
+	err = StreamEvaluates(filter, func(batch []models.Evaluate) error {
+		for _, e := range batch {
+			for _, row := range evaluateReportRows(e, filter.PerApplicant) {
+				cell, err := excelize.CoordinatesToCellName(1, rowIndex)
+				if err != nil {
+					return err
+				}
+				if err := stream.SetRow(cell, row); err != nil {
+					return err
+				}
+				rowIndex++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The fake database below stands in for Postgres in service tests. It records every statement so
+// tests can assert on the SQL a service generates, and answers with one canned row per query.
+//
+// Rows of the tenant tables belong to ownerCooperative. A statement that pins cooperative_id to any
+// other cooperative sees none of them; one that forgets the filter sees them, as it would on Postgres.
+const (
+	ownerCooperative = "1111111111111"
+	otherCooperative = "2222222222222"
+
+	// fakeIDCard is the ID card of the stored member; it passes the checksum
+	fakeIDCard = "1101700203450"
+	// fakeRole is the role of every stored admin
+	fakeRole = "TESTER"
+)
+
+// tenantTables carry a cooperative_id column
+var tenantTables = map[string]bool{
+	"members":           true,
+	"evaluates":         true,
+	"evaluate_logs":     true,
+	"evaluate_policies": true,
+}
+
+var (
+	tablePattern       = regexp.MustCompile(`(?i)\b(?:from|update|into|join)\s+"?(\w+)"?`)
+	cooperativePattern = regexp.MustCompile(`cooperative_id"?\s*=\s*\$(\d+)`)
+	columnPattern      = regexp.MustCompile(`(?i)^select (?:distinct )?"?(\w+)"? from`)
+	parentPattern      = regexp.MustCompile(`"?(evaluate_id|result_id)"?\s*(?:=|IN\s*\()\s*\$(\d+)`)
+)
+
+type fakeQuery struct {
+	sql  string
+	args []driver.Value
+}
+
+// tables lists every table the statement reads or writes, subqueries included
+func (q fakeQuery) tables() map[string]bool {
+	tables := map[string]bool{}
+	for _, match := range tablePattern.FindAllStringSubmatch(q.sql, -1) {
+		tables[strings.ToLower(match[1])] = true
+	}
+	return tables
+}
+
+// cooperatives lists the values the statement compares cooperative_id with
+func (q fakeQuery) cooperatives() []driver.Value {
+	var values []driver.Value
+	for _, match := range cooperativePattern.FindAllStringSubmatch(q.sql, -1) {
+		n, _ := strconv.Atoi(match[1])
+		if n >= 1 && n <= len(q.args) {
+			values = append(values, q.args[n-1])
+		}
+	}
+	return values
+}
+
+// visible reports whether the statement can see the owner's rows
+func (q fakeQuery) visible() bool {
+	for _, value := range q.cooperatives() {
+		if value != ownerCooperative {
+			return false
+		}
+	}
+	return true
+}
+
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []fakeQuery
+	// permissions of fakeRole
+	permissions []string
+	// answer, when set, replaces the canned rows of the queries it accepts
+	answer func(q fakeQuery) (*fakeRows, bool)
+}
+
+func (db *fakeDB) record(query string, args []driver.NamedValue) fakeQuery {
+	q := fakeQuery{sql: query}
+	for _, arg := range args {
+		q.args = append(q.args, arg.Value)
+	}
+	db.mu.Lock()
+	db.queries = append(db.queries, q)
+	db.mu.Unlock()
+	return q
+}
+
+func (db *fakeDB) recorded() []fakeQuery {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]fakeQuery(nil), db.queries...)
+}
+
+// writes lists the statements that changed data
+func (db *fakeDB) writes() []string {
+	var writes []string
+	for _, q := range db.recorded() {
+		verb := strings.ToUpper(strings.Fields(q.sql)[0])
+		if verb == "UPDATE" || verb == "DELETE" || verb == "INSERT" {
+			writes = append(writes, q.sql)
+		}
+	}
+	return writes
+}
+
+// touched reports whether any statement read or wrote the table
+func (db *fakeDB) touched(table string) bool {
+	for _, q := range db.recorded() {
+		if q.tables()[table] {
+			return true
+		}
+	}
+	return false
+}
+
+// unscoped lists the statements on any of the tables that do not limit cooperative_id to the
+// cooperative. It fails the test when none of the tables was queried at all.
+func (db *fakeDB) unscoped(t *testing.T, cooperativeID string, tables ...string) []string {
+	t.Helper()
+	var unscoped []string
+	queried := false
+	for _, q := range db.recorded() {
+		touched := q.tables()
+		hit := false
+		for _, table := range tables {
+			hit = hit || touched[table]
+		}
+		if !hit {
+			continue
+		}
+		queried = true
+
+		scoped := false
+		for _, value := range q.cooperatives() {
+			scoped = scoped || value == cooperativeID
+		}
+		if !scoped {
+			unscoped = append(unscoped, q.sql)
+		}
+	}
+	if !queried {
+		t.Fatalf("none of %v was queried", tables)
+	}
+	return unscoped
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { c.db.record("BEGIN", nil); return c, nil }
+func (c *fakeConn) Commit() error                       { c.db.record("COMMIT", nil); return nil }
+func (c *fakeConn) Rollback() error                     { c.db.record("ROLLBACK", nil); return nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if q := c.db.record(query, args); q.visible() {
+		return driver.RowsAffected(1), nil
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q := c.db.record(query, args)
+	if c.db.answer != nil {
+		if rows, ok := c.db.answer(q); ok {
+			return rows, nil
+		}
+	}
+
+	visible := q.visible()
+	lower := strings.ToLower(query)
+	switch {
+	case strings.HasPrefix(lower, "select count("):
+		count := int64(0)
+		if visible {
+			count = 1
+		}
+		return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{count}}}, nil
+	case strings.Contains(lower, "group by"):
+		if !visible {
+			return &fakeRows{columns: []string{"year", "count"}}, nil
+		}
+		return &fakeRows{columns: []string{"year", "count"}, values: [][]driver.Value{{int64(2567), int64(1)}}}, nil
+	case columnPattern.MatchString(query):
+		// Plucked columns come back on their own
+		column := columnPattern.FindStringSubmatch(query)[1]
+		var value driver.Value = "ในเมือง"
+		if column == "id" || strings.HasSuffix(column, "_id") {
+			value = uuid.NewString()
+		}
+		if !visible {
+			return &fakeRows{columns: []string{column}}, nil
+		}
+		return &fakeRows{columns: []string{column}, values: [][]driver.Value{{value}}}, nil
+	case strings.Contains(lower, "sum("):
+		total := float64(0)
+		if visible {
+			total = 5000
+		}
+		return &fakeRows{columns: []string{"total"}, values: [][]driver.Value{{total}}}, nil
+	}
+
+	columns := []string{"id", "cooperative_id", "revision", "status", "created_at"}
+	row := []driver.Value{uuid.NewString(), ownerCooperative, int64(1), models.EvaluateStatusDraft, time.Now()}
+	// Preloaded children point at the parent they were asked for
+	for _, match := range parentPattern.FindAllStringSubmatch(query, -1) {
+		if n, _ := strconv.Atoi(match[2]); n <= len(q.args) && !slices.Contains(columns, match[1]) {
+			columns = append(columns, match[1])
+			row = append(row, q.args[n-1])
+		}
+	}
+	tables := q.tables()
+	switch {
+	// Only members carry a string member number; elsewhere member_id is a UUID
+	case tables["members"]:
+		columns = append(columns, "id_card", "member_id", "full_name")
+		row = append(row, fakeIDCard, "M0001", "สมาชิกสหกรณ์")
+	case tables["admins"]:
+		columns = append(columns, "role")
+		row = append(row, fakeRole)
+	case tables["roles"]:
+		permissions, _ := json.Marshal(c.db.permissions)
+		columns = append(columns, "name", "permissions")
+		row = append(row, fakeRole, permissions)
+	}
+	if !visible {
+		return &fakeRows{columns: columns}, nil
+	}
+	return &fakeRows{columns: columns, values: [][]driver.Value{row}}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// useFakeDB points database.DB at a fresh fake database for the test
+func useFakeDB(t *testing.T) *fakeDB {
+	t.Helper()
+	fake := &fakeDB{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fake)}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return fake
+}
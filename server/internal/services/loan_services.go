@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/calculator"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+var ErrNoLoanTerms = errors.New("แบบประเมินนี้ยังไม่ได้ระบุเงื่อนไขเงินกู้")
+
+// GetEvaluateSchedule returns the amortization schedule of an evaluate's loan terms
+func GetEvaluateSchedule(cooperativeID string, evaluateID uuid.UUID, readerID uuid.UUID) (*models.LoanSchedule, error) {
+	readable, err := readableEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var evaluate models.Evaluate
+	if err := database.DB.Scopes(readable).Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
+		return nil, errors.New("ไม่พบข้อมูลการประเมิน")
+	}
+
+	if evaluate.LoanTerms.Principal <= 0 {
+		return nil, ErrNoLoanTerms
+	}
+
+	schedule := calculator.AmortizationSchedule(evaluate.LoanTerms)
+	return &schedule, nil
+}
+
+var ErrNoLoanLimit = errors.New("ไม่พบเกณฑ์ DTI หรือ DSCR สำหรับคำนวณวงเงินกู้สูงสุด")
+
+// CalculateMaxLoan solves for the largest affordable loan, using the request's caps or else the
+// policy of its loan type and margin type
+func CalculateMaxLoan(cooperativeID string, request *models.MaxLoanRequest) (*models.MaxLoanResult, error) {
+	margins, err := applicantMargins(request.Applicants, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	maxDti, minDscr := request.MaxDti, request.MinDscr
+	if maxDti == 0 || minDscr == 0 {
+		policy, err := GetPolicyFor(cooperativeID, request.EvaluateType, request.MarginType)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			if maxDti == 0 {
+				maxDti = policy.MaxDti
+			}
+			if minDscr == 0 {
+				minDscr = policy.MinDscr
+			}
+		}
+	}
+
+	if maxDti <= 0 && minDscr <= 0 {
+		return nil, ErrNoLoanLimit
+	}
+
+	result := calculator.MaxLoan(&request.EvaluateRequest, request.LoanTerms, maxDti, minDscr, careerMarginLookup(margins))
+	return &result, nil
+}
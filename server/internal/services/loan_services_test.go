@@ -0,0 +1,87 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+func TestCalculateMaxLoanFallsBackToPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxDti, minDscr float64
+		// policy is the stored policy's caps, nil when the cooperative has none
+		policy      []float64
+		wantDti     float64
+		wantDscr    float64
+		err         error
+		readsPolicy bool
+	}{
+		{"request caps win", 30, 2, []float64{40, 1.5}, 30, 2, nil, false},
+		{"policy fills both", 0, 0, []float64{40, 1.5}, 40, 1.5, nil, true},
+		{"policy fills dscr", 30, 0, []float64{40, 1.5}, 30, 1.5, nil, true},
+		{"policy fills dti", 0, 2, []float64{40, 1.5}, 40, 2, nil, true},
+		{"no policy keeps the request cap", 30, 0, nil, 30, 0, nil, true},
+		{"no policy and no caps", 0, 0, nil, 0, 0, ErrNoLoanLimit, true},
+		{"policy without caps", 0, 0, []float64{0, 0}, 0, 0, ErrNoLoanLimit, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = func(q fakeQuery) (*fakeRows, bool) {
+				if !q.tables()["evaluate_policies"] {
+					return nil, false
+				}
+				rows := &fakeRows{columns: []string{"cooperative_id", "max_dti", "min_dscr"}}
+				if tt.policy != nil && q.visible() {
+					rows.values = [][]driver.Value{{ownerCooperative, tt.policy[0], tt.policy[1]}}
+				}
+				return rows, true
+			}
+
+			request := &models.MaxLoanRequest{
+				EvaluateRequest: models.EvaluateRequest{
+					Applicants: []models.ApplicantRequest{{Name: "ผู้กู้", IDCard: fakeIDCard, Salary: models.Salary{Base: 50000}}},
+					Result: models.EvaluateResultRequest{
+						Applicants: []models.ResultApplicantRequest{{IDCard: fakeIDCard, LivingExpenses: 10000}},
+					},
+					LoanTerms: models.LoanTerms{AnnualRate: 6, TermMonths: 12, Method: models.LoanMethodFlat},
+				},
+				MaxDti:  tt.maxDti,
+				MinDscr: tt.minDscr,
+			}
+
+			got, err := CalculateMaxLoan(ownerCooperative, request)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CalculateMaxLoan() error = %v, want %v", err, tt.err)
+			}
+			if db.touched("evaluate_policies") != tt.readsPolicy {
+				t.Errorf("read the policy = %v, want %v", !tt.readsPolicy, tt.readsPolicy)
+			}
+			if tt.readsPolicy {
+				assertScoped(t, db, ownerCooperative, "evaluate_policies")
+			}
+			if tt.err != nil {
+				return
+			}
+
+			if limit := capOf(got.Dti); limit != tt.wantDti {
+				t.Errorf("DTI cap = %v, want %v", limit, tt.wantDti)
+			}
+			if limit := capOf(got.Dscr); limit != tt.wantDscr {
+				t.Errorf("DSCR cap = %v, want %v", limit, tt.wantDscr)
+			}
+		})
+	}
+}
+
+// capOf is the cap a limit applied, zero when it was not applied
+func capOf(limit *models.MaxLoanLimit) float64 {
+	if limit == nil {
+		return 0
+	}
+	return limit.Limit
+}
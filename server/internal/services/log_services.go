@@ -5,10 +5,17 @@ import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 )
 
-func GetEvaluateLogs(search string, page int, limit int) ([]models.EvaluateLog, int64, error) {
+// GetEvaluateLogs lists the cooperative's log entries. includeShared adds entries that belong to no
+// cooperative, such as lockouts of IPs and unknown usernames.
+func GetEvaluateLogs(cooperativeID string, includeShared bool, search string, page int, limit int) ([]models.EvaluateLog, int64, error) {
 	var logs []models.EvaluateLog
 	var total int64
 	query := database.DB.Model(&models.EvaluateLog{})
+	if includeShared {
+		query = query.Where("cooperative_id IN ?", []string{cooperativeID, ""})
+	} else {
+		query = query.Scopes(cooperativeScope(cooperativeID))
+	}
 
 	if search != "" {
 		searchPattern := "%" + search + "%"
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Failed sign-ins older than loginFailureWindow are forgotten. Past the free attempts each failure
+// opens a rejection window twice as long as the previous one, up to loginMaxDelay, in which attempts
+// are refused with 429 at once; nothing is slowed down or held. Reaching the lock threshold refuses
+// the username or IP for loginLockDuration.
+const (
+	loginFailureWindow    = 15 * time.Minute
+	loginMaxDelay         = 30 * time.Second
+	loginLockDuration     = 15 * time.Minute
+	loginThrottleValueMax = 64
+)
+
+// loginThrottlePolicies are looser per IP since an office shares one address between tellers
+var loginThrottlePolicies = map[string]struct{ freeAttempts, lockAt int }{
+	models.LoginThrottleUsername: {freeAttempts: 3, lockAt: 5},
+	models.LoginThrottleIP:       {freeAttempts: 10, lockAt: 20},
+}
+
+var (
+	ErrInvalidCredentials = errors.New("เลขบัตรประชาชนหรือรหัสผ่านไม่ถูกต้อง")
+	ErrLoginThrottled     = errors.New("เข้าสู่ระบบไม่สำเร็จหลายครั้ง กรุณารอสักครู่แล้วลองใหม่")
+	ErrLoginLocked        = errors.New("เข้าสู่ระบบไม่สำเร็จหลายครั้งเกินไป ระบบระงับการเข้าสู่ระบบชั่วคราว")
+	ErrLoginLockNotFound  = errors.New("ไม่พบรายการที่ถูกระงับการเข้าสู่ระบบ")
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// LoginBlockedError tells the caller how long to wait before the next sign-in attempt
+type LoginBlockedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string { return e.Err.Error() }
+func (e *LoginBlockedError) Unwrap() error { return e.Err }
+
+func loginThrottleValue(value string) string {
+	if len(value) > loginThrottleValueMax {
+		return value[:loginThrottleValueMax]
+	}
+	return value
+}
+
+// loginDelay is how long attempts from a username or IP are refused after the given number of failures
+func loginDelay(kind string, failures int) time.Duration {
+	freeAttempts := loginThrottlePolicies[kind].freeAttempts
+	if failures < freeAttempts {
+		return 0
+	}
+	delay := time.Second << (failures - freeAttempts)
+	if delay > loginMaxDelay || delay <= 0 {
+		return loginMaxDelay
+	}
+	return delay
+}
+
+// CheckLoginAllowed refuses a sign-in attempt while the username or IP is locked or inside the
+// rejection window of its last failure
+func CheckLoginAllowed(username string, ip string) error {
+	var throttles []models.LoginThrottle
+	if err := database.DB.
+		Where("(kind = ? AND value = ?) OR (kind = ? AND value = ?)",
+			models.LoginThrottleUsername, loginThrottleValue(username), models.LoginThrottleIP, ip).
+		Find(&throttles).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var blocked *LoginBlockedError
+	for _, throttle := range throttles {
+		var wait time.Duration
+		var reason error
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			wait, reason = throttle.LockedUntil.Sub(now), ErrLoginLocked
+		} else if now.Sub(throttle.LastFailureAt) < loginFailureWindow {
+			if until := throttle.LastFailureAt.Add(loginDelay(throttle.Kind, throttle.Failures)); until.After(now) {
+				wait, reason = until.Sub(now), ErrLoginThrottled
+			}
+		}
+		if reason != nil && (blocked == nil || wait > blocked.RetryAfter) {
+			blocked = &LoginBlockedError{Err: reason, RetryAfter: wait}
+		}
+	}
+	if blocked != nil {
+		return blocked
+	}
+	return nil
+}
+
+// AuthenticateAdmin checks a username and password. Unknown usernames and wrong passwords both return
+// ErrInvalidCredentials after the same bcrypt work, so neither the response nor its timing tells them apart.
+func AuthenticateAdmin(username string, password string) (*models.Admin, error) {
+	var admin models.Admin
+	if err := database.DB.Where("username = ?", username).Limit(1).Find(&admin).Error; err != nil {
+		return nil, err
+	}
+
+	if admin.Id == uuid.Nil {
+		dummyPasswordHashOnce.Do(func() {
+			dummyPasswordHash, _ = HashPassword(uuid.NewString())
+		})
+		VerifyPassword(password, dummyPasswordHash)
+		return nil, ErrInvalidCredentials
+	}
+
+	if !VerifyPassword(password, admin.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	return &admin, nil
+}
+
+// RecordLoginFailure counts a failed sign-in against the username and the IP and locks whichever
+// reaches its threshold. Every lockout is written to the audit log.
+func RecordLoginFailure(username string, ip string) error {
+	username = loginThrottleValue(username)
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := countLoginFailure(tx, models.LoginThrottleUsername, username, username); err != nil {
+			return err
+		}
+		return countLoginFailure(tx, models.LoginThrottleIP, ip, username)
+	})
+}
+
+func countLoginFailure(tx *gorm.DB, kind string, value string, username string) error {
+	now := time.Now()
+
+	// Failures outside the window start the count again
+	var throttle models.LoginThrottle
+	if err := tx.Raw(`INSERT INTO login_throttles (kind, value, failures, last_username, last_failure_at)
+		VALUES (?, ?, 1, ?, ?)
+		ON CONFLICT (kind, value) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+			last_username = EXCLUDED.last_username,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING *`, kind, value, username, now, now.Add(-loginFailureWindow)).
+		Scan(&throttle).Error; err != nil {
+		return err
+	}
+
+	if throttle.Failures != loginThrottlePolicies[kind].lockAt {
+		return nil
+	}
+
+	lockedUntil := now.Add(loginLockDuration)
+	if err := tx.Model(&models.LoginThrottle{}).Where("id = ?", throttle.Id).
+		Update("locked_until", lockedUntil).Error; err != nil {
+		return err
+	}
+
+	action := fmt.Sprintf("ระงับการเข้าสู่ระบบของเลขบัตรประชาชน %s เป็นเวลา %d นาที หลังเข้าสู่ระบบไม่สำเร็จ %d ครั้ง",
+		value, int(loginLockDuration.Minutes()), throttle.Failures)
+	if kind == models.LoginThrottleIP {
+		action = fmt.Sprintf("ระงับการเข้าสู่ระบบจาก IP %s เป็นเวลา %d นาที หลังเข้าสู่ระบบไม่สำเร็จ %d ครั้ง",
+			value, int(loginLockDuration.Minutes()), throttle.Failures)
+	}
+
+	// A locked account is logged under the account itself, anything else as the username that was tried
+	actor := models.Admin{Username: username}
+	if kind == models.LoginThrottleUsername {
+		if err := tx.Where("username = ?", value).Limit(1).Find(&actor).Error; err != nil {
+			return err
+		}
+		actor.Username = value
+	}
+	return writeLoginAuditLog(tx, &throttle, &actor, action)
+}
+
+// ResetLoginFailures forgets the failed sign-ins of a username after a successful sign-in. The IP
+// count is kept so one valid account cannot be used to keep guessing others.
+func ResetLoginFailures(username string) error {
+	return database.DB.
+		Where("kind = ? AND value = ? AND (locked_until IS NULL OR locked_until <= ?)",
+			models.LoginThrottleUsername, loginThrottleValue(username), time.Now()).
+		Delete(&models.LoginThrottle{}).Error
+}
+
+// GetLoginLockouts lists usernames and IPs that are locked right now
+func GetLoginLockouts() ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	if err := database.DB.Where("locked_until > ?", time.Now()).
+		Order("locked_until DESC").Find(&throttles).Error; err != nil {
+		return nil, err
+	}
+	return throttles, nil
+}
+
+// UnlockLogin lifts a lockout before it expires and writes the unlock to the audit log
+func UnlockLogin(id uuid.UUID, actorID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var throttle models.LoginThrottle
+		if err := tx.Where("id = ? AND locked_until > ?", id, time.Now()).Limit(1).Find(&throttle).Error; err != nil {
+			return err
+		}
+		if throttle.Id == uuid.Nil {
+			return ErrLoginLockNotFound
+		}
+
+		var actor models.Admin
+		if err := tx.Where("id = ?", actorID).First(&actor).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&throttle).Error; err != nil {
+			return err
+		}
+
+		action := fmt.Sprintf("ปลดการระงับการเข้าสู่ระบบของเลขบัตรประชาชน %s", throttle.Value)
+		if throttle.Kind == models.LoginThrottleIP {
+			action = fmt.Sprintf("ปลดการระงับการเข้าสู่ระบบจาก IP %s", throttle.Value)
+		}
+		return writeLoginAuditLog(tx, &throttle, &actor, action)
+	})
+}
+
+// writeLoginAuditLog records a lockout or unlock in the evaluate log. A locked account's entry goes to
+// each of its cooperatives; IPs and unknown usernames belong to no cooperative and are shown to admins
+// with tenant:switch.
+func writeLoginAuditLog(tx *gorm.DB, throttle *models.LoginThrottle, actor *models.Admin, action string) error {
+	cooperativeIDs := []string{""}
+	if throttle.Kind == models.LoginThrottleUsername {
+		var assigned []string
+		if err := tx.Table("admin_cooperatives").
+			Joins("JOIN admins ON admins.id = admin_cooperatives.admin_id").
+			Where("admins.username = ?", throttle.Value).
+			Order("cooperative_id ASC").
+			Pluck("cooperative_id", &assigned).Error; err != nil {
+			return err
+		}
+		if len(assigned) > 0 {
+			cooperativeIDs = assigned
+		}
+	}
+
+	for _, cooperativeID := range cooperativeIDs {
+		evaluateLog := models.EvaluateLog{
+			CooperativeID: cooperativeID,
+			Action:        action,
+			Username:      actor.Username,
+			FullName:      actor.FullName,
+			Role:          actor.Role,
+			Timestamp:     time.Now(),
+		}
+		if err := tx.Create(&evaluateLog).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
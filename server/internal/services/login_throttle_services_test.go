@@ -0,0 +1,196 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		kind     string
+		failures int
+		want     time.Duration
+	}{
+		// Three free attempts per username, then 1s, 2s, 4s ...
+		{models.LoginThrottleUsername, 2, 0},
+		{models.LoginThrottleUsername, 3, time.Second},
+		{models.LoginThrottleUsername, 4, 2 * time.Second},
+		{models.LoginThrottleUsername, 7, 16 * time.Second},
+		{models.LoginThrottleUsername, 8, loginMaxDelay},
+		// Ten per IP
+		{models.LoginThrottleIP, 9, 0},
+		{models.LoginThrottleIP, 10, time.Second},
+		{models.LoginThrottleIP, 12, 4 * time.Second},
+		// Far past the cap the shift overflows; the delay must not wrap to zero
+		{models.LoginThrottleIP, 200, loginMaxDelay},
+	}
+
+	for _, tt := range tests {
+		if got := loginDelay(tt.kind, tt.failures); got != tt.want {
+			t.Errorf("loginDelay(%s, %d) = %v, want %v", tt.kind, tt.failures, got, tt.want)
+		}
+	}
+}
+
+// loginFailures answers the failure count upsert with the count the row reaches for each kind
+func loginFailures(username int, ip int) func(q fakeQuery) (*fakeRows, bool) {
+	return func(q fakeQuery) (*fakeRows, bool) {
+		if !strings.HasPrefix(q.sql, "INSERT INTO login_throttles") {
+			return nil, false
+		}
+		failures := username
+		if q.args[0] == models.LoginThrottleIP {
+			failures = ip
+		}
+		return &fakeRows{
+			columns: []string{"id", "kind", "value", "failures", "last_failure_at"},
+			values:  [][]driver.Value{{uuid.NewString(), q.args[0], q.args[1], int64(failures), time.Now()}},
+		}, true
+	}
+}
+
+func TestRecordLoginFailureLocksAtThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		username int
+		ip       int
+		locks    []string
+	}{
+		{"below both", 4, 19, nil},
+		{"username reaches five", 5, 19, []string{"เลขบัตรประชาชน " + fakeIDCard}},
+		{"ip reaches twenty", 4, 20, []string{"IP 10.0.0.1"}},
+		{"both", 5, 20, []string{"เลขบัตรประชาชน " + fakeIDCard, "IP 10.0.0.1"}},
+		// Failures past the threshold while locked do not lock or log again
+		{"past both", 6, 21, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = loginFailures(tt.username, tt.ip)
+
+			if err := RecordLoginFailure(fakeIDCard, "10.0.0.1"); err != nil {
+				t.Fatal(err)
+			}
+
+			var locks, logs []fakeQuery
+			for _, q := range db.recorded() {
+				switch {
+				case strings.Contains(q.sql, `UPDATE "login_throttles" SET "locked_until"=`):
+					locks = append(locks, q)
+				case strings.HasPrefix(q.sql, `INSERT INTO "evaluate_logs"`):
+					logs = append(logs, q)
+				}
+			}
+			if len(locks) != len(tt.locks) || len(logs) != len(tt.locks) {
+				t.Fatalf("got %d locks and %d audit entries, want %d", len(locks), len(logs), len(tt.locks))
+			}
+			for i, want := range tt.locks {
+				action := ""
+				for _, arg := range logs[i].args {
+					if s, ok := arg.(string); ok && strings.HasPrefix(s, "ระงับการเข้าสู่ระบบ") {
+						action = s
+					}
+				}
+				if !strings.Contains(action, want) {
+					t.Errorf("audit entry %q does not name %q", action, want)
+				}
+			}
+			if !strings.Contains(db.inTransaction(), "INSERT INTO login_throttles") {
+				t.Error("failures are not counted in a transaction")
+			}
+		})
+	}
+}
+
+func TestCheckLoginAllowed(t *testing.T) {
+	now := time.Now()
+	throttle := func(kind string, failures int, lastFailure time.Duration, lockedFor time.Duration) []driver.Value {
+		var lockedUntil driver.Value
+		if lockedFor != 0 {
+			lockedUntil = now.Add(lockedFor)
+		}
+		return []driver.Value{uuid.NewString(), kind, "x", int64(failures), now.Add(-lastFailure), lockedUntil}
+	}
+
+	tests := []struct {
+		name      string
+		throttles [][]driver.Value
+		err       error
+		wait      time.Duration
+	}{
+		{"no failures", nil, nil, 0},
+		{"free attempts", [][]driver.Value{throttle(models.LoginThrottleUsername, 2, 0, 0)}, nil, 0},
+		{"inside the delay", [][]driver.Value{throttle(models.LoginThrottleUsername, 4, 0, 0)}, ErrLoginThrottled, 2 * time.Second},
+		{"delay over", [][]driver.Value{throttle(models.LoginThrottleUsername, 4, 3*time.Second, 0)}, nil, 0},
+		// Failures older than the window no longer count
+		{"window over", [][]driver.Value{throttle(models.LoginThrottleUsername, 8, loginFailureWindow+time.Second, 0)}, nil, 0},
+		{"locked", [][]driver.Value{throttle(models.LoginThrottleUsername, 5, 0, 10*time.Minute)}, ErrLoginLocked, 10 * time.Minute},
+		{"lock expired", [][]driver.Value{throttle(models.LoginThrottleUsername, 5, loginFailureWindow, -time.Second)}, nil, 0},
+		// The IP allows more attempts than a username
+		{"ip free attempts", [][]driver.Value{throttle(models.LoginThrottleIP, 9, 0, 0)}, nil, 0},
+		{
+			"longest wait wins",
+			[][]driver.Value{throttle(models.LoginThrottleUsername, 4, 0, 0), throttle(models.LoginThrottleIP, 20, 0, 15*time.Minute)},
+			ErrLoginLocked, 15 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = func(q fakeQuery) (*fakeRows, bool) {
+				if !q.tables()["login_throttles"] {
+					return nil, false
+				}
+				return &fakeRows{
+					columns: []string{"id", "kind", "value", "failures", "last_failure_at", "locked_until"},
+					values:  tt.throttles,
+				}, true
+			}
+
+			err := CheckLoginAllowed(fakeIDCard, "10.0.0.1")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CheckLoginAllowed() error = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil {
+				return
+			}
+			var blocked *LoginBlockedError
+			if !errors.As(err, &blocked) || blocked.RetryAfter > tt.wait || blocked.RetryAfter < tt.wait-time.Second {
+				t.Errorf("retry after %v, want about %v", blocked.RetryAfter, tt.wait)
+			}
+		})
+	}
+}
+
+func TestResetLoginFailuresKeepsIPAndLocks(t *testing.T) {
+	db := useFakeDB(t)
+
+	if err := ResetLoginFailures(fakeIDCard); err != nil {
+		t.Fatal(err)
+	}
+
+	var deletes []fakeQuery
+	for _, q := range db.recorded() {
+		if strings.HasPrefix(q.sql, "DELETE") {
+			deletes = append(deletes, q)
+		}
+	}
+	if len(deletes) != 1 || len(db.writes()) != 1 {
+		t.Fatalf("writes = %v, want one delete", db.writes())
+	}
+	q := deletes[0]
+	if !strings.HasPrefix(q.sql, `DELETE FROM "login_throttles"`) || !strings.Contains(q.sql, "locked_until IS NULL OR locked_until <=") {
+		t.Errorf("reset = %s", q.sql)
+	}
+	if q.args[0] != models.LoginThrottleUsername || q.args[1] != fakeIDCard {
+		t.Errorf("reset deletes %v, want the username only", q.args)
+	}
+}
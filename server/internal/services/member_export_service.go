@@ -0,0 +1,206 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// memberExportBatchSize bounds how many members are held in memory at once
+const memberExportBatchSize = 1000
+
+// memberExportHeader follows the SeedMemberData JSON fields, which the member import also accepts
+var memberExportHeader = []string{
+	"cooperativeId", "idCard", "accountYear", "memberId", "fullName", "nationality", "sharesNum", "sharesValue",
+	"joiningDate", "memberType", "leavingDate", "address", "moo", "subdistrict", "district", "province",
+}
+
+// StreamMembers walks every member matching the filter ordered by member ID, in fixed-size batches
+func StreamMembers(filter MemberFilter, handle func([]models.Member) error) error {
+	lastMemberID := ""
+	for {
+		query := memberFilterQuery(database.DB.Model(&models.Member{}), filter)
+		if lastMemberID != "" {
+			query = query.Where("member_id > ?", lastMemberID)
+		}
+
+		var batch []models.Member
+		if err := query.Order("member_id ASC").Limit(memberExportBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := handle(batch); err != nil {
+			return err
+		}
+
+		if len(batch) < memberExportBatchSize {
+			return nil
+		}
+		lastMemberID = batch[len(batch)-1].MemberId
+	}
+}
+
+// ToSeedMemberData converts a member back into the seed file shape
+func ToSeedMemberData(member models.Member) SeedMemberData {
+	seed := SeedMemberData{
+		FullName:    member.FullName,
+		Nationality: member.Nationality,
+		SharesNum:   member.SharesNum,
+		SharesValue: member.SharesValue,
+		MemberType:  member.MemberType,
+		Address:     member.Address,
+		Moo:         member.Moo,
+		Subdistrict: member.Subdistrict,
+		District:    member.District,
+		Province:    member.Province,
+	}
+
+	seed.CooperativeID, _ = strconv.ParseInt(member.CooperativeID, 10, 64)
+	seed.IdCard, _ = strconv.ParseInt(member.IdCard, 10, 64)
+	seed.AccountYear, _ = strconv.ParseInt(member.AccountYear, 10, 64)
+	seed.MemberId, _ = strconv.ParseInt(member.MemberId, 10, 64)
+
+	if !member.JoiningDate.IsZero() {
+		seed.JoiningDate = member.JoiningDate.Format("2006-01-02")
+	}
+	if !member.LeavingDate.IsZero() {
+		seed.LeavingDate = member.LeavingDate.Format("2006-01-02")
+	}
+
+	return seed
+}
+
+// memberExportRow lays a member out in memberExportHeader order; identifiers are written as text
+// so spreadsheets do not round 13-digit numbers
+func memberExportRow(member models.Member) []interface{} {
+	seed := ToSeedMemberData(member)
+	return []interface{}{
+		member.CooperativeID, member.IdCard, strconv.FormatInt(seed.AccountYear, 10), member.MemberId,
+		seed.FullName, seed.Nationality, seed.SharesNum, seed.SharesValue,
+		seed.JoiningDate, seed.MemberType, seed.LeavingDate, seed.Address, seed.Moo,
+		seed.Subdistrict, seed.District, seed.Province,
+	}
+}
+
+// WriteMembersJSON streams the members as a JSON array of SeedMemberData
+func WriteMembersJSON(w io.Writer, filter MemberFilter) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := StreamMembers(filter, func(batch []models.Member) error {
+		for _, member := range batch {
+			data, err := json.Marshal(ToSeedMemberData(member))
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// WriteMembersCSV streams the members as CSV, flushing after every batch
+func WriteMembersCSV(w io.Writer, filter MemberFilter) error {
+	// UTF-8 BOM so Excel opens Thai text correctly
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(memberExportHeader); err != nil {
+		return err
+	}
+
+	err := StreamMembers(filter, func(batch []models.Member) error {
+		for _, member := range batch {
+			row := memberExportRow(member)
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = fmt.Sprint(value)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMembersXLSX streams the members into an Excel sheet
+func WriteMembersXLSX(w io.Writer, filter MemberFilter) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Members"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	headerRow := make([]interface{}, len(memberExportHeader))
+	for i, title := range memberExportHeader {
+		headerRow[i] = title
+	}
+	if err := stream.SetRow("A1", headerRow); err != nil {
+		return err
+	}
+
+	rowIndex := 2
+	err = StreamMembers(filter, func(batch []models.Member) error {
+		for _, member := range batch {
+			cell, err := excelize.CoordinatesToCellName(1, rowIndex)
+			if err != nil {
+				return err
+			}
+			if err := stream.SetRow(cell, memberExportRow(member)); err != nil {
+				return err
+			}
+			rowIndex++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+// exportedMember is stored with a Christian-era account year, as ValidateMemberInput leaves it
+var exportedMember = models.Member{
+	CooperativeID: ownerCooperative,
+	IdCard:        "3500100384889",
+	AccountYear:   "2024",
+	MemberId:      "100",
+	FullName:      "สมชาย ใจดี",
+	Nationality:   "ไทย",
+	SharesNum:     150,
+	SharesValue:   1500,
+	JoiningDate:   time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+	MemberType:    1,
+	LeavingDate:   time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC),
+	Address:       "12/3",
+	Moo:           4,
+	Subdistrict:   "ในเมือง",
+	District:      "เมือง",
+	Province:      "ขอนแก่น",
+}
+
+// exportedMembers answers the member query with exportedMember
+func exportedMembers(q fakeQuery) (*fakeRows, bool) {
+	if !q.tables()["members"] {
+		return nil, false
+	}
+	m := exportedMember
+	rows := &fakeRows{columns: []string{
+		"id", "cooperative_id", "id_card", "account_year", "member_id", "full_name", "nationality", "shares_num", "shares_value",
+		"joining_date", "member_type", "leaving_date", "address", "moo", "subdistrict", "district", "province",
+	}}
+	if q.visible() {
+		rows.values = [][]driver.Value{{
+			uuid.NewString(), m.CooperativeID, m.IdCard, m.AccountYear, m.MemberId, m.FullName, m.Nationality, m.SharesNum, m.SharesValue,
+			m.JoiningDate, m.MemberType, m.LeavingDate, m.Address, m.Moo, m.Subdistrict, m.District, m.Province,
+		}}
+	}
+	return rows, true
+}
+
+// sameMember compares the registry fields of two members
+func sameMember(a models.Member, b models.Member) bool {
+	return a.CooperativeID == b.CooperativeID && a.IdCard == b.IdCard && a.AccountYear == b.AccountYear &&
+		a.MemberId == b.MemberId && a.FullName == b.FullName && a.Nationality == b.Nationality &&
+		a.SharesNum == b.SharesNum && a.SharesValue == b.SharesValue && a.JoiningDate.Equal(b.JoiningDate) &&
+		a.MemberType == b.MemberType && a.LeavingDate.Equal(b.LeavingDate) && a.Address == b.Address &&
+		a.Moo == b.Moo && a.Subdistrict == b.Subdistrict && a.District == b.District && a.Province == b.Province
+}
+
+// An exported file imports back into the same member, whichever format it was written in
+func TestMemberExportImportsBack(t *testing.T) {
+	tests := []struct {
+		filename string
+		write    func(w io.Writer, filter MemberFilter) error
+	}{
+		{"members.csv", WriteMembersCSV},
+		{"members.xlsx", WriteMembersXLSX},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = exportedMembers
+
+			var file bytes.Buffer
+			if err := tt.write(&file, MemberFilter{CooperativeID: ownerCooperative}); err != nil {
+				t.Fatal(err)
+			}
+			assertScoped(t, db, ownerCooperative, "members")
+
+			records, err := readImportRecords(tt.filename, &file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 2 || strings.Join(records[0], ",") != strings.Join(memberExportHeader, ",") {
+				t.Fatalf("records = %v", records)
+			}
+
+			columns, err := memberImportHeader(records[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			input, errs := memberImportInput(columns, records[1])
+			if len(errs) > 0 {
+				t.Fatalf("memberImportInput() errors = %v", errs)
+			}
+			member, err := ValidateMemberInput(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameMember(*member, exportedMember) {
+				t.Errorf("imported %+v, exported %+v", *member, exportedMember)
+			}
+		})
+	}
+}
+
+func TestWriteMembersCSV(t *testing.T) {
+	db := useFakeDB(t)
+	db.answer = exportedMembers
+
+	var file bytes.Buffer
+	if err := WriteMembersCSV(&file, MemberFilter{CooperativeID: ownerCooperative}); err != nil {
+		t.Fatal(err)
+	}
+
+	content := file.String()
+	if !strings.HasPrefix(content, "\xEF\xBB\xBF") {
+		t.Error("CSV does not start with a UTF-8 BOM")
+	}
+	// Identifiers are written as text, not as numbers a spreadsheet would round
+	row := strings.Split(strings.TrimSpace(content), "\n")[1]
+	if !strings.HasPrefix(row, ownerCooperative+",3500100384889,2024,100,สมชาย ใจดี,") {
+		t.Errorf("row = %q", row)
+	}
+}
+
+func TestWriteMembersJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		cooperative string
+		members     int
+	}{
+		{"own cooperative", ownerCooperative, 1},
+		// An empty export is still an array
+		{"other cooperative", otherCooperative, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = exportedMembers
+
+			var file bytes.Buffer
+			if err := WriteMembersJSON(&file, MemberFilter{CooperativeID: tt.cooperative}); err != nil {
+				t.Fatal(err)
+			}
+
+			var seeds []SeedMemberData
+			if err := json.Unmarshal(file.Bytes(), &seeds); err != nil {
+				t.Fatalf("invalid JSON %q: %v", file.String(), err)
+			}
+			if len(seeds) != tt.members {
+				t.Fatalf("got %d members, want %d", len(seeds), tt.members)
+			}
+			if tt.members > 0 && (seeds[0].IdCard != 3500100384889 || seeds[0].JoiningDate != "2020-03-01" || seeds[0].LeavingDate != "2024-09-30") {
+				t.Errorf("seed = %+v", seeds[0])
+			}
+		})
+	}
+}
+
+func TestToSeedMemberDataLeavesZeroDatesOut(t *testing.T) {
+	member := exportedMember
+	member.LeavingDate = time.Time{}
+
+	if seed := ToSeedMemberData(member); seed.LeavingDate != "" || seed.JoiningDate != "2020-03-01" {
+		t.Errorf("dates = %q, %q", seed.JoiningDate, seed.LeavingDate)
+	}
+}
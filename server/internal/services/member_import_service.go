@@ -0,0 +1,437 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImportUnsupportedFormat = errors.New("รองรับเฉพาะไฟล์ .csv และ .xlsx")
+	ErrImportEmptyFile         = errors.New("ไม่พบข้อมูลสมาชิกในไฟล์")
+	ErrImportHasErrors         = errors.New("พบข้อมูลไม่ถูกต้อง ยังไม่ได้บันทึกข้อมูลสมาชิก")
+	ErrImportFailed            = errors.New("ไม่สามารถนำเข้าข้อมูลสมาชิกได้")
+)
+
+const (
+	MemberImportCreate = "create"
+	MemberImportUpdate = "update"
+	MemberImportError  = "error"
+)
+
+// MemberImportRow is the outcome of one data row of an import file
+type MemberImportRow struct {
+	Row      int      `json:"row"` // row number in the file, header = 1
+	IdCard   string   `json:"idCard"`
+	MemberId string   `json:"memberId"`
+	FullName string   `json:"fullName"`
+	Action   string   `json:"action"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type MemberImportReport struct {
+	DryRun  bool              `json:"dryRun"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []MemberImportRow `json:"rows"`
+}
+
+// memberImportColumns maps the registry headers (Thai layout, or the JSON field names) to member fields
+var memberImportColumns = map[string]string{
+	"เลขทะเบียนสหกรณ์":     "cooperativeId",
+	"เลขบัตรประชาชน":       "idCard",
+	"ปีบัญชี":              "accountYear",
+	"เลขสมาชิก":            "memberId",
+	"เลขทะเบียนสมาชิก":     "memberId",
+	"ชื่อ-นามสกุล":         "fullName",
+	"ชื่อ-สกุล":            "fullName",
+	"สัญชาติ":              "nationality",
+	"จำนวนหุ้น":            "sharesNum",
+	"มูลค่าหุ้น":           "sharesValue",
+	"วันที่เข้าเป็นสมาชิก": "joiningDate",
+	"ประเภทสมาชิก":         "memberType",
+	"วันที่ออกจากสมาชิก":   "leavingDate",
+	"ที่อยู่":              "address",
+	"บ้านเลขที่":           "address",
+	"หมู่":                 "moo",
+	"หมู่ที่":              "moo",
+	"ตำบล":                 "subdistrict",
+	"อำเภอ":                "district",
+	"จังหวัด":              "province",
+}
+
+var memberImportRequiredColumns = []string{"cooperativeId", "idCard", "memberId", "fullName", "nationality", "joiningDate"}
+
+// ImportMembers validates a CSV/XLSX member registry of one cooperative and, unless dryRun, upserts
+// every row by ID card in a single transaction. Nothing is written when any row is invalid.
+func ImportMembers(cooperativeID string, filename string, file io.Reader, dryRun bool) (*MemberImportReport, error) {
+	records, err := readImportRecords(filename, file)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, ErrImportEmptyFile
+	}
+
+	columns, err := memberImportHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MemberImportReport{DryRun: dryRun, Rows: []MemberImportRow{}}
+	members := make([]*models.Member, 0, len(records)-1)
+
+	seenIDCards := map[string]int{}
+	seenMemberIDs := map[string]int{}
+	seenNames := map[string]int{}
+
+	for i, record := range records[1:] {
+		rowNumber := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		input, parseErrors := memberImportInput(columns, record)
+		row := MemberImportRow{Row: rowNumber, IdCard: input.IdCard, MemberId: input.MemberId, FullName: input.FullName}
+		row.Errors = parseErrors
+
+		member, err := ValidateMemberInput(input)
+		if err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		} else if member.CooperativeID != cooperativeID {
+			row.Errors = append(row.Errors, ErrCooperativeMismatch.Error())
+		}
+
+		// Duplicates inside the file
+		if input.IdCard != "" {
+			if first, ok := seenIDCards[input.IdCard]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("เลขบัตรประชาชนซ้ำกับแถวที่ %d", first))
+			} else {
+				seenIDCards[input.IdCard] = rowNumber
+			}
+		}
+		if input.MemberId != "" {
+			if first, ok := seenMemberIDs[input.MemberId]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("เลขสมาชิกซ้ำกับแถวที่ %d", first))
+			} else {
+				seenMemberIDs[input.MemberId] = rowNumber
+			}
+		}
+		if name := normalizeMemberName(input.FullName); name != "" {
+			if first, ok := seenNames[name]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("ชื่อ-นามสกุลซ้ำกับแถวที่ %d", first))
+			} else {
+				seenNames[name] = rowNumber
+			}
+		}
+
+		if len(row.Errors) > 0 {
+			member = nil
+		}
+		report.Rows = append(report.Rows, row)
+		members = append(members, member)
+	}
+
+	report.Total = len(report.Rows)
+	if report.Total == 0 {
+		return nil, ErrImportEmptyFile
+	}
+
+	// Duplicates against members already registered
+	if err := resolveImportMembers(cooperativeID, report, members); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportFailed, err)
+	}
+
+	if report.Failed > 0 || dryRun {
+		if report.Failed > 0 && !dryRun {
+			return report, ErrImportHasErrors
+		}
+		return report, nil
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, member := range members {
+			if member == nil {
+				continue
+			}
+			if err := tx.Save(member).Error; err != nil {
+				return fmt.Errorf("failed to save member %s: %v", member.IdCard, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportFailed, err)
+	}
+
+	return report, nil
+}
+
+// resolveImportMembers matches each valid row with an existing member of the cooperative by ID card
+// and checks that its member ID and name do not belong to another of its members, then tallies the report
+func resolveImportMembers(cooperativeID string, report *MemberImportReport, members []*models.Member) error {
+	var idCards, memberIDs, names []string
+	for _, member := range members {
+		if member == nil {
+			continue
+		}
+		idCards = append(idCards, member.IdCard)
+		memberIDs = append(memberIDs, member.MemberId)
+		names = append(names, normalizeMemberName(member.FullName))
+	}
+
+	existingByIDCard := map[string]models.Member{}
+	existingByMemberID := map[string]models.Member{}
+	existingByName := map[string]models.Member{}
+
+	if len(idCards) > 0 {
+		var existing []models.Member
+		if err := database.DB.Scopes(cooperativeScope(cooperativeID)).
+			Where("id_card IN ? OR member_id IN ? OR REPLACE(full_name, ' ', '') IN ?", idCards, memberIDs, names).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, member := range existing {
+			existingByIDCard[member.IdCard] = member
+			existingByMemberID[member.MemberId] = member
+			existingByName[normalizeMemberName(member.FullName)] = member
+		}
+	}
+
+	now := time.Now()
+	for i, member := range members {
+		row := &report.Rows[i]
+		if member != nil {
+			current, found := existingByIDCard[member.IdCard]
+			if other, ok := existingByMemberID[member.MemberId]; ok && (!found || other.Id != current.Id) {
+				row.Errors = append(row.Errors, "เลขสมาชิกนี้มีอยู่แล้ว")
+			}
+			if other, ok := existingByName[normalizeMemberName(member.FullName)]; ok && (!found || other.Id != current.Id) {
+				row.Errors = append(row.Errors, "ชื่อ-นามสกลุลนี้มีอยู่แล้ว")
+			}
+
+			if len(row.Errors) == 0 {
+				if found {
+					member.Id = current.Id
+					member.CreatedAt = current.CreatedAt
+					row.Action = MemberImportUpdate
+					report.Updated++
+				} else {
+					member.CreatedAt = now
+					row.Action = MemberImportCreate
+					report.Created++
+				}
+				member.UpdatedAt = now
+				continue
+			}
+			members[i] = nil
+		}
+
+		row.Action = MemberImportError
+		report.Failed++
+	}
+
+	return nil
+}
+
+func readImportRecords(filename string, file io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		// Remove UTF-8 BOM if it exists (Excel adds one when saving CSV)
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("อ่านไฟล์ CSV ไม่สำเร็จ: %v", err)
+		}
+		return records, nil
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("อ่านไฟล์ Excel ไม่สำเร็จ: %v", err)
+		}
+		defer workbook.Close()
+
+		// Raw values keep ID cards from turning into 3.56E+12 and dates into locale strings
+		sheet := workbook.GetSheetName(workbook.GetActiveSheetIndex())
+		records, err := workbook.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("อ่านไฟล์ Excel ไม่สำเร็จ: %v", err)
+		}
+		return records, nil
+	default:
+		return nil, ErrImportUnsupportedFormat
+	}
+}
+
+// memberImportHeader maps each column index to a member field, failing when a required column is missing
+func memberImportHeader(header []string) (map[string]int, error) {
+	fields := map[string]string{}
+	for thai, field := range memberImportColumns {
+		fields[strings.ReplaceAll(thai, " ", "")] = field
+		fields[strings.ToLower(field)] = field
+	}
+
+	columns := map[string]int{}
+	for i, title := range header {
+		key := strings.ReplaceAll(strings.TrimSpace(title), " ", "")
+		field, ok := fields[key]
+		if !ok {
+			field, ok = fields[strings.ToLower(key)]
+		}
+		if ok {
+			if _, exists := columns[field]; !exists {
+				columns[field] = i
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range memberImportRequiredColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("ไม่พบคอลัมน์ที่จำเป็นในไฟล์: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// memberImportInput reads one record into a MemberInput, numeric and date cells are normalized so
+// ValidateMemberInput applies exactly the same rules as the member form
+func memberImportInput(columns map[string]int, record []string) (MemberInput, []string) {
+	var errs []string
+
+	cell := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(field string, label string) float64 {
+		value := strings.ReplaceAll(cell(field), ",", "")
+		if value == "" {
+			return 0
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%sไม่ถูกต้อง", label))
+			return 0
+		}
+		return n
+	}
+	date := func(field string) string {
+		value, err := normalizeImportDate(cell(field))
+		// ValidateMemberInput rejects a bad joining date but silently drops a bad leaving date
+		if err != nil && field == "leavingDate" {
+			errs = append(errs, "รูปแบบวันที่ออกจากสมาชิกไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)")
+		}
+		return value
+	}
+
+	input := MemberInput{
+		CooperativeID: importDigits(cell("cooperativeId")),
+		IdCard:        importDigits(cell("idCard")),
+		AccountYear:   importAccountYear(cell("accountYear")),
+		MemberId:      importDigits(cell("memberId")),
+		FullName:      cell("fullName"),
+		Nationality:   cell("nationality"),
+		SharesNum:     number("sharesNum", "จำนวนหุ้น"),
+		SharesValue:   number("sharesValue", "มูลค่าหุ้น"),
+		JoiningDate:   date("joiningDate"),
+		MemberType:    int64(number("memberType", "ประเภทสมาชิก")),
+		LeavingDate:   date("leavingDate"),
+		Address:       cell("address"),
+		Moo:           int64(number("moo", "หมู่")),
+		Subdistrict:   strings.Trim(cell("subdistrict"), "."),
+		District:      cell("district"),
+		Province:      cell("province"),
+	}
+
+	return input, errs
+}
+
+// importDigits undoes spreadsheet number formatting on identifiers (e.g. 3.560100384889E+12, 6.0, 3-5601-00384-88-9)
+func importDigits(value string) string {
+	value = strings.NewReplacer("-", "", " ", "").Replace(value)
+	if strings.ContainsAny(value, "eE.") {
+		if n, err := strconv.ParseFloat(value, 64); err == nil && n == math.Trunc(n) {
+			return strconv.FormatFloat(n, 'f', 0, 64)
+		}
+	}
+	return value
+}
+
+// importAccountYear returns a Buddhist-era year; exports and seed files carry Christian-era years
+func importAccountYear(value string) string {
+	value = importDigits(value)
+	if year, err := strconv.Atoi(value); err == nil && year > 0 && year < 2400 {
+		return strconv.Itoa(year + 543)
+	}
+	return value
+}
+
+// normalizeImportDate accepts YYYY-MM-DD, DD/MM/YYYY (Buddhist-era years are converted) and Excel
+// date serials, and returns YYYY-MM-DD
+func normalizeImportDate(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+
+	if t, err := time.Parse("2/1/2006", value); err == nil {
+		if t.Year() > 2400 {
+			t = t.AddDate(-543, 0, 0)
+		}
+		return t.Format("2006-01-02"), nil
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+
+	return value, errors.New("invalid date")
+}
+
+func normalizeMemberName(fullName string) string {
+	return strings.ReplaceAll(fullName, " ", "")
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
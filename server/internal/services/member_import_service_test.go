@@ -0,0 +1,304 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memberImportFile is a CSV registry in the Thai layout
+func memberImportFile(rows ...string) string {
+	return "เลขทะเบียนสหกรณ์,เลขบัตรประชาชน,เลขสมาชิก,ชื่อ-นามสกุล,สัญชาติ,วันที่เข้าเป็นสมาชิก\n" + strings.Join(rows, "\n")
+}
+
+// memberImportRecord is one member of ownerCooperative who joined on 2024-01-15
+func memberImportRecord(idCard string, memberID string, fullName string) string {
+	return ownerCooperative + "," + idCard + "," + memberID + "," + fullName + ",ไทย,2024-01-15"
+}
+
+// registeredMember answers the duplicate lookup with one member already registered in the cooperative
+func registeredMember(q fakeQuery) (*fakeRows, bool) {
+	if !q.tables()["members"] || !strings.HasPrefix(q.sql, "SELECT") {
+		return nil, false
+	}
+	rows := &fakeRows{columns: []string{"id", "cooperative_id", "id_card", "member_id", "full_name", "created_at"}}
+	if q.visible() {
+		rows.values = [][]driver.Value{{uuid.NewString(), ownerCooperative, "1101700203450", "100", "สมชาย ใจดี", time.Now()}}
+	}
+	return rows, true
+}
+
+func TestMemberImportHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		want    map[string]int
+		missing string
+	}{
+		{
+			"thai layout",
+			[]string{"เลขทะเบียนสหกรณ์", "เลขบัตรประชาชน", "ปีบัญชี", "เลขสมาชิก", "ชื่อ-นามสกุล", "สัญชาติ", "วันที่เข้าเป็นสมาชิก", "ตำบล"},
+			map[string]int{"cooperativeId": 0, "idCard": 1, "accountYear": 2, "memberId": 3, "fullName": 4, "nationality": 5, "joiningDate": 6, "subdistrict": 7},
+			"",
+		},
+		{
+			// Spaces inside and around Thai headers are ignored
+			"thai with spaces",
+			[]string{" เลขทะเบียน สหกรณ์", "เลขบัตร ประชาชน", "เลขทะเบียนสมาชิก", "ชื่อ - สกุล", "สัญชาติ ", "วันที่เข้า เป็นสมาชิก"},
+			map[string]int{"cooperativeId": 0, "idCard": 1, "memberId": 2, "fullName": 3, "nationality": 4, "joiningDate": 5},
+			"",
+		},
+		{
+			// The export layout, whatever the case
+			"field names",
+			[]string{"CooperativeId", "idcard", "memberId", "FULLNAME", "nationality", "joiningDate", "unknown"},
+			map[string]int{"cooperativeId": 0, "idCard": 1, "memberId": 2, "fullName": 3, "nationality": 4, "joiningDate": 5},
+			"",
+		},
+		{
+			// The first of two aliases wins
+			"aliases",
+			[]string{"เลขทะเบียนสหกรณ์", "เลขบัตรประชาชน", "เลขสมาชิก", "ชื่อ-นามสกุล", "สัญชาติ", "วันที่เข้าเป็นสมาชิก", "เลขทะเบียนสมาชิก"},
+			map[string]int{"memberId": 2},
+			"",
+		},
+		{
+			"missing columns",
+			[]string{"เลขทะเบียนสหกรณ์", "เลขบัตรประชาชน", "เลขสมาชิก", "ชื่อ-นามสกุล"},
+			nil,
+			"nationality, joiningDate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := memberImportHeader(tt.header)
+			if tt.missing != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.missing) {
+					t.Fatalf("memberImportHeader() error = %v, want missing %s", err, tt.missing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for field, index := range tt.want {
+				if got, ok := columns[field]; !ok || got != index {
+					t.Errorf("column of %s = %v (%v), want %v", field, got, ok, index)
+				}
+			}
+		})
+	}
+}
+
+func TestMemberImportInput(t *testing.T) {
+	columns := map[string]int{"idCard": 0, "accountYear": 1, "sharesNum": 2, "joiningDate": 3, "leavingDate": 4, "memberId": 5}
+
+	tests := []struct {
+		name    string
+		record  []string
+		check   func(input MemberInput) bool
+		errText string
+	}{
+		{"spreadsheet id card", []string{"3.500100384889E+12"}, func(input MemberInput) bool { return input.IdCard == "3500100384889" }, ""},
+		{"dashed id card", []string{"3-5001-00384-88-9"}, func(input MemberInput) bool { return input.IdCard == "3500100384889" }, ""},
+		{"member id as float", []string{"", "", "", "", "", "6.0"}, func(input MemberInput) bool { return input.MemberId == "6" }, ""},
+		// The registry is read as Buddhist-era years; exports carry Christian-era ones
+		{"christian account year", []string{"", "2024"}, func(input MemberInput) bool { return input.AccountYear == "2567" }, ""},
+		{"buddhist account year", []string{"", "2567"}, func(input MemberInput) bool { return input.AccountYear == "2567" }, ""},
+		{"shares with separators", []string{"", "", "1,500"}, func(input MemberInput) bool { return input.SharesNum == 1500 }, ""},
+		{"bad shares", []string{"", "", "หนึ่งพัน"}, nil, "จำนวนหุ้นไม่ถูกต้อง"},
+		{"buddhist date", []string{"", "", "", "1/2/2567"}, func(input MemberInput) bool { return input.JoiningDate == "2024-02-01" }, ""},
+		{"christian date", []string{"", "", "", "15/1/2024"}, func(input MemberInput) bool { return input.JoiningDate == "2024-01-15" }, ""},
+		{"excel serial date", []string{"", "", "", "45292"}, func(input MemberInput) bool { return input.JoiningDate == "2024-01-01" }, ""},
+		{"bad leaving date", []string{"", "", "", "", "31/13/2567"}, nil, "วันที่ออกจากสมาชิกไม่ถูกต้อง"},
+		{"short record", []string{"3500100384889"}, func(input MemberInput) bool { return input.JoiningDate == "" && input.MemberId == "" }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, errs := memberImportInput(columns, tt.record)
+			if tt.errText != "" {
+				if !strings.Contains(strings.Join(errs, ","), tt.errText) {
+					t.Errorf("errors = %v, want %q", errs, tt.errText)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Errorf("unexpected errors %v", errs)
+			}
+			if !tt.check(input) {
+				t.Errorf("memberImportInput(%v) = %+v", tt.record, input)
+			}
+		})
+	}
+}
+
+func TestImportMembersReport(t *testing.T) {
+	type row struct {
+		action string
+		errors string
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		rows    []row
+		created int
+		updated int
+		failed  int
+	}{
+		{
+			"new and registered",
+			memberImportFile(
+				memberImportRecord("1101700203450", "100", "สมชาย ใจดี"),
+				memberImportRecord("3100600123450", "101", "สมหญิง รักดี"),
+			),
+			[]row{{MemberImportUpdate, ""}, {MemberImportCreate, ""}},
+			1, 1, 0,
+		},
+		{
+			// Names match whatever their spacing
+			"duplicates in the file",
+			memberImportFile(
+				memberImportRecord("3100600123450", "101", "สมหญิง รักดี"),
+				memberImportRecord("3100600123450", "102", "สมศรี มีสุข"),
+				memberImportRecord("3500100384889", "101", "สมปอง ดีใจ"),
+				memberImportRecord("1100400567897", "103", "สมหญิง  รักดี"),
+			),
+			[]row{
+				{MemberImportCreate, ""},
+				{MemberImportError, "เลขบัตรประชาชนซ้ำกับแถวที่ 2"},
+				{MemberImportError, "เลขสมาชิกซ้ำกับแถวที่ 2"},
+				{MemberImportError, "ชื่อ-นามสกุลซ้ำกับแถวที่ 2"},
+			},
+			1, 0, 3,
+		},
+		{
+			"duplicates of registered members",
+			memberImportFile(
+				memberImportRecord("3100600123450", "100", "สมหญิง รักดี"),
+				memberImportRecord("3500100384889", "104", "สมชาย  ใจดี"),
+			),
+			[]row{{MemberImportError, "เลขสมาชิกนี้มีอยู่แล้ว"}, {MemberImportError, "ชื่อ-นามสกลุลนี้มีอยู่แล้ว"}},
+			0, 0, 2,
+		},
+		{
+			"invalid rows",
+			memberImportFile(
+				otherCooperative+",3100600123450,101,สมหญิง รักดี,ไทย,2024-01-15",
+				memberImportRecord("3100600123451", "102", "สมศรี มีสุข"),
+				ownerCooperative+",3500100384889,103,สมปอง ดีใจ,ไทย,ไม่ระบุ",
+				"",
+				memberImportRecord("1100400567897", "104", "สมใจ ใจดี"),
+			),
+			// The empty line is skipped
+			[]row{{MemberImportError, ErrCooperativeMismatch.Error()}, {MemberImportError, ""}, {MemberImportError, "รูปแบบวันที่เข้าร่วมไม่ถูกต้อง"}, {MemberImportCreate, ""}},
+			1, 0, 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = registeredMember
+
+			report, err := ImportMembers(ownerCooperative, "members.csv", strings.NewReader(tt.file), true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !report.DryRun || report.Total != len(tt.rows) || report.Created != tt.created || report.Updated != tt.updated || report.Failed != tt.failed {
+				t.Errorf("report = dry run %v, %d rows, %d created, %d updated, %d failed; want %d, %d, %d, %d",
+					report.DryRun, report.Total, report.Created, report.Updated, report.Failed, len(tt.rows), tt.created, tt.updated, tt.failed)
+			}
+			for i, want := range tt.rows {
+				if i >= len(report.Rows) {
+					break
+				}
+				got := report.Rows[i]
+				if got.Action != want.action || (want.action == MemberImportError && len(got.Errors) == 0) ||
+					!strings.Contains(strings.Join(got.Errors, ","), want.errors) {
+					t.Errorf("row %d = %s %v, want %s %q", got.Row, got.Action, got.Errors, want.action, want.errors)
+				}
+			}
+			if writes := db.writes(); len(writes) > 0 {
+				t.Errorf("dry run wrote:\n%s", strings.Join(writes, "\n"))
+			}
+			assertScoped(t, db, ownerCooperative, "members")
+		})
+	}
+}
+
+func TestImportMembersWrites(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		err    error
+		writes bool
+	}{
+		{
+			"valid file",
+			memberImportFile(
+				memberImportRecord("1101700203450", "100", "สมชาย ใจดี"),
+				memberImportRecord("3100600123450", "101", "สมหญิง รักดี"),
+			),
+			nil, true,
+		},
+		{
+			// One bad row keeps the good ones out too
+			"file with errors",
+			memberImportFile(
+				memberImportRecord("3100600123450", "101", "สมหญิง รักดี"),
+				memberImportRecord("3100600123450", "102", "สมศรี มีสุข"),
+			),
+			ErrImportHasErrors, false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.answer = registeredMember
+
+			if _, err := ImportMembers(ownerCooperative, "members.csv", strings.NewReader(tt.file), false); !errors.Is(err, tt.err) {
+				t.Fatalf("ImportMembers() error = %v, want %v", err, tt.err)
+			}
+
+			statements := db.inTransaction()
+			saved := strings.Contains(statements, `INSERT INTO "members"`) && strings.Contains(statements, `UPDATE "members"`)
+			if tt.writes && !saved {
+				t.Errorf("expected an insert and an update in one transaction:\n%s", statements)
+			}
+			if !tt.writes && len(db.writes()) > 0 {
+				t.Errorf("wrote despite errors:\n%s", strings.Join(db.writes(), "\n"))
+			}
+		})
+	}
+}
+
+func TestImportMembersRejectsFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		err      error
+	}{
+		{"unsupported format", "members.txt", memberImportFile(memberImportRecord("3100600123450", "101", "สมหญิง รักดี")), ErrImportUnsupportedFormat},
+		{"header only", "members.csv", memberImportFile(), ErrImportEmptyFile},
+		{"blank rows only", "members.csv", memberImportFile(",,,,,", ""), ErrImportEmptyFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeDB(t)
+			if _, err := ImportMembers(ownerCooperative, tt.filename, strings.NewReader(tt.content), true); !errors.Is(err, tt.err) {
+				t.Errorf("ImportMembers() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
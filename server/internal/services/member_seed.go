@@ -10,6 +10,7 @@ import (
 
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/validation"
 	"github.com/google/uuid"
 )
 
@@ -33,8 +34,8 @@ type SeedMemberData struct {
 	Province      string  `json:"province"`
 }
 
-// SeedMembersFromJSON loads member data from JSON file and seeds the database
-func SeedMembersFromJSON() error {
+// SeedMembersFromJSON loads member data from JSON file and seeds the members of one cooperative
+func SeedMembersFromJSON(cooperativeID string) error {
 	filePath := "seed/members_seed.json"
 
 	// Read JSON file
@@ -94,9 +95,21 @@ func SeedMembersFromJSON() error {
 			UpdatedAt:     time.Now(),
 		}
 
+		// Skip members of other cooperatives
+		if cooperativeIDStr != cooperativeID {
+			fmt.Printf("Skipped member %s: cooperative %s\n", seed.FullName, cooperativeIDStr)
+			continue
+		}
+
+		// Skip ID cards that fail the check digit
+		if _, err := validation.ValidateThaiID(idCardStr); err != nil {
+			fmt.Printf("Skipped member %s: ID Card=%s, %v\n", seed.FullName, idCardStr, err)
+			continue
+		}
+
 		// Check if member already exists (by ID card or member ID)
 		var existingMember models.Member
-		if err := database.DB.Where("id_card = ? OR member_id = ?", idCardStr, memberIdStr).First(&existingMember).Error; err == nil {
+		if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("id_card = ? OR member_id = ?", idCardStr, memberIdStr).First(&existingMember).Error; err == nil {
 			fmt.Printf("Member already exists: ID Card=%s, Member ID=%s\n", idCardStr, memberIdStr)
 			continue // Skip existing member
 		}
@@ -155,9 +168,13 @@ func SeedSingleMember(seed SeedMemberData) error {
 		UpdatedAt:     time.Now(),
 	}
 
+	if _, err := validation.ValidateThaiID(idCardStr); err != nil {
+		return fmt.Errorf("invalid ID Card=%s: %v", idCardStr, err)
+	}
+
 	// Check if member already exists
 	var existingMember models.Member
-	if err := database.DB.Where("id_card = ? OR member_id = ?", idCardStr, memberIdStr).First(&existingMember).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeIDStr)).Where("id_card = ? OR member_id = ?", idCardStr, memberIdStr).First(&existingMember).Error; err == nil {
 		return fmt.Errorf("member already exists: ID Card=%s, Member ID=%s", idCardStr, memberIdStr)
 	}
 
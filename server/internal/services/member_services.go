@@ -3,32 +3,35 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/validation"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Member CRUD Services
 
 func CreateMember(cooperativeID string, idCard string, accountYear string, memberId string, fullName string, nationality string, sharesNum float64, sharesValue float64, joiningDate time.Time, memberType int64, leavingDate time.Time, address string, moo int64, subdistrict string, district string, province string) (*models.Member, error) {
-	// Check if ID Card already exists
+	// ID card, member ID and name are unique within the cooperative
 	var existingMemberByIDCard models.Member
-	if err := database.DB.Where("id_card = ?", idCard).First(&existingMemberByIDCard).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("id_card = ?", idCard).First(&existingMemberByIDCard).Error; err == nil {
 		return nil, errors.New("เลขบัตรประชาชนนี้มีอยู่แล้ว")
 	}
 
 	var existingFullname models.Member
 	cleanFullName := strings.ReplaceAll(fullName, " ", "")
-	if err := database.DB.Where("REPLACE(full_name, ' ', '') = ?", cleanFullName).First(&existingFullname).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("REPLACE(full_name, ' ', '') = ?", cleanFullName).First(&existingFullname).Error; err == nil {
 		return nil, errors.New("ชื่อ-นามสกลุลนี้มีอยู่แล้ว")
 	}
 
 	// Check if Member ID already exists
 	var existingMemberByMemberID models.Member
-	if err := database.DB.Where("member_id = ?", memberId).First(&existingMemberByMemberID).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("member_id = ?", memberId).First(&existingMemberByMemberID).Error; err == nil {
 		return nil, errors.New("เลขสมาชิกนี้มีอยู่แล้ว")
 	}
 
@@ -61,42 +64,34 @@ func CreateMember(cooperativeID string, idCard string, accountYear string, membe
 	return &member, nil
 }
 
-func GetMembersWithPagination(page int, limit int) ([]models.Member, int64, error) {
+func GetMembersWithPagination(cooperativeID string, page int, limit int) ([]models.Member, int64, error) {
 	var members []models.Member
 	var total int64
 
 	// Get total count
-	if err := database.DB.Model(&models.Member{}).Count(&total).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated data - remove LENGTH() for better performance
 	offset := (page - 1) * limit
-	if err := database.DB.Model(&models.Member{}).Order("member_id ASC").Offset(offset).Limit(limit).Find(&members).Error; err != nil {
+	if err := database.DB.Model(&models.Member{}).Scopes(cooperativeScope(cooperativeID)).Order("member_id ASC").Offset(offset).Limit(limit).Find(&members).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return members, total, nil
 }
 
-func GetMembersWithFiltersAndPagination(fullName string, subdistrict string, district string, province string, page int, limit int) ([]models.Member, int64, error) {
+func GetMembersWithFiltersAndPagination(cooperativeID string, fullName string, subdistrict string, district string, province string, page int, limit int) ([]models.Member, int64, error) {
 	var members []models.Member
 	var total int64
-	query := database.DB.Model(&models.Member{})
-
-	// Apply filters if provided
-	if fullName != "" {
-		query = query.Where("full_name ILIKE ?", "%"+fullName+"%")
-	}
-	if subdistrict != "" {
-		query = query.Where("subdistrict ILIKE ?", "%"+subdistrict+"%")
-	}
-	if district != "" {
-		query = query.Where("district ILIKE ?", "%"+district+"%")
-	}
-	if province != "" {
-		query = query.Where("province ILIKE ?", "%"+province+"%")
-	}
+	query := memberFilterQuery(database.DB.Model(&models.Member{}), MemberFilter{
+		CooperativeID: cooperativeID,
+		FullName:      fullName,
+		Subdistrict:   subdistrict,
+		District:      district,
+		Province:      province,
+	})
 
 	// Get total count with filters
 	if err := query.Count(&total).Error; err != nil {
@@ -112,25 +107,70 @@ func GetMembersWithFiltersAndPagination(fullName string, subdistrict string, dis
 	return members, total, nil
 }
 
-func GetMemberByID(id uuid.UUID) (*models.Member, error) {
+// MemberFilter narrows a member listing, empty/zero fields are not filtered except CooperativeID,
+// which is always applied
+type MemberFilter struct {
+	CooperativeID string
+	FullName      string
+	Subdistrict   string
+	District      string
+	Province      string
+	AccountYear   string // Christian-era year as stored
+	MemberType    int64
+	JoinedFrom    time.Time
+	JoinedTo      time.Time // exclusive
+}
+
+func memberFilterQuery(query *gorm.DB, filter MemberFilter) *gorm.DB {
+	query = query.Scopes(cooperativeScope(filter.CooperativeID))
+
+	// Apply filters if provided
+	if filter.FullName != "" {
+		query = query.Where("full_name ILIKE ?", "%"+filter.FullName+"%")
+	}
+	if filter.Subdistrict != "" {
+		query = query.Where("subdistrict ILIKE ?", "%"+filter.Subdistrict+"%")
+	}
+	if filter.District != "" {
+		query = query.Where("district ILIKE ?", "%"+filter.District+"%")
+	}
+	if filter.Province != "" {
+		query = query.Where("province ILIKE ?", "%"+filter.Province+"%")
+	}
+	if filter.AccountYear != "" {
+		query = query.Where("account_year = ?", filter.AccountYear)
+	}
+	if filter.MemberType != 0 {
+		query = query.Where("member_type = ?", filter.MemberType)
+	}
+	if !filter.JoinedFrom.IsZero() {
+		query = query.Where("joining_date >= ?", filter.JoinedFrom)
+	}
+	if !filter.JoinedTo.IsZero() {
+		query = query.Where("joining_date < ?", filter.JoinedTo)
+	}
+	return query
+}
+
+func GetMemberByID(cooperativeID string, id uuid.UUID) (*models.Member, error) {
 	var member models.Member
-	if err := database.DB.First(&member, "id = ?", id).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&member, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &member, nil
 }
 
-func GetMemberByIDCard(idCard string) (*models.Member, error) {
+func GetMemberByIDCard(cooperativeID string, idCard string) (*models.Member, error) {
 	var member models.Member
-	if err := database.DB.Where("id_card = ?", idCard).First(&member).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("id_card = ?", idCard).First(&member).Error; err != nil {
 		return nil, err
 	}
 	return &member, nil
 }
 
-func GetMemberByMemberID(memberId string) (*models.Member, error) {
+func GetMemberByMemberID(cooperativeID string, memberId string) (*models.Member, error) {
 	var member models.Member
-	if err := database.DB.Where("member_id = ?", memberId).First(&member).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("member_id = ?", memberId).First(&member).Error; err != nil {
 		return nil, err
 	}
 	return &member, nil
@@ -144,28 +184,29 @@ func GetMembersByCooperativeID(cooperativeID string) ([]models.Member, error) {
 	return members, nil
 }
 
+// UpdateMember edits a member of the cooperative; a member cannot be moved to another cooperative
 func UpdateMember(id uuid.UUID, cooperativeID string, idCard string, accountYear string, memberId string, fullName string, nationality string, sharesNum float64, sharesValue float64, joiningDate time.Time, memberType int64, leavingDate time.Time, address string, moo int64, subdistrict string, district string, province string) (*models.Member, error) {
 	var member models.Member
-	if err := database.DB.First(&member, "id = ?", id).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&member, "id = ?", id).Error; err != nil {
 		return nil, errors.New("ไม่พบข้อมูลสมาชิก")
 	}
 
 	// Check if ID Card already exists (excluding current member)
 	var existingMemberByIDCard models.Member
-	if err := database.DB.Where("id_card = ? AND id != ?", idCard, id).First(&existingMemberByIDCard).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("id_card = ? AND id != ?", idCard, id).First(&existingMemberByIDCard).Error; err == nil {
 		return nil, errors.New("เลขบัตรประชาชนนี้มีอยู่แล้ว")
 	}
 
 	// Check if Member ID already exists (excluding current member)
 	var existingMemberByMemberID models.Member
-	if err := database.DB.Where("member_id = ? AND id != ?", memberId, id).First(&existingMemberByMemberID).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("member_id = ? AND id != ?", memberId, id).First(&existingMemberByMemberID).Error; err == nil {
 		return nil, errors.New("เลขสมาชิกนี้มีอยู่แล้ว")
 	}
 
 	// Check if full name already exists (excluding current member)
 	var existingFullname models.Member
 	cleanFullName := strings.ReplaceAll(fullName, " ", "")
-	if err := database.DB.Where("REPLACE(full_name, ' ', '') = ? AND id != ?", cleanFullName, id).First(&existingFullname).Error; err == nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("REPLACE(full_name, ' ', '') = ? AND id != ?", cleanFullName, id).First(&existingFullname).Error; err == nil {
 		return nil, errors.New("ชื่อ-นามสกลุลนี้มีอยู่แล้ว")
 	}
 
@@ -195,10 +236,10 @@ func UpdateMember(id uuid.UUID, cooperativeID string, idCard string, accountYear
 	return &member, nil
 }
 
-func DeleteMember(id uuid.UUID) error {
+func DeleteMember(cooperativeID string, id uuid.UUID) error {
 	// Check if member exists
 	var member models.Member
-	if err := database.DB.First(&member, "id = ?", id).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&member, "id = ?", id).Error; err != nil {
 		return errors.New("ไม่พบข้อมูลสมาชิก")
 	}
 
@@ -210,46 +251,166 @@ func DeleteMember(id uuid.UUID) error {
 	return nil
 }
 
-func MemberExists(id uuid.UUID) bool {
+func MemberExists(cooperativeID string, id uuid.UUID) bool {
 	var member models.Member
-	return database.DB.First(&member, "id = ?", id).Error == nil
+	return database.DB.Scopes(cooperativeScope(cooperativeID)).First(&member, "id = ?", id).Error == nil
 }
 
-func MemberExistsByIDCard(idCard string) bool {
+func MemberExistsByIDCard(cooperativeID string, idCard string) bool {
 	var member models.Member
-	return database.DB.Where("id_card = ?", idCard).First(&member).Error == nil
+	return database.DB.Scopes(cooperativeScope(cooperativeID)).Where("id_card = ?", idCard).First(&member).Error == nil
 }
 
-func MemberExistsByMemberID(memberId string) bool {
+func MemberExistsByMemberID(cooperativeID string, memberId string) bool {
 	var member models.Member
-	return database.DB.Where("member_id = ?", memberId).First(&member).Error == nil
+	return database.DB.Scopes(cooperativeScope(cooperativeID)).Where("member_id = ?", memberId).First(&member).Error == nil
 }
 
 // Search members by full name
-func SearchMembersByName(fullName string) ([]models.Member, error) {
+func SearchMembersByName(cooperativeID string, fullName string) ([]models.Member, error) {
 	var members []models.Member
 	searchPattern := fmt.Sprintf("%%%s%%", fullName)
 
-	if err := database.DB.Where("full_name ILIKE ?", searchPattern).Find(&members).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("full_name ILIKE ?", searchPattern).Find(&members).Error; err != nil {
 		return nil, err
 	}
 	return members, nil
 }
 
 // Get members by province
-func GetMembersByProvince(province string) ([]models.Member, error) {
+func GetMembersByProvince(cooperativeID string, province string) ([]models.Member, error) {
 	var members []models.Member
-	if err := database.DB.Where("province = ?", province).Find(&members).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("province = ?", province).Find(&members).Error; err != nil {
 		return nil, err
 	}
 	return members, nil
 }
 
 // Get members by member type
-func GetMembersByType(memberType int64) ([]models.Member, error) {
+func GetMembersByType(cooperativeID string, memberType int64) ([]models.Member, error) {
 	var members []models.Member
-	if err := database.DB.Where("member_type = ?", memberType).Find(&members).Error; err != nil {
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("member_type = ?", memberType).Find(&members).Error; err != nil {
 		return nil, err
 	}
 	return members, nil
 }
+
+// MemberInput is a member as entered by a user or a registry file, before validation
+type MemberInput struct {
+	CooperativeID string
+	IdCard        string
+	AccountYear   string // Buddhist-era year (YYYY)
+	MemberId      string
+	FullName      string
+	Nationality   string
+	SharesNum     float64
+	SharesValue   float64
+	JoiningDate   string // Format: YYYY-MM-DD
+	MemberType    int64
+	LeavingDate   string // Format: YYYY-MM-DD, optional
+	Address       string
+	Moo           int64
+	Subdistrict   string
+	District      string
+	Province      string
+}
+
+// ValidateMemberInput checks a member against the registry rules and converts it into a model,
+// the account year is stored as a Christian-era year
+func ValidateMemberInput(input MemberInput) (*models.Member, error) {
+	input.IdCard = validation.NormalizeThaiID(input.IdCard)
+	if input.IdCard == "" || input.MemberId == "" || input.FullName == "" || input.Nationality == "" {
+		return nil, errors.New("กรุณากรอกข้อมูลที่จำเป็นให้ครบถ้วน (เลขบัตรประชาชน, เลขสมาชิก, ชื่อ-นามสกุล, สัญชาติ)")
+	}
+
+	if len(input.CooperativeID) != 13 {
+		return nil, errors.New("เลขทะเบียนสหกรณ์ต้องมี 13 หลัก")
+	}
+
+	if _, err := validation.ValidateThaiID(input.IdCard); err != nil {
+		return nil, err
+	}
+
+	accountYear := input.AccountYear
+	if accountYear != "" {
+		num, err := strconv.Atoi(accountYear)
+		if err != nil {
+			return nil, errors.New("รูปแบบปีบัญชีไม่ถูกต้อง (ต้องเป็น YYYY)")
+		}
+		accountYear = strconv.Itoa(num - 543)
+	}
+
+	joiningDate, err := time.Parse("2006-01-02", input.JoiningDate)
+	if err != nil {
+		return nil, errors.New("รูปแบบวันที่เข้าร่วมไม่ถูกต้อง (ต้องเป็น YYYY-MM-DD)")
+	}
+
+	leavingDate, err := time.Parse("2006-01-02", input.LeavingDate)
+	if err != nil {
+		leavingDate = time.Time{} // Use zero time if empty or invalid
+	}
+
+	return &models.Member{
+		CooperativeID: input.CooperativeID,
+		IdCard:        input.IdCard,
+		AccountYear:   accountYear,
+		MemberId:      input.MemberId,
+		FullName:      input.FullName,
+		Nationality:   input.Nationality,
+		SharesNum:     input.SharesNum,
+		SharesValue:   input.SharesValue,
+		JoiningDate:   joiningDate,
+		MemberType:    input.MemberType,
+		LeavingDate:   leavingDate,
+		Address:       input.Address,
+		Moo:           input.Moo,
+		Subdistrict:   input.Subdistrict,
+		District:      input.District,
+		Province:      input.Province,
+	}, nil
+}
+
+// GetMemberEvaluates lists every evaluation where the member is the borrower or a co-borrower, newest first,
+// leaving out those the reader may not see. Applicants saved before they were linked to a member are matched by ID card.
+func GetMemberEvaluates(cooperativeID string, id uuid.UUID, readerID uuid.UUID) (*models.MemberEvaluateHistory, error) {
+	readable, err := readableEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var member models.Member
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&member, "id = ?", id).Error; err != nil {
+		return nil, errors.New("ไม่พบข้อมูลสมาชิก")
+	}
+
+	var evaluates []models.Evaluate
+	if err := database.DB.
+		Preload("Applicants", applicantOrder).
+		Preload("Result").
+		Preload("User").
+		Scopes(readable).
+		Where("id IN (?)", database.DB.Model(&models.Applicant{}).
+			Select("evaluate_id").
+			Where("member_id = ? OR id_card = ?", member.Id, member.IdCard)).
+		Order("created_at DESC").
+		Find(&evaluates).Error; err != nil {
+		return nil, err
+	}
+
+	history := &models.MemberEvaluateHistory{
+		Member:    member,
+		Evaluates: make([]models.MemberEvaluate, 0, len(evaluates)),
+	}
+	for _, evaluate := range evaluates {
+		role := ""
+		for i, applicant := range evaluate.Applicants {
+			if (applicant.MemberID != nil && *applicant.MemberID == member.Id) || applicant.IDCard == member.IdCard {
+				role = applicantLabel(i)
+				break
+			}
+		}
+		history.Evaluates = append(history.Evaluates, models.MemberEvaluate{Evaluate: evaluate, Role: role})
+	}
+
+	return history, nil
+}
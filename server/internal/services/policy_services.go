@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EvaluatePolicy Services, each cooperative sets its own thresholds
+
+func CreatePolicy(cooperativeID string, request *models.EvaluatePolicyRequest) (*models.EvaluatePolicy, error) {
+	// Check if a policy already exists for this loan type and margin type
+	var existingPolicy models.EvaluatePolicy
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("evaluate_type = ? AND margin_type = ?", request.EvaluateType, request.MarginType).First(&existingPolicy).Error; err == nil {
+		return nil, errors.New("เกณฑ์การอนุมัติสำหรับประเภทสินเชื่อนี้มีอยู่แล้ว")
+	}
+
+	policy := models.EvaluatePolicy{
+		CooperativeID:  cooperativeID,
+		EvaluateType:   request.EvaluateType,
+		MarginType:     request.MarginType,
+		MaxDti:         request.MaxDti,
+		MinDscr:        request.MinDscr,
+		MinNetIncome:   request.MinNetIncome,
+		MaxCoBorrowers: request.MaxCoBorrowers,
+		ShareMultiple:  request.ShareMultiple,
+	}
+
+	if err := database.DB.Create(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func GetPolicies(cooperativeID string) ([]models.EvaluatePolicy, error) {
+	var policies []models.EvaluatePolicy
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Order("evaluate_type ASC, margin_type ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func GetPolicyByID(cooperativeID string, id uuid.UUID) (*models.EvaluatePolicy, error) {
+	var policy models.EvaluatePolicy
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&policy, "id = ?", id).Error; err != nil {
+		return nil, errors.New("ไม่พบเกณฑ์การอนุมัติ")
+	}
+	return &policy, nil
+}
+
+// GetPolicyFor returns the cooperative's policy for a loan type and margin type, or nil when none is
+// configured
+func GetPolicyFor(cooperativeID string, evaluateType string, marginType string) (*models.EvaluatePolicy, error) {
+	var policy models.EvaluatePolicy
+	err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("evaluate_type = ? AND margin_type = ?", evaluateType, marginType).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func UpdatePolicy(cooperativeID string, id uuid.UUID, request *models.EvaluatePolicyRequest) (*models.EvaluatePolicy, error) {
+	var policy models.EvaluatePolicy
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&policy, "id = ?", id).Error; err != nil {
+		return nil, errors.New("ไม่พบเกณฑ์การอนุมัติ")
+	}
+
+	// Check if another policy already uses this loan type and margin type
+	var existingPolicy models.EvaluatePolicy
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).Where("evaluate_type = ? AND margin_type = ? AND id != ?", request.EvaluateType, request.MarginType, id).First(&existingPolicy).Error; err == nil {
+		return nil, errors.New("เกณฑ์การอนุมัติสำหรับประเภทสินเชื่อนี้มีอยู่แล้ว")
+	}
+
+	policy.EvaluateType = request.EvaluateType
+	policy.MarginType = request.MarginType
+	policy.MaxDti = request.MaxDti
+	policy.MinDscr = request.MinDscr
+	policy.MinNetIncome = request.MinNetIncome
+	policy.MaxCoBorrowers = request.MaxCoBorrowers
+	policy.ShareMultiple = request.ShareMultiple
+	policy.UpdatedAt = time.Now()
+
+	if err := database.DB.Save(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func DeletePolicy(cooperativeID string, id uuid.UUID) error {
+	var policy models.EvaluatePolicy
+	if err := database.DB.Scopes(cooperativeScope(cooperativeID)).First(&policy, "id = ?", id).Error; err != nil {
+		return errors.New("ไม่พบเกณฑ์การอนุมัติ")
+	}
+
+	if err := database.DB.Delete(&policy).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,28 +5,15 @@ import (
 	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
 )
 
-// GetPublicKPI returns summary KPI data without requiring authentication.
+// GetPublicKPI returns summary KPI data without requiring authentication. It never reads member or
+// evaluate data, which belongs to a cooperative; see GetDashboardSummary for those figures.
 func GetPublicKPI() (*models.PublicKPIResponse, error) {
-	var totalMembers int64
-	if err := database.DB.Model(&models.Member{}).Count(&totalMembers).Error; err != nil {
-		return nil, err
-	}
-
-	var totalEvaluations int64
-	if err := database.DB.Model(&models.Evaluate{}).Count(&totalEvaluations).Error; err != nil {
-		return nil, err
-	}
-
-	var totalSharesRaw float64
-	if err := database.DB.Model(&models.Member{}).
-		Select("COALESCE(SUM(shares_value), 0)").
-		Scan(&totalSharesRaw).Error; err != nil {
+	var totalCooperatives int64
+	if err := database.DB.Model(&models.Cooperative{}).Count(&totalCooperatives).Error; err != nil {
 		return nil, err
 	}
 
 	return &models.PublicKPIResponse{
-		TotalMembers:     totalMembers,
-		TotalEvaluations: totalEvaluations,
-		TotalShares:      int64(totalSharesRaw),
+		TotalCooperatives: totalCooperatives,
 	}, nil
 }
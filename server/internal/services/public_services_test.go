@@ -0,0 +1,20 @@
+package services
+
+import "testing"
+
+func TestPublicKPIReadsNoCooperativeData(t *testing.T) {
+	db := useFakeDB(t)
+
+	if _, err := GetPublicKPI(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !db.touched("cooperatives") {
+		t.Error("GetPublicKPI() did not count the cooperatives")
+	}
+	for table := range tenantTables {
+		if db.touched(table) {
+			t.Errorf("GetPublicKPI() read %s, which belongs to a cooperative", table)
+		}
+	}
+}
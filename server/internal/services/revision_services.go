@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// revisionIgnoredFields are regenerated on every save and carry no business meaning
+var revisionIgnoredFields = map[string]bool{
+	"id":          true,
+	"evaluateId":  true,
+	"resultId":    true,
+	"applicantId": true,
+	"createdAt":   true,
+	"updatedAt":   true,
+	"deletedAt":   true,
+	"position":    true,
+}
+
+// createEvaluateRevision snapshots the current state of an evaluate as its next revision
+func createEvaluateRevision(tx *gorm.DB, evaluateID uuid.UUID, editorID uuid.UUID) error {
+	var evaluate models.Evaluate
+	if err := tx.Preload("Applicants", applicantOrder).Preload("Result").Preload("Result.Applicants", applicantOrder).
+		Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
+		return err
+	}
+
+	var lastRevision int
+	if err := tx.Model(&models.EvaluateRevision{}).
+		Where("evaluate_id = ?", evaluateID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&lastRevision).Error; err != nil {
+		return err
+	}
+
+	revision := models.EvaluateRevision{
+		EvaluateID: evaluateID,
+		Revision:   lastRevision + 1,
+		EditorID:   editorID,
+		Snapshot:   &evaluate,
+		CreatedAt:  time.Now(),
+	}
+
+	return tx.Create(&revision).Error
+}
+
+// ensureBaselineRevision snapshots evaluates created before revisions existed, so their
+// original figures survive the first edit
+func ensureBaselineRevision(tx *gorm.DB, evaluate *models.Evaluate) error {
+	var count int64
+	if err := tx.Model(&models.EvaluateRevision{}).Where("evaluate_id = ?", evaluate.Id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return createEvaluateRevision(tx, evaluate.Id, evaluate.UserID)
+}
+
+func GetEvaluateRevisions(cooperativeID string, evaluateID uuid.UUID, readerID uuid.UUID) ([]models.EvaluateRevision, error) {
+	readable, err := readableAuditEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []models.EvaluateRevision
+	if err := database.DB.Preload("Editor").
+		Omit("snapshot").
+		Where("evaluate_id = ? AND evaluate_id IN (?)", evaluateID, readable).
+		Order("revision ASC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func GetEvaluateRevision(cooperativeID string, evaluateID uuid.UUID, revision int, readerID uuid.UUID) (*models.EvaluateRevision, error) {
+	readable, err := readableAuditEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var evaluateRevision models.EvaluateRevision
+	if err := database.DB.Preload("Editor").
+		Where("evaluate_id = ? AND evaluate_id IN (?) AND revision = ?", evaluateID, readable, revision).
+		First(&evaluateRevision).Error; err != nil {
+		return nil, errors.New("ไม่พบประวัติการแก้ไข")
+	}
+	return &evaluateRevision, nil
+}
+
+// DiffEvaluateRevisions returns every field that differs between two revisions of an evaluate
+func DiffEvaluateRevisions(cooperativeID string, evaluateID uuid.UUID, fromRevision int, toRevision int, readerID uuid.UUID) (*models.EvaluateRevisionDiff, error) {
+	from, err := GetEvaluateRevision(cooperativeID, evaluateID, fromRevision, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := GetEvaluateRevision(cooperativeID, evaluateID, toRevision, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromFields, err := flattenSnapshot(from.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	toFields, err := flattenSnapshot(to.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.RevisionFieldChange{}
+	for field, fromValue := range fromFields {
+		toValue, ok := toFields[field]
+		if !ok || !reflect.DeepEqual(fromValue, toValue) {
+			changes = append(changes, models.RevisionFieldChange{Field: field, From: fromValue, To: toValue})
+		}
+	}
+	for field, toValue := range toFields {
+		if _, ok := fromFields[field]; !ok {
+			changes = append(changes, models.RevisionFieldChange{Field: field, From: nil, To: toValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	// Metadata only, the changes already describe the snapshots
+	from.Snapshot = nil
+	to.Snapshot = nil
+
+	return &models.EvaluateRevisionDiff{
+		From:    *from,
+		To:      *to,
+		Changes: changes,
+	}, nil
+}
+
+// flattenSnapshot turns a snapshot into a map of JSON path to leaf value
+func flattenSnapshot(snapshot *models.Evaluate) (map[string]interface{}, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	flattenValue("", tree, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if revisionIgnoredFields[key] {
+				continue
+			}
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		// Applicants are paired by ID card so reordering co-borrowers is not a rewrite
+		keys := applicantKeys(v)
+		for i, child := range v {
+			key := fmt.Sprint(i)
+			if keys != nil {
+				key = keys[i]
+			}
+			flattenValue(fmt.Sprintf("%s[%s]", path, key), child, fields)
+		}
+	default:
+		fields[path] = v
+	}
+}
+
+// applicantKeys returns the ID card of every element when all of them have a distinct one, or nil
+func applicantKeys(items []interface{}) []string {
+	keys := make([]string, len(items))
+	seen := map[string]bool{}
+	for i, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		idCard, ok := object["idCard"].(string)
+		if !ok || idCard == "" || seen[idCard] {
+			return nil
+		}
+		seen[idCard] = true
+		keys[i] = idCard
+	}
+	return keys
+}
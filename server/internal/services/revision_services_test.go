@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestFlattenSnapshotPairsApplicantsByIDCard(t *testing.T) {
+	before := &models.Evaluate{Applicants: []models.Applicant{
+		{Id: uuid.New(), Name: "ผู้กู้", IDCard: "1101700203450", Position: 0},
+		{Id: uuid.New(), Name: "ผู้กู้ร่วม", IDCard: "3100600123450", Position: 1},
+	}}
+	// Same applicants swapped, with new row IDs, and one changed field
+	after := &models.Evaluate{Applicants: []models.Applicant{
+		{Id: uuid.New(), Name: "ผู้กู้ร่วม (แก้ไข)", IDCard: "3100600123450", Position: 0},
+		{Id: uuid.New(), Name: "ผู้กู้", IDCard: "1101700203450", Position: 1},
+	}}
+
+	beforeFields, err := flattenSnapshot(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afterFields, err := flattenSnapshot(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changed []string
+	for path, value := range afterFields {
+		if beforeFields[path] != value {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) != 1 || changed[0] != "applicants[3100600123450].name" {
+		t.Errorf("changed fields = %v, want [applicants[3100600123450].name]", changed)
+	}
+}
+
+func TestApplicantKeysFallsBackToIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []interface{}
+	}{
+		{"not objects", []interface{}{"a", "b"}},
+		{"missing ID card", []interface{}{map[string]interface{}{"idCard": "1"}, map[string]interface{}{}}},
+		{"empty ID card", []interface{}{map[string]interface{}{"idCard": ""}}},
+		{"duplicate ID card", []interface{}{map[string]interface{}{"idCard": "1"}, map[string]interface{}{"idCard": "1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keys := applicantKeys(tt.items); keys != nil {
+				t.Errorf("applicantKeys() = %v, want nil", keys)
+			}
+		})
+	}
+}
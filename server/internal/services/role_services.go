@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleNotFound        = errors.New("ไม่พบบทบาท")
+	ErrRoleExists          = errors.New("ชื่อบทบาทนี้มีอยู่แล้ว")
+	ErrRoleInUse           = errors.New("ไม่สามารถลบบทบาทที่มีผู้ใช้งานอยู่ได้")
+	ErrRoleSystem          = errors.New("ไม่สามารถเปลี่ยนชื่อหรือลบบทบาทพื้นฐานของระบบได้")
+	ErrRoleLockout         = errors.New("ต้องมีอย่างน้อยหนึ่งบทบาทที่จัดการผู้ใช้งานได้")
+	ErrUnknownPermission   = errors.New("ไม่รู้จักสิทธิ์ที่ระบุ")
+	ErrPermissionForbidden = errors.New("ไม่มีสิทธิ์ในการเข้าถึงข้อมูลส่วนนี้")
+)
+
+// normalizePermissions rejects unknown permissions and drops duplicates, keeping the catalogue order
+func normalizePermissions(permissions []string) ([]string, error) {
+	requested := map[string]bool{}
+	for _, permission := range permissions {
+		requested[strings.TrimSpace(permission)] = true
+	}
+
+	normalized := []string{}
+	for _, permission := range models.Permissions {
+		if requested[permission] {
+			normalized = append(normalized, permission)
+			delete(requested, permission)
+		}
+	}
+	if len(requested) > 0 {
+		return nil, ErrUnknownPermission
+	}
+	return normalized, nil
+}
+
+func hasPermission(permissions []string, permission string) bool {
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissions returns the permissions of a role by name. Built-in roles fall back to their
+// defaults when the row has not been seeded yet.
+func RolePermissions(name string) ([]string, error) {
+	var role models.Role
+	if err := database.DB.Where("name = ?", name).Limit(1).Find(&role).Error; err != nil {
+		return nil, err
+	}
+	if role.Id != uuid.Nil {
+		return role.Permissions, nil
+	}
+
+	for _, builtIn := range models.DefaultRoles {
+		if builtIn.Name == name {
+			return builtIn.Permissions, nil
+		}
+	}
+	return []string{}, nil
+}
+
+// AdminCan reports whether the admin's role grants every listed permission
+func AdminCan(admin *models.Admin, permissions ...string) (bool, error) {
+	granted, err := RolePermissions(admin.Role)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if !hasPermission(granted, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func GetRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := database.DB.Order("system DESC, name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func GetRoleByID(id uuid.UUID) (*models.Role, error) {
+	var role models.Role
+	if err := database.DB.Where("id = ?", id).Limit(1).Find(&role).Error; err != nil {
+		return nil, err
+	}
+	if role.Id == uuid.Nil {
+		return nil, ErrRoleNotFound
+	}
+	return &role, nil
+}
+
+// RoleExists reports whether admins may be assigned the named role
+func RoleExists(name string) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&models.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	for _, builtIn := range models.DefaultRoles {
+		if builtIn.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func CreateRole(request *models.RoleRequest) (*models.Role, error) {
+	permissions, err := normalizePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(request.Name))
+	exists, err := RoleExists(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists || name == models.RolePending {
+		return nil, ErrRoleExists
+	}
+
+	role := models.Role{
+		Name:        name,
+		Description: request.Description,
+		Permissions: permissions,
+	}
+	if err := database.DB.Create(&role).Error; err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// UpdateRole edits a role. Renaming carries the admins that hold the role over to the new name.
+func UpdateRole(id uuid.UUID, request *models.RoleRequest) (*models.Role, error) {
+	permissions, err := normalizePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := GetRoleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(request.Name))
+	if role.System && name != role.Name {
+		return nil, ErrRoleSystem
+	}
+	if name != role.Name {
+		exists, err := RoleExists(name)
+		if err != nil {
+			return nil, err
+		}
+		if exists || name == models.RolePending {
+			return nil, ErrRoleExists
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Someone must still be able to manage admins and roles afterwards
+		if hasPermission(role.Permissions, models.PermissionAdminManage) && !hasPermission(permissions, models.PermissionAdminManage) {
+			var managers int64
+			if err := tx.Model(&models.Role{}).
+				Where("id != ? AND permissions @> ?::jsonb", role.Id, `["`+models.PermissionAdminManage+`"]`).
+				Count(&managers).Error; err != nil {
+				return err
+			}
+			if managers == 0 {
+				return ErrRoleLockout
+			}
+		}
+
+		if name != role.Name {
+			var holders []uuid.UUID
+			if err := tx.Model(&models.Admin{}).Where("role = ?", role.Name).Pluck("id", &holders).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Admin{}).Where("role = ?", role.Name).Update("role", name).Error; err != nil {
+				return err
+			}
+			// Their role changed as far as any issued token is concerned
+			for _, adminID := range holders {
+				if err := invalidateAdminTokens(tx, adminID); err != nil {
+					return err
+				}
+			}
+		}
+
+		role.Name = name
+		role.Description = request.Description
+		role.Permissions = permissions
+		role.UpdatedAt = time.Now()
+		return tx.Save(role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func DeleteRole(id uuid.UUID) error {
+	role, err := GetRoleByID(id)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return ErrRoleSystem
+	}
+
+	var holders int64
+	if err := database.DB.Model(&models.Admin{}).Where("role = ?", role.Name).Count(&holders).Error; err != nil {
+		return err
+	}
+	if holders > 0 {
+		return ErrRoleInUse
+	}
+
+	return database.DB.Delete(&models.Role{}, "id = ?", id).Error
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+// inTransaction joins the statements run between BEGIN and COMMIT
+func (db *fakeDB) inTransaction() string {
+	var statements []string
+	open := false
+	for _, q := range db.recorded() {
+		switch q.sql {
+		case "BEGIN":
+			open = true
+		case "COMMIT", "ROLLBACK":
+			open = false
+		default:
+			if open {
+				statements = append(statements, q.sql)
+			}
+		}
+	}
+	return strings.Join(statements, "\n")
+}
+
+func TestUpdateRoleInvalidatesHoldersOnRename(t *testing.T) {
+	tests := []struct {
+		name       string
+		roleName   string
+		invalidate bool
+	}{
+		{"rename", "auditor", true},
+		{"same name", fakeRole, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useFakeDB(t)
+			db.permissions = []string{models.PermissionEvaluateWrite}
+			// No other role has the new name
+			db.answer = func(q fakeQuery) (*fakeRows, bool) {
+				if strings.HasPrefix(strings.ToLower(q.sql), "select count(") && q.tables()["roles"] {
+					return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(0)}}}, true
+				}
+				return nil, false
+			}
+
+			if _, err := UpdateRole(uuid.New(), &models.RoleRequest{Name: tt.roleName, Permissions: db.permissions}); err != nil {
+				t.Fatal(err)
+			}
+
+			statements := db.inTransaction()
+			for _, fragment := range []string{`UPDATE "admins" SET "role"=`, `"token_version"=token_version + 1`, `UPDATE "sessions" SET "revoked_at"=`} {
+				if strings.Contains(statements, fragment) != tt.invalidate {
+					t.Errorf("transaction contains %q = %v, want %v:\n%s", fragment, !tt.invalidate, tt.invalidate, statements)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour // idle timeout, extended on every refresh
+
+	// sessionTouchInterval limits how often a request writes LastSeenAt
+	sessionTouchInterval = time.Minute
+)
+
+var (
+	ErrSessionInvalid  = errors.New("เซสชันหมดอายุหรือถูกยกเลิก กรุณาเข้าสู่ระบบใหม่")
+	ErrSessionNotFound = errors.New("ไม่พบเซสชัน")
+)
+
+// SessionTokens is what a client receives when signing in or refreshing
+type SessionTokens struct {
+	SessionID        uuid.UUID
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// deviceFromUserAgent gives a short label such as "Windows · Chrome" for the session list
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	}
+
+	switch {
+	case os != "" && browser != "":
+		return os + " · " + browser
+	case os != "" || browser != "":
+		return os + browser
+	default:
+		return "ไม่ทราบอุปกรณ์"
+	}
+}
+
+func issueSessionTokens(session *models.Session, tokenVersion int, refreshToken string) (*SessionTokens, error) {
+	accessToken, err := GenerateToken(session.AdminID.String(), session.Id.String(), tokenVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionTokens{
+		SessionID:        session.Id,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  time.Now().Add(AccessTokenTTL),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// CreateSession opens a session for a signed-in admin and issues its first token pair
+func CreateSession(admin *models.Admin, userAgent string, ip string) (*SessionTokens, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	cooperativeID, err := defaultCooperative(admin)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		AdminID:          admin.Id,
+		CooperativeID:    cooperativeID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		Device:           deviceFromUserAgent(userAgent),
+		IP:               ip,
+		UserAgent:        userAgent,
+		LastSeenAt:       now,
+		ExpiresAt:        now.Add(RefreshTokenTTL),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return issueSessionTokens(&session, admin.TokenVersion, refreshToken)
+}
+
+// RefreshSession rotates the refresh token of a live session and issues a new access token. A token
+// that was already rotated means it leaked, so the whole session is revoked.
+func RefreshSession(refreshToken string, userAgent string, ip string) (*SessionTokens, error) {
+	if refreshToken == "" {
+		return nil, ErrSessionInvalid
+	}
+	hash := hashRefreshToken(refreshToken)
+	now := time.Now()
+
+	var tokens *SessionTokens
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var session models.Session
+		if err := tx.Where("refresh_token_hash = ?", hash).Limit(1).Find(&session).Error; err != nil {
+			return err
+		}
+
+		if session.Id == uuid.Nil {
+			var reused models.Session
+			if err := tx.Where("previous_token_hash = ?", hash).Limit(1).Find(&reused).Error; err != nil {
+				return err
+			}
+			if reused.Id != uuid.Nil && reused.RevokedAt == nil {
+				if err := tx.Model(&reused).Update("revoked_at", now).Error; err != nil {
+					return err
+				}
+			}
+			return ErrSessionInvalid
+		}
+
+		if session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+			return ErrSessionInvalid
+		}
+
+		var admin models.Admin
+		if err := tx.Select("id", "token_version").Where("id = ?", session.AdminID).Limit(1).Find(&admin).Error; err != nil {
+			return err
+		}
+		if admin.Id == uuid.Nil {
+			return ErrSessionInvalid
+		}
+
+		nextToken, err := newRefreshToken()
+		if err != nil {
+			return err
+		}
+
+		session.PreviousTokenHash = session.RefreshTokenHash
+		session.RefreshTokenHash = hashRefreshToken(nextToken)
+		session.IP = ip
+		session.UserAgent = userAgent
+		session.LastSeenAt = now
+		session.ExpiresAt = now.Add(RefreshTokenTTL)
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+
+		tokens, err = issueSessionTokens(&session, admin.TokenVersion, nextToken)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// ValidateSession checks that the session behind an access token is still live and that the admin's
+// token version has not moved on since it was issued, then records activity. It returns the
+// cooperative the session is working in.
+func ValidateSession(claims *AccessClaims) (string, error) {
+	var row struct {
+		RevokedAt     *time.Time
+		ExpiresAt     time.Time
+		LastSeenAt    time.Time
+		TokenVersion  int
+		CooperativeID string
+	}
+	result := database.DB.Table("sessions").
+		Select("sessions.revoked_at, sessions.expires_at, sessions.last_seen_at, admins.token_version, sessions.cooperative_id").
+		Joins("JOIN admins ON admins.id = sessions.admin_id").
+		Where("sessions.id = ? AND sessions.admin_id = ?", claims.SessionID, claims.UserID).
+		Limit(1).Scan(&row)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	now := time.Now()
+	if result.RowsAffected == 0 || row.RevokedAt != nil || !row.ExpiresAt.After(now) ||
+		row.TokenVersion != claims.TokenVersion {
+		return "", ErrSessionInvalid
+	}
+
+	if now.Sub(row.LastSeenAt) > sessionTouchInterval {
+		database.DB.Model(&models.Session{}).Where("id = ?", claims.SessionID).UpdateColumn("last_seen_at", now)
+	}
+	return row.CooperativeID, nil
+}
+
+// GetSessions lists the live sessions of an admin, most recently used first
+func GetSessions(adminID uuid.UUID, currentSessionID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := database.DB.Where("admin_id = ? AND revoked_at IS NULL AND expires_at > ?", adminID, time.Now()).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the admin's own sessions out
+func RevokeSession(adminID uuid.UUID, sessionID uuid.UUID) error {
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND admin_id = ? AND revoked_at IS NULL", sessionID, adminID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/calculator"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+)
+
+var ErrStressEvaluateNotFound = errors.New("ไม่พบข้อมูลการประเมิน")
+
+// evaluateToRequest rebuilds the request a stored evaluate was calculated from; officer-entered
+// expenses carry their ID card so the calculator matches them back to the right applicant
+func evaluateToRequest(evaluate *models.Evaluate) models.EvaluateRequest {
+	request := models.EvaluateRequest{
+		EvaluateType: evaluate.EvaluateType,
+		MarginType:   evaluate.MarginType,
+		LoanTerms:    evaluate.LoanTerms,
+		Result: models.EvaluateResultRequest{
+			EvaluateType: evaluate.Result.EvaluateType,
+			DebtDetail:   evaluate.Result.DebtDetail,
+		},
+	}
+
+	for _, a := range evaluate.Applicants {
+		request.Applicants = append(request.Applicants, models.ApplicantRequest{
+			CareerCategory:       a.CareerCategory,
+			Career:               a.Career,
+			OtherCareer:          a.OtherCareer,
+			CareerCategoryID:     a.CareerCategoryID,
+			SubCategoryID:        a.SubCategoryID,
+			Name:                 a.Name,
+			IDCard:               a.IDCard,
+			MarginID:             a.MarginID,
+			MarginValue:          a.MarginValue,
+			BusinessActivity:     a.BusinessActivity,
+			ExpenseItem:          a.ExpenseItem,
+			ProfileLost:          a.ProfileLost,
+			ShareHolder:          a.ShareHolder,
+			OptionalOtherExpense: a.OptionalOtherExpense,
+			Salary:               a.Salary,
+			OtherSalary:          a.OtherSalary,
+			OptionsSalary:        a.OptionsSalary,
+		})
+	}
+
+	for _, a := range evaluate.Result.Applicants {
+		request.Result.Applicants = append(request.Result.Applicants, models.ResultApplicantRequest{
+			Name:           a.Name,
+			IDCard:         a.IDCard,
+			LivingExpenses: a.LivingExpenses,
+			OtherExpenses:  a.OtherExpenses,
+		})
+	}
+
+	return request
+}
+
+// StressEvaluate reruns a saved evaluate under each shock (or the default shocks) and checks every
+// scenario against the evaluate's policy
+func StressEvaluate(cooperativeID string, evaluateID uuid.UUID, readerID uuid.UUID, request *models.StressRequest) (*models.StressTestResult, error) {
+	readable, err := readableEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var evaluate models.Evaluate
+	if err := database.DB.
+		Preload("Applicants", applicantOrder).
+		Preload("Result").
+		Preload("Result.Applicants", applicantOrder).
+		Scopes(readable).
+		Where("id = ?", evaluateID).First(&evaluate).Error; err != nil {
+		return nil, ErrStressEvaluateNotFound
+	}
+
+	// Reuse the margins the evaluate was saved with; older evaluates use those in effect when created
+	base := evaluateToRequest(&evaluate)
+	margins, err := applicantMargins(base.Applicants, evaluate.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range evaluate.Applicants {
+		if a.MarginValue != nil {
+			margins[careerMarginKey(a.CareerCategory, a.Career)] = careerMargin{ID: a.MarginID, Value: *a.MarginValue}
+		}
+	}
+
+	policy, err := GetPolicyFor(evaluate.CooperativeID, evaluate.EvaluateType, evaluate.MarginType)
+	if err != nil {
+		return nil, err
+	}
+
+	shocks := request.Scenarios
+	if len(shocks) == 0 {
+		shocks = calculator.DefaultStressShocks
+	}
+
+	baseline, scenarios := calculator.StressTest(base, shocks, careerMarginLookup(margins), policy)
+	return &models.StressTestResult{
+		EvaluateID: evaluate.Id,
+		Baseline:   baseline,
+		Scenarios:  scenarios,
+	}, nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/database"
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidTransition   = errors.New("ไม่สามารถเปลี่ยนสถานะแบบประเมินตามขั้นตอนนี้ได้")
+	ErrTransitionForbidden = errors.New("ไม่มีสิทธิ์เปลี่ยนสถานะแบบประเมินนี้")
+	ErrSelfReview          = errors.New("ผู้จัดทำไม่สามารถตรวจสอบหรืออนุมัติแบบประเมินของตนเองได้")
+	ErrReviewerApproval    = errors.New("ผู้ตรวจสอบไม่สามารถอนุมัติแบบประเมินที่ตนเองตรวจสอบได้ ต้องให้ผู้อนุมัติอีกคน")
+	ErrEvaluateLocked      = errors.New("แก้ไขหรือลบได้เฉพาะแบบประเมินฉบับร่าง กรุณาส่งกลับเป็นฉบับร่างก่อน")
+)
+
+// evaluateTransitions lists the permission needed to move an evaluate from one state to another.
+// Every move needs at least evaluate:write; an empty permission would let any signed-in admin make it.
+var evaluateTransitions = map[string]map[string]string{
+	models.EvaluateStatusDraft: {
+		models.EvaluateStatusSubmitted: models.PermissionEvaluateWrite,
+		models.EvaluateStatusCancelled: models.PermissionEvaluateWrite,
+	},
+	models.EvaluateStatusSubmitted: {
+		models.EvaluateStatusUnderReview: models.PermissionEvaluateWrite,
+		models.EvaluateStatusDraft:       models.PermissionEvaluateWrite,
+		models.EvaluateStatusCancelled:   models.PermissionEvaluateWrite,
+	},
+	models.EvaluateStatusUnderReview: {
+		models.EvaluateStatusApproved: models.PermissionEvaluateApprove,
+		models.EvaluateStatusRejected: models.PermissionEvaluateApprove,
+		models.EvaluateStatusDraft:    models.PermissionEvaluateApprove,
+	},
+	models.EvaluateStatusApproved: {
+		models.EvaluateStatusDisbursed: models.PermissionEvaluateWrite,
+		models.EvaluateStatusCancelled: models.PermissionEvaluateApprove,
+	},
+	models.EvaluateStatusRejected: {
+		models.EvaluateStatusCancelled: models.PermissionEvaluateWrite,
+	},
+}
+
+// checkerStatuses can only be reached by someone other than the preparer. Approval additionally
+// needs someone other than the checker who took the evaluate under review.
+var checkerStatuses = map[string]bool{
+	models.EvaluateStatusUnderReview: true,
+	models.EvaluateStatusApproved:    true,
+	models.EvaluateStatusRejected:    true,
+}
+
+func IsValidEvaluateStatus(status string) bool {
+	switch status {
+	case models.EvaluateStatusDraft, models.EvaluateStatusSubmitted, models.EvaluateStatusUnderReview,
+		models.EvaluateStatusApproved, models.EvaluateStatusRejected, models.EvaluateStatusDisbursed,
+		models.EvaluateStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransition reports whether an admin with the given permissions may move an evaluate between the two states
+func CanTransition(from string, to string, permissions []string) bool {
+	required, ok := evaluateTransitions[from][to]
+	if !ok {
+		return false
+	}
+	return required == "" || hasPermission(permissions, required)
+}
+
+// lockEvaluate loads the cooperative's evaluate for update, so concurrent edits and transitions of
+// the same record run one after the other
+func lockEvaluate(tx *gorm.DB, cooperativeID string, evaluateID uuid.UUID, evaluate *models.Evaluate) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(cooperativeScope(cooperativeID)).
+		Where("id = ?", evaluateID).
+		First(evaluate).Error
+}
+
+// ensureEvaluateEditable rejects changes to an evaluate that is not a draft. Anything further along
+// has been handed to a checker, so it has to be sent back to ฉบับร่าง before its figures can change;
+// an approved evaluate can never get there again. Load the evaluate with lockEvaluate first so its
+// status cannot change until the transaction ends.
+func ensureEvaluateEditable(evaluate *models.Evaluate) error {
+	if evaluate.Status != models.EvaluateStatusDraft {
+		return ErrEvaluateLocked
+	}
+	return nil
+}
+
+// TransitionEvaluateStatus moves an evaluate to the next workflow state and records the transition
+func TransitionEvaluateStatus(cooperativeID string, evaluateID uuid.UUID, actorID uuid.UUID, status string, feedback string) (*models.Evaluate, error) {
+	tx := database.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var evaluate models.Evaluate
+	if err := lockEvaluate(tx, cooperativeID, evaluateID, &evaluate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Where("evaluate_id = ?", evaluate.Id).Order("created_at ASC").Find(&evaluate.Applicants).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var actor models.Admin
+	if err := tx.Where("id = ?", actorID).First(&actor).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, ok := evaluateTransitions[evaluate.Status][status]; !ok {
+		tx.Rollback()
+		return nil, ErrInvalidTransition
+	}
+
+	permissions, err := RolePermissions(actor.Role)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !CanTransition(evaluate.Status, status, permissions) {
+		tx.Rollback()
+		return nil, ErrTransitionForbidden
+	}
+
+	// Preparer/checker separation
+	if checkerStatuses[status] && evaluate.UserID == actorID {
+		tx.Rollback()
+		return nil, ErrSelfReview
+	}
+	if status == models.EvaluateStatusApproved && evaluate.ReviewerID != nil && *evaluate.ReviewerID == actorID {
+		tx.Rollback()
+		return nil, ErrReviewerApproval
+	}
+
+	history := models.EvaluateStatusHistory{
+		EvaluateID: evaluate.Id,
+		ActorID:    actorID,
+		FromStatus: evaluate.Status,
+		ToStatus:   status,
+		Feedback:   feedback,
+		CreatedAt:  time.Now(),
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	mainBorrowerName := ""
+	if len(evaluate.Applicants) > 0 {
+		mainBorrowerName = evaluate.Applicants[0].Name
+	}
+
+	evaluateLog := models.EvaluateLog{
+		CooperativeID: cooperativeID,
+		Action:        fmt.Sprintf("เปลี่ยนสถานะแบบประเมินของ %s จาก %s เป็น %s", mainBorrowerName, evaluate.Status, status),
+		Username:      actor.Username,
+		FullName:      actor.FullName,
+		Role:          actor.Role,
+		Timestamp:     time.Now(),
+	}
+	if err := tx.Create(&evaluateLog).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"status": status, "feedback": feedback, "updated_at": time.Now()}
+	switch status {
+	case models.EvaluateStatusUnderReview:
+		updates["reviewer_id"] = actorID
+	case models.EvaluateStatusDraft:
+		// A returned evaluate is reviewed again from scratch
+		updates["reviewer_id"] = nil
+	}
+
+	if err := tx.Model(&models.Evaluate{}).
+		Where("id = ?", evaluateID).
+		Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	// The transition checks its own permissions, so the actor gets the result back even if the
+	// evaluate is not theirs to read otherwise
+	return findEvaluate(cooperativeScope(cooperativeID), evaluateID)
+}
+
+func GetEvaluateStatusHistory(cooperativeID string, evaluateID uuid.UUID, readerID uuid.UUID) ([]models.EvaluateStatusHistory, error) {
+	readable, err := readableAuditEvaluates(cooperativeID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []models.EvaluateStatusHistory
+	if err := database.DB.Preload("Actor").
+		Where("evaluate_id = ? AND evaluate_id IN (?)", evaluateID, readable).
+		Order("created_at ASC").
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
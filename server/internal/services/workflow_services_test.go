@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/SorayuthJapanya/co-op-credit-evaluator/internal/models"
+)
+
+func TestCanTransitionNeedsPermission(t *testing.T) {
+	for from, targets := range evaluateTransitions {
+		for to := range targets {
+			if CanTransition(from, to, nil) {
+				t.Errorf("%s → %s is allowed without any permission", from, to)
+			}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		from, to    string
+		permissions []string
+		want        bool
+	}{
+		{"submit with evaluate:write", models.EvaluateStatusDraft, models.EvaluateStatusSubmitted, []string{models.PermissionEvaluateWrite}, true},
+		{"submit with approve only", models.EvaluateStatusDraft, models.EvaluateStatusSubmitted, []string{models.PermissionEvaluateApprove}, false},
+		{"approve with evaluate:write", models.EvaluateStatusUnderReview, models.EvaluateStatusApproved, []string{models.PermissionEvaluateWrite}, false},
+		{"approve with evaluate:approve", models.EvaluateStatusUnderReview, models.EvaluateStatusApproved, []string{models.PermissionEvaluateApprove}, true},
+		{"no such move", models.EvaluateStatusDraft, models.EvaluateStatusApproved, models.Permissions, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to, tt.permissions); got != tt.want {
+				t.Errorf("CanTransition(%s, %s, %v) = %v, want %v", tt.from, tt.to, tt.permissions, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureEvaluateEditableOnlyDrafts(t *testing.T) {
+	for _, status := range []string{
+		models.EvaluateStatusDraft, models.EvaluateStatusSubmitted, models.EvaluateStatusUnderReview,
+		models.EvaluateStatusApproved, models.EvaluateStatusRejected, models.EvaluateStatusDisbursed,
+		models.EvaluateStatusCancelled,
+	} {
+		err := ensureEvaluateEditable(&models.Evaluate{Status: status})
+		if want := status == models.EvaluateStatusDraft; (err == nil) != want {
+			t.Errorf("ensureEvaluateEditable(%s) = %v, editable want %v", status, err, want)
+		}
+	}
+}
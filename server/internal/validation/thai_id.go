@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrThaiIDRequired = errors.New("กรุณากรอกเลขบัตรประชาชน")
+	ErrThaiIDFormat   = errors.New("เลขบัตรประชาชนต้องเป็นตัวเลข 13 หลัก")
+	ErrThaiIDChecksum = errors.New("เลขบัตรประชาชนไม่ถูกต้อง (เลขตรวจสอบหลักสุดท้ายไม่ตรง)")
+)
+
+// NormalizeThaiID strips the dashes and spaces people type or copy from the card (1-2345-67890-12-3)
+func NormalizeThaiID(id string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(id))
+}
+
+// ValidateThaiID normalizes a Thai citizen ID and verifies its mod-11 check digit,
+// returning the 13-digit form to store
+func ValidateThaiID(id string) (string, error) {
+	id = NormalizeThaiID(id)
+	if id == "" {
+		return "", ErrThaiIDRequired
+	}
+
+	if len(id) != 13 {
+		return id, ErrThaiIDFormat
+	}
+
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return id, ErrThaiIDFormat
+		}
+		if i < 12 {
+			sum += int(id[i]-'0') * (13 - i)
+		}
+	}
+
+	if int(id[12]-'0') != (11-sum%11)%10 {
+		return id, ErrThaiIDChecksum
+	}
+
+	return id, nil
+}
@@ -0,0 +1,40 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateThaiID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		want    string
+		wantErr error
+	}{
+		{"valid", "1101700203450", "1101700203450", nil},
+		{"valid with check digit 1", "1234567890121", "1234567890121", nil},
+		{"valid zero remainder", "0000000000001", "0000000000001", nil},
+		{"dashed as printed on the card", "1-1017-00203-45-0", "1101700203450", nil},
+		{"spaces and padding", " 3 1006 00123 45 0 ", "3100600123450", nil},
+		{"bad check digit", "1101700203451", "1101700203451", ErrThaiIDChecksum},
+		{"bad check digit dashed", "3-1006-00123-45-8", "3100600123458", ErrThaiIDChecksum},
+		{"too short", "110170020345", "110170020345", ErrThaiIDFormat},
+		{"too long", "11017002034500", "11017002034500", ErrThaiIDFormat},
+		{"letters", "11017002034A0", "11017002034A0", ErrThaiIDFormat},
+		{"empty", "", "", ErrThaiIDRequired},
+		{"dashes only", "- -", "", ErrThaiIDRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateThaiID(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateThaiID(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ValidateThaiID(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+}